@@ -0,0 +1,56 @@
+package cmd
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"os/signal"
+	"syscall"
+
+	"github.com/julianGoh17/simple-e2e/framework/operations"
+)
+
+// InterruptedExitCode is the exit code of the binary when a run is stopped by SIGINT or SIGTERM, which is the code shells use for SIGINT
+const InterruptedExitCode = 130
+
+// GetExitCode returns the exit code of the binary for the error returned by a command
+func GetExitCode(err error) int {
+	switch {
+	case err == nil:
+		return 0
+	case errors.Is(err, operations.ErrInterrupted):
+		return InterruptedExitCode
+	default:
+		return 1
+	}
+}
+
+// trapInterrupts will trigger the interrupt on the first SIGINT or SIGTERM so the run can tear down its tests, and call exit on the second so
+// the user can stop waiting for the teardown. The returned function stops trapping the signals.
+func trapInterrupts(interrupt *operations.Interrupt, exit func(code int)) func() {
+	signals := make(chan os.Signal, 2)
+	done := make(chan struct{})
+	signal.Notify(signals, os.Interrupt, syscall.SIGTERM)
+	go func() {
+		received := 0
+		for {
+			select {
+			case sig := <-signals:
+				received++
+				if received > 1 {
+					fmt.Printf("Received %s again, exiting without waiting for teardown\n", sig)
+					exit(InterruptedExitCode)
+					return
+				}
+				fmt.Printf("Received %s, running teardown before exiting. Interrupt again to exit immediately\n", sig)
+				interrupt.Trigger()
+			case <-done:
+				return
+			}
+		}
+	}()
+	return func() {
+		signal.Stop(signals)
+		close(done)
+	}
+}
@@ -0,0 +1,49 @@
+package cmd
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"syscall"
+	"testing"
+	"time"
+
+	"github.com/julianGoh17/simple-e2e/framework/operations"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestGetExitCode(t *testing.T) {
+	tables := []struct {
+		err  error
+		code int
+	}{
+		{nil, 0},
+		{errors.New("Test failed at stage: stage1"), 1},
+		{operations.ErrInterrupted, InterruptedExitCode},
+		{fmt.Errorf("%w at stage: stage1", operations.ErrInterrupted), InterruptedExitCode},
+	}
+
+	for _, table := range tables {
+		assert.Equal(t, table.code, GetExitCode(table.err))
+	}
+}
+
+func TestTrapInterrupts(t *testing.T) {
+	interrupt := operations.NewInterrupt(time.Minute)
+	exited := make(chan int, 1)
+	stop := trapInterrupts(interrupt, func(code int) {
+		exited <- code
+	})
+	defer stop()
+
+	assert.NoError(t, syscall.Kill(os.Getpid(), syscall.SIGINT))
+	assert.Eventually(t, interrupt.IsInterrupted, time.Second, time.Millisecond)
+
+	assert.NoError(t, syscall.Kill(os.Getpid(), syscall.SIGTERM))
+	select {
+	case code := <-exited:
+		assert.Equal(t, InterruptedExitCode, code)
+	case <-time.After(time.Second):
+		assert.Fail(t, "Did not exit on the second interrupt")
+	}
+}
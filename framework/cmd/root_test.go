@@ -29,5 +29,8 @@ func TestInitRootCmd(t *testing.T) {
 }
 
 func TestMain(m *testing.M) {
+	internal.SetStateRoot()
+	internal.SetArtifactsRoot()
+	internal.SetWorkspaceRoot()
 	internal.TestCoverageReaches85Percent(m)
 }
@@ -1,17 +1,36 @@
 package cmd
 
 import (
+	"errors"
 	"fmt"
+	"os"
+	"sort"
 	"strings"
+	"time"
 
+	"github.com/julianGoh17/simple-e2e/framework/models"
 	"github.com/julianGoh17/simple-e2e/framework/operations"
 	"github.com/julianGoh17/simple-e2e/framework/util"
+	"github.com/olekukonko/tablewriter"
 	"github.com/spf13/cobra"
 )
 
 var (
-	stages string
-	test   string
+	stages      string
+	tests       []string
+	all         bool
+	parallel    int
+	tags        string
+	excludeTags string
+	vars        []string
+	varFiles    []string
+	dryRun      bool
+	resume      string
+	fromStage   string
+	// updateSnapshots is whether snapshot steps rewrite their snapshots instead of comparing with them
+	updateSnapshots bool
+	// teardownTimeout is how long the stages and steps which run on failure have to finish once a run is interrupted
+	teardownTimeout time.Duration
 )
 
 // NewRunCmd returns the run command as a cobra object to be interacted with
@@ -22,26 +41,200 @@ func NewRunCmd() *cobra.Command {
 		Long:  `Run all the steps in a specified test or just a specific set of stages from that test.`,
 		RunE: func(cmd *cobra.Command, args []string) error {
 			util.ConfigureGlobalLogLevel(verbosity)
-			// TODO: add documentation that says that each run should be stateless
-			controller, err := operations.NewController()
+			if len(tests) == 0 && !all && resume == "" {
+				return errors.New("required flag(s) \"test\" not set")
+			}
+			state, err := getRunState()
 			if err != nil {
 				return err
 			}
+			testPaths := state.GetTestPaths()
+			if len(tests) != 0 || all {
+				if testPaths, err = operations.FindTestFiles(config.GetOrDefault(util.TestDirEnv), tests, all); err != nil {
+					return err
+				}
+			}
 			stage := []string{}
 			if stages != "" {
 				stage = strings.Split(stages, ",")
 			}
-			testPath := fmt.Sprintf("%s/%s.yaml", config.GetOrDefault(util.TestDirEnv), test)
-			return controller.RunTest(testPath, stage...)
+			overrides, err := operations.LoadVariableOverrides(varFiles, vars)
+			if err != nil {
+				return err
+			}
+			interrupt := operations.NewInterrupt(teardownTimeout)
+			runner := operations.NewRunner(parallel, func(controller *operations.Controller) error {
+				controller.SetVariableOverrides(overrides)
+				controller.SetRunState(state)
+				controller.SetFromStage(fromStage)
+				controller.SetInterrupt(interrupt)
+				controller.SetUpdateSnapshots(updateSnapshots)
+				return controller.SetTagFilter(tags, excludeTags)
+			})
+			if dryRun {
+				plans, err := runner.PlanTests(testPaths, stage...)
+				if err != nil {
+					return maskError(err)
+				}
+				fmt.Print(util.MaskSecrets(getPlanOutput(plans)))
+				return nil
+			}
+			stopTrapping := trapInterrupts(interrupt, os.Exit)
+			results, err := runner.RunTests(testPaths, stage...)
+			stopTrapping()
+			if len(results) > 0 {
+				getResultsTable(results).Render()
+				fmt.Println(getResultsSummary(results))
+			}
+			if err != nil && state.IsSaved() {
+				fmt.Printf("Resume this run from its failed stages with 'run --resume %s'\n", state.ID)
+			}
+			if interrupt.IsInterrupted() {
+				return operations.ErrInterrupted
+			}
+			return maskError(err)
 		},
 	}
 }
 
 func initRunCmd(rootCmd, runCmd *cobra.Command) {
-	runCmd.Flags().StringVarP(&test, "test", "t", "", "The name of the test to run. Do not need to pass in file extension.")
+	runCmd.Flags().StringSliceVarP(&tests, "test", "t", []string{}, `The names of the tests to run. Do not need to pass in file extension.
+Can be passed in multiple times or as a comma separated list, and can be a glob such as 'examples/*'.
+	`)
+	runCmd.Flags().BoolVar(&all, "all", false, `Run every test file under the test directory. Test files which are included by other test files are not run.
+	`)
+	runCmd.Flags().IntVarP(&parallel, "parallel", "p", 1, `The number of tests to run at the same time. Tests running at the same time prefix the names of their containers
+so they do not clash.
+	`)
 	runCmd.Flags().StringVarP(&stages, "stages", "s", "", `A comma separated list of stages to run from that test.
 For example to only run 'stage1' from a test, add '-s stage1' to your command.
 	`)
-	runCmd.MarkFlagRequired("test")
+	runCmd.Flags().StringVar(&tags, "tags", "", `Only run the stages whose tags match this expression, for example 'smoke && !slow'.
+Tags of a test are added to each of its stages.
+	`)
+	runCmd.Flags().StringVar(&excludeTags, "exclude-tags", "", `Do not run the stages whose tags match this expression, for example 'slow || flaky'.
+	`)
+	runCmd.Flags().StringArrayVar(&vars, "var", []string{}, `Set a variable as KEY=VALUE. Can be passed in multiple times. Takes precedence over the variable files and the step
+variables, matrix values and global variables of the test with the same name.
+	`)
+	runCmd.Flags().StringArrayVar(&varFiles, "var-file", []string{}, `A '.yaml' file with a map of variables or a '.env' file with a KEY=VALUE on each line. Can be passed in multiple
+times, with later files taking precedence. Takes precedence over the step variables, matrix values and global variables of the test.
+	`)
+	runCmd.Flags().BoolVar(&dryRun, "dry-run", false, `Print what each test will do without running any steps or using Docker. Shows the stages and steps in order, the step
+function each step matched, when each stage and step runs and the step variables after interpolation.
+	`)
+	runCmd.Flags().StringVar(&resume, "resume", "", `The ID of an earlier run to resume. The stages it completed are skipped, and the containers, step statuses and step
+outputs it saved are restored. Runs the same tests as the earlier run unless tests are given.
+	`)
+	runCmd.Flags().StringVar(&fromStage, "from-stage", "", `Skip every stage before this stage and run this stage and every stage after it, even if the run being resumed
+completed them.
+	`)
+	runCmd.Flags().DurationVar(&teardownTimeout, "teardown-timeout", time.Minute, `How long the stages and steps which run on failure, such as 'alwaysRuns' stages, have to finish once the run is
+interrupted by SIGINT or SIGTERM. A second interrupt exits immediately.
+	`)
+	runCmd.Flags().BoolVar(&updateSnapshots, "update-snapshots", false, `Create or rewrite the snapshots of 'Match snapshot' steps with the values they are given instead of
+comparing the values with them. Without it, a snapshot which does not exist fails the step.
+	`)
 	rootCmd.AddCommand(runCmd)
 }
+
+func getResultsTable(results []*models.TestResult) *tablewriter.Table {
+	table := tablewriter.NewWriter(os.Stdout)
+
+	table.SetHeader([]string{"Test", "Combination", "Status", "Duration", "Error"})
+	table.SetBorder(false)
+
+	for _, result := range results {
+		table.Append([]string{
+			result.Name,
+			util.MaskSecrets(models.CombinationToString(result.Combination)),
+			models.MapResultStatusToString(result.Status),
+			result.Duration.Round(time.Millisecond).String(),
+			util.MaskSecrets(result.Error),
+		})
+	}
+
+	return table
+}
+
+// getResultsSummary returns how many of the test runs passed, failed and were skipped
+func getResultsSummary(results []*models.TestResult) string {
+	counts := make(map[models.ResultStatus]int)
+	for _, result := range results {
+		counts[result.Status]++
+	}
+	return fmt.Sprintf("Ran %d tests: %d passed, %d failed, %d skipped", len(results), counts[models.Passed], counts[models.Failed], counts[models.Skipped])
+}
+
+// getRunState returns the state of the run given by '--resume', or the state of a new run
+func getRunState() (*operations.RunState, error) {
+	if resume == "" {
+		return operations.NewRunState(), nil
+	}
+	return operations.LoadRunState(resume)
+}
+
+// getPlanOutput returns the plans of a dry run as an indented list of tests, stages and steps
+func getPlanOutput(plans []*models.TestPlan) string {
+	var output strings.Builder
+	for _, plan := range plans {
+		fmt.Fprintf(&output, "Test: %s", plan.Name)
+		if len(plan.Combination) > 0 {
+			fmt.Fprintf(&output, " (%s)", models.CombinationToString(plan.Combination))
+		}
+		output.WriteString("\n")
+		for _, stage := range plan.Stages {
+			fmt.Fprintf(&output, "  Stage: %s [%s]\n", stage.Name, models.MapPlannedRunToString(stage.Runs))
+			if len(stage.Tags) > 0 {
+				fmt.Fprintf(&output, "    tags: %s\n", strings.Join(stage.Tags, ", "))
+			}
+			if stage.If != "" {
+				fmt.Fprintf(&output, "    if: %s\n", stage.If)
+			}
+			for _, step := range stage.Steps {
+				writeStepPlan(&output, step)
+			}
+		}
+	}
+	return output.String()
+}
+
+func writeStepPlan(output *strings.Builder, step *models.StepPlan) {
+	fmt.Fprintf(output, "    Step: %s [%s]\n", step.Description, models.MapPlannedRunToString(step.Runs))
+	if step.Error != "" {
+		fmt.Fprintf(output, "      error: %s\n", step.Error)
+	} else {
+		fmt.Fprintf(output, "      function: %s\n", step.Function)
+	}
+	if step.ID != "" {
+		fmt.Fprintf(output, "      id: %s\n", step.ID)
+	}
+	if step.If != "" {
+		fmt.Fprintf(output, "      if: %s\n", step.If)
+	}
+	if step.Loop != "" {
+		fmt.Fprintf(output, "      loop: %s\n", step.Loop)
+	}
+	if step.ContinueOnError {
+		output.WriteString("      continueOnError: true\n")
+	}
+	keys := []string{}
+	for key := range step.Variables {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+	for _, key := range keys {
+		fmt.Fprintf(output, "      %s: %s\n", key, step.Variables[key])
+	}
+}
+
+// maskError returns an error with the values of secrets masked, or the error itself if it does not contain any secrets
+func maskError(err error) error {
+	if err == nil {
+		return nil
+	}
+	if masked := util.MaskSecrets(err.Error()); masked != err.Error() {
+		return errors.New(masked)
+	}
+	return err
+}
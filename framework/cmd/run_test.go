@@ -2,11 +2,14 @@ package cmd
 
 import (
 	"bytes"
+	"errors"
 	"io/ioutil"
 	"os"
+	"regexp"
 	"testing"
 
 	"github.com/julianGoh17/simple-e2e/framework/internal"
+	"github.com/julianGoh17/simple-e2e/framework/models"
 	"github.com/julianGoh17/simple-e2e/framework/util"
 	"github.com/stretchr/testify/assert"
 )
@@ -107,6 +110,289 @@ func TestRunCmdPassWhenCanFindValidTestFileAndRunningFewStages(t *testing.T) {
 	assert.NotContains(t, output, "Hello there Boy!")
 }
 
+func TestRunCmdOnlyRunsStagesMatchingTags(t *testing.T) {
+	internal.SetTestFilesRoot()
+	rootCmd := NewRootCmd()
+	InitRootCmd(rootCmd)
+
+	read, written, rescue := beginCaptureOfTerminalOutput()
+
+	rootCmd.SetArgs([]string{"run", "-t", "examples/tags-test", "--tags", "smoke", "--exclude-tags", "slow"})
+	assert.NoError(t, rootCmd.Execute())
+
+	output := endCaptureOfTerminalOutput(read, written, rescue)
+
+	assert.Contains(t, output, "Hello there Julian!")
+	assert.NotContains(t, output, "Hello there Coachella!")
+	assert.NotContains(t, output, "Hello there Eugene!")
+}
+
+func TestRunCmdFailsWithInvalidTags(t *testing.T) {
+	internal.SetTestFilesRoot()
+	rootCmd := NewRootCmd()
+	InitRootCmd(rootCmd)
+
+	rootCmd.SetArgs([]string{"run", "-t", "examples/tags-test", "--tags", "smoke &&"})
+	err := rootCmd.Execute()
+	assert.Error(t, err)
+	assert.Equal(t, "Invalid tag expression 'smoke &&': Unexpected end of expression 'smoke &&'", err.Error())
+}
+
+func TestRunCmdRunsMultipleTests(t *testing.T) {
+	internal.SetTestFilesRoot()
+	rootCmd := NewRootCmd()
+	InitRootCmd(rootCmd)
+
+	read, written, rescue := beginCaptureOfTerminalOutput()
+
+	rootCmd.SetArgs([]string{"run", "-t", "test", "-t", "examples/tags-test,examples/c*", "--parallel", "2"})
+	assert.NoError(t, rootCmd.Execute())
+
+	output := endCaptureOfTerminalOutput(read, written, rescue)
+
+	assert.Contains(t, output, "Hello there Boy!")
+	assert.Contains(t, output, "Hello there Mr. Julian!")
+	assert.Contains(t, output, "Hello there Eugene!")
+	assert.Contains(t, output, "Ran 3 tests: 3 passed, 0 failed, 0 skipped")
+}
+
+func TestRunCmdRunsAllTests(t *testing.T) {
+	internal.SetTestFilesRoot()
+	rootCmd := NewRootCmd()
+	InitRootCmd(rootCmd)
+
+	read, written, rescue := beginCaptureOfTerminalOutput()
+
+	rootCmd.SetArgs([]string{"run", "--all", "--tags", "example"})
+	err := rootCmd.Execute()
+
+	output := endCaptureOfTerminalOutput(read, written, rescue)
+
+	assert.NoError(t, err)
+	assert.Contains(t, output, "Ran 11 tests: 1 passed, 0 failed, 10 skipped")
+}
+
+func TestRunCmdFailsWhenSomeTestsFail(t *testing.T) {
+	internal.SetTestFilesRoot()
+	rootCmd := NewRootCmd()
+	InitRootCmd(rootCmd)
+
+	read, written, rescue := beginCaptureOfTerminalOutput()
+
+	rootCmd.SetArgs([]string{"run", "-t", "test,examples/simple-test"})
+	err := rootCmd.Execute()
+
+	output := endCaptureOfTerminalOutput(read, written, rescue)
+
+	assert.Error(t, err)
+	assert.Equal(t, "1 of 2 tests failed", err.Error())
+	assert.Contains(t, output, "Ran 2 tests: 1 passed, 1 failed, 0 skipped")
+}
+
+func TestRunCmdResumesFailedRun(t *testing.T) {
+	internal.SetTestFilesRoot()
+	rootCmd := NewRootCmd()
+	InitRootCmd(rootCmd)
+
+	read, written, rescue := beginCaptureOfTerminalOutput()
+	rootCmd.SetArgs([]string{"run", "-t", "test,examples/simple-test"})
+	assert.Error(t, rootCmd.Execute())
+	output := endCaptureOfTerminalOutput(read, written, rescue)
+
+	matches := regexp.MustCompile(`'run --resume (\S+)'`).FindStringSubmatch(output)
+	if !assert.Equal(t, 2, len(matches), output) {
+		return
+	}
+
+	rootCmd = NewRootCmd()
+	InitRootCmd(rootCmd)
+	read, written, rescue = beginCaptureOfTerminalOutput()
+	rootCmd.SetArgs([]string{"run", "--resume", matches[1]})
+	err := rootCmd.Execute()
+	output = endCaptureOfTerminalOutput(read, written, rescue)
+
+	assert.Error(t, err)
+	assert.Equal(t, "1 of 2 tests failed", err.Error())
+	assert.NotContains(t, output, "Hello there Julian!")
+	assert.Contains(t, output, "Ran 2 tests: 1 passed, 1 failed, 0 skipped")
+
+	rootCmd = NewRootCmd()
+	InitRootCmd(rootCmd)
+	read, written, rescue = beginCaptureOfTerminalOutput()
+	rootCmd.SetArgs([]string{"run", "--resume", matches[1], "-t", "test", "--from-stage", "stage2"})
+	assert.NoError(t, rootCmd.Execute())
+	output = endCaptureOfTerminalOutput(read, written, rescue)
+
+	assert.NotContains(t, output, "Hello there Julian!")
+	assert.Contains(t, output, "Hello there Eugene!")
+}
+
+func TestRunCmdDoesNotSuggestResumingUnsavedRun(t *testing.T) {
+	internal.SetTestFilesRoot()
+	file, err := ioutil.TempFile("", "simple-e2e-state")
+	assert.NoError(t, err)
+	file.Close()
+	defer os.Remove(file.Name())
+	previous := os.Getenv(util.StateDirEnv)
+	os.Setenv(util.StateDirEnv, file.Name())
+	defer os.Setenv(util.StateDirEnv, previous)
+	rootCmd := NewRootCmd()
+	InitRootCmd(rootCmd)
+
+	read, written, rescue := beginCaptureOfTerminalOutput()
+	rootCmd.SetArgs([]string{"run", "-t", "test,examples/simple-test"})
+	assert.Error(t, rootCmd.Execute())
+	output := endCaptureOfTerminalOutput(read, written, rescue)
+
+	assert.Contains(t, output, "Ran 2 tests: 1 passed, 1 failed, 0 skipped")
+	assert.NotContains(t, output, "run --resume")
+}
+
+func TestRunCmdFailsToResumeUnknownRun(t *testing.T) {
+	rootCmd := NewRootCmd()
+	InitRootCmd(rootCmd)
+
+	rootCmd.SetArgs([]string{"run", "--resume", "random"})
+	err := rootCmd.Execute()
+	assert.Error(t, err)
+	assert.Contains(t, err.Error(), "Could not find state of run 'random'")
+}
+
+func TestRunCmdFailsWhenNoTestsFound(t *testing.T) {
+	internal.SetTestFilesRoot()
+	rootCmd := NewRootCmd()
+	InitRootCmd(rootCmd)
+
+	rootCmd.SetArgs([]string{"run", "-t", "random/*"})
+	err := rootCmd.Execute()
+	assert.Error(t, err)
+	assert.Equal(t, "Could not find any test files matching 'random/*'", err.Error())
+}
+
+func TestRunCmdOverridesVariables(t *testing.T) {
+	internal.SetTestFilesRoot()
+	rootCmd := NewRootCmd()
+	InitRootCmd(rootCmd)
+
+	read, written, rescue := beginCaptureOfTerminalOutput()
+
+	rootCmd.SetArgs([]string{"run", "-t", "test", "-s", "stage1", "--var", "NAME=Override"})
+	assert.NoError(t, rootCmd.Execute())
+
+	output := endCaptureOfTerminalOutput(read, written, rescue)
+
+	assert.Contains(t, output, "Hello there Override!")
+	assert.NotContains(t, output, "Hello there Julian!")
+	assert.NotContains(t, output, "Hello there Coachella!")
+}
+
+func TestRunCmdFailsWithInvalidVariables(t *testing.T) {
+	internal.SetTestFilesRoot()
+	rootCmd := NewRootCmd()
+	InitRootCmd(rootCmd)
+
+	rootCmd.SetArgs([]string{"run", "-t", "test", "--var", "NAME"})
+	err := rootCmd.Execute()
+	assert.Error(t, err)
+	assert.Equal(t, "Invalid variable 'NAME', expected KEY=VALUE", err.Error())
+
+	rootCmd = NewRootCmd()
+	InitRootCmd(rootCmd)
+	rootCmd.SetArgs([]string{"run", "-t", "test", "--var-file", "random.env"})
+	err = rootCmd.Execute()
+	assert.Error(t, err)
+	assert.Equal(t, "unable to read variable file: open random.env: no such file or directory", err.Error())
+}
+
+func TestRunCmdRunsTestWithSecrets(t *testing.T) {
+	internal.SetTestFilesRoot()
+	os.Setenv("SIMPLE_E2E_API_TOKEN", "run-cmd-api-token")
+	defer os.Unsetenv("SIMPLE_E2E_API_TOKEN")
+	rootCmd := NewRootCmd()
+	InitRootCmd(rootCmd)
+
+	read, written, rescue := beginCaptureOfTerminalOutput()
+
+	rootCmd.SetArgs([]string{"run", "-t", "examples/secrets-test"})
+	assert.NoError(t, rootCmd.Execute())
+
+	output := endCaptureOfTerminalOutput(read, written, rescue)
+	assert.Contains(t, output, "Hello there Julian!")
+	assert.Equal(t, "Could not log in with ****", maskError(errors.New("Could not log in with run-cmd-api-token")).Error())
+}
+
+func TestMaskError(t *testing.T) {
+	util.RegisterSecret("mask-error-password")
+	err := errors.New("Could not log in")
+
+	assert.Nil(t, maskError(nil))
+	assert.Equal(t, err, maskError(err))
+	assert.Equal(t, "Could not log in with ****", maskError(errors.New("Could not log in with mask-error-password")).Error())
+}
+
+func TestRunCmdDryRun(t *testing.T) {
+	internal.SetTestFilesRoot()
+	rootCmd := NewRootCmd()
+	InitRootCmd(rootCmd)
+
+	read, written, rescue := beginCaptureOfTerminalOutput()
+
+	rootCmd.SetArgs([]string{"run", "-t", "examples/tags-test", "--dry-run", "--exclude-tags", "slow", "--var", "NAME=Override"})
+	assert.NoError(t, rootCmd.Execute())
+
+	output := endCaptureOfTerminalOutput(read, written, rescue)
+	assert.Contains(t, output, `Test: Tags Test
+  Stage: smoke [runs on success]
+    tags: example, smoke
+    Step: Say hello to [runs on success]
+      function: operations.SayHelloTo
+      NAME: Override
+  Stage: soak [not selected]`)
+	assert.NotContains(t, output, "Hello there")
+	assert.NotContains(t, output, "Ran 1 tests")
+}
+
+func TestGetPlanOutput(t *testing.T) {
+	plans := []*models.TestPlan{
+		{
+			Name:        "test",
+			Combination: map[string]string{"TAG": "1.0"},
+			Stages: []*models.StagePlan{
+				{
+					Name: "stage1",
+					If:   "failure()",
+					Runs: models.RunsOnFailure,
+					Steps: []*models.StepPlan{
+						{
+							Description:     "random-step",
+							ID:              "random",
+							Error:           "Step 'random-step' is not registered in step list",
+							If:              "steps.build.status == 'Passed'",
+							Runs:            models.RunsDependingOnSteps,
+							ContinueOnError: true,
+							Loop:            "repeat 2 times",
+							Variables:       map[string]string{"B": "2", "A": "1"},
+						},
+					},
+				},
+			},
+		},
+	}
+
+	assert.Equal(t, `Test: test (TAG=1.0)
+  Stage: stage1 [runs on failure]
+    if: failure()
+    Step: random-step [runs depending on earlier steps]
+      error: Step 'random-step' is not registered in step list
+      id: random
+      if: steps.build.status == 'Passed'
+      loop: repeat 2 times
+      continueOnError: true
+      A: 1
+      B: 2
+`, getPlanOutput(plans))
+}
+
 func beginCaptureOfTerminalOutput() (*os.File, *os.File, *os.File) {
 	rescueStdout := os.Stdout
 	read, written, _ := os.Pipe()
@@ -121,3 +407,60 @@ func endCaptureOfTerminalOutput(read, written, rescueStdout *os.File) string {
 	os.Stdout = rescueStdout
 	return string(out)
 }
+
+func TestGetResultsTable(t *testing.T) {
+	results := []*models.TestResult{
+		{
+			Name:        "test",
+			Combination: map[string]string{"TAG": "1.0"},
+			Status:      models.Passed,
+		},
+		{
+			Name:        "test",
+			Combination: map[string]string{"TAG": "2.0"},
+			Status:      models.Failed,
+			Error:       "Test failed at stage: stage1",
+		},
+	}
+
+	table := getResultsTable(results)
+	assert.Equal(t, 2, table.NumLines())
+	assert.Equal(t, "Ran 2 tests: 1 passed, 1 failed, 0 skipped", getResultsSummary(results))
+}
+
+func TestRunCmdUpdatesSnapshots(t *testing.T) {
+	dir, err := ioutil.TempDir("", "simple-e2e-snapshots")
+	assert.NoError(t, err)
+	defer os.RemoveAll(dir)
+	previous := os.Getenv(util.TestDirEnv)
+	os.Setenv(util.TestDirEnv, dir)
+	defer os.Setenv(util.TestDirEnv, previous)
+
+	test := "name: snapshot\nstages:\n  - name: stage\n    steps:\n      - description: Match snapshot\n        variables:\n          NAME: greeting\n          VALUE: ${GREETING}\n"
+	assert.NoError(t, ioutil.WriteFile(dir+"/snapshot.yaml", []byte(test), 0644))
+
+	for _, table := range []struct {
+		args  []string
+		fails bool
+	}{
+		{[]string{"--var", "GREETING=hello"}, true},
+		{[]string{"--var", "GREETING=hello", "--update-snapshots"}, false},
+		{[]string{"--var", "GREETING=hello"}, false},
+		{[]string{"--var", "GREETING=hi", "--update-snapshots"}, false},
+		{[]string{"--var", "GREETING=hi"}, false},
+		{[]string{"--var", "GREETING=hello"}, true},
+	} {
+		rootCmd := NewRootCmd()
+		InitRootCmd(rootCmd)
+		rootCmd.SetArgs(append([]string{"run", "-t", "snapshot"}, table.args...))
+		if table.fails {
+			assert.Error(t, rootCmd.Execute(), table.args)
+		} else {
+			assert.NoError(t, rootCmd.Execute(), table.args)
+		}
+	}
+
+	snapshot, err := ioutil.ReadFile(dir + "/__snapshots__/greeting.snap")
+	assert.NoError(t, err)
+	assert.Equal(t, "hi\n", string(snapshot))
+}
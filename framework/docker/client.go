@@ -10,6 +10,7 @@ import (
 	"github.com/docker/docker/api/types"
 	"github.com/docker/docker/api/types/container"
 	"github.com/docker/docker/client"
+	"github.com/julianGoh17/simple-e2e/framework/util"
 	"github.com/rs/zerolog"
 )
 
@@ -122,8 +123,26 @@ func (wrapper *WrapperClient) ListContainers(ctx context.Context, showAll bool)
 	return containers, nil
 }
 
+// InspectContainer will return the details of a container, such as its state and network settings, from the host's docker daemon
+func (wrapper *WrapperClient) InspectContainer(ctx context.Context, containerID string) (types.ContainerJSON, error) {
+	logger.Trace().
+		Str("containerID", containerID).
+		Msg("Beginning to inspect container")
+
+	details, err := wrapper.Cli.ContainerInspect(ctx, containerID)
+	if err != nil {
+		logger.Trace().Err(err).Str("containerID", containerID).Msg("Failed to inspect container")
+		return details, err
+	}
+
+	logger.Trace().
+		Str("containerID", containerID).
+		Msg("Successfully inspected container")
+	return details, nil
+}
+
 func readOutputAndCloseReader(reader io.ReadCloser) error {
-	io.Copy(os.Stdout, reader)
+	io.Copy(util.NewSecretMaskingWriter(os.Stdout), reader)
 	return reader.Close()
 }
 
@@ -84,6 +84,17 @@ func TestWrapperClientListContainersFail(t *testing.T) {
 	assert.Nil(t, containers)
 }
 
+func TestWrapperClientInspectContainerFails(t *testing.T) {
+	os.Setenv(internal.DockerHostEnv, internal.UnconnectableDockerHost)
+	defer os.Unsetenv(internal.DockerHostEnv)
+	client := createClient(t)
+
+	ctx := context.Background()
+	_, err := client.InspectContainer(ctx, "random-id")
+	assert.Error(t, err)
+	assert.Equal(t, internal.ErrCanNotConnectToHost.Error(), err.Error())
+}
+
 func createClient(t *testing.T) WrapperClient {
 	client := WrapperClient{}
 	err := client.Initialize()
@@ -3,7 +3,9 @@ package docker
 import (
 	"context"
 	"fmt"
+	"sort"
 	"strings"
+	"sync"
 
 	"github.com/docker/docker/api/types"
 	"github.com/docker/docker/api/types/container"
@@ -20,6 +22,10 @@ var (
 type Handler struct {
 	wrapper           *WrapperClient
 	containerManagers map[string]*ContainerManager
+	// lock protects containerManagers as steps from different test runs can use the handler at the same time
+	lock sync.Mutex
+	// namespace is added to the name of every container created on the daemon so tests which run at the same time do not clash
+	namespace string
 }
 
 // NewHandler will create a handler object intialized and ready to use. Will error if there is any problems with setting up for docker operations
@@ -42,6 +48,31 @@ func NewHandler() (*Handler, error) {
 	return handler, nil
 }
 
+// SetNamespace will prefix the name of every container that the handler creates on the host's daemon with the namespace. Steps still refer to
+// the container by the name they created it with.
+func (handler *Handler) SetNamespace(namespace string) {
+	handler.namespace = namespace
+}
+
+// WithNamespace returns a handler for the same daemon which adds the namespace to its own, so that a test run can create containers with the
+// same names as another run using this handler at the same time. It only manages the containers which this handler found on the daemon, and not
+// the containers created by steps of other runs.
+func (handler *Handler) WithNamespace(namespace string) *Handler {
+	scoped := &Handler{
+		wrapper:           handler.wrapper,
+		containerManagers: make(map[string]*ContainerManager),
+		namespace:         handler.getDaemonContainerName(namespace),
+	}
+	handler.lock.Lock()
+	defer handler.lock.Unlock()
+	for name, manager := range handler.containerManagers {
+		if !manager.created {
+			scoped.containerManagers[name] = manager
+		}
+	}
+	return scoped
+}
+
 // PullImage will pull the image from dockerhub onto the host machine's daemon
 func (handler *Handler) PullImage(image string) error {
 	logger.Trace().
@@ -59,7 +90,7 @@ func (handler *Handler) CreateContainer(image, containerName string) error {
 		Str("containerName", containerName).
 		Msg("Creating container and manager")
 
-	if _, ok := handler.containerManagers[containerName]; ok {
+	if _, ok := handler.getContainerManager(containerName); ok {
 		return traceExitCreateContainerAndContainerManagerError(fmt.Errorf("container with name '%s' already exists", containerName),
 			image,
 			containerName,
@@ -70,16 +101,16 @@ func (handler *Handler) CreateContainer(image, containerName string) error {
 	resp, err := handler.wrapper.CreateContainer(ctx, &container.Config{
 		Image: image,
 		Tty:   false,
-	}, containerName)
+	}, handler.getDaemonContainerName(containerName))
 	if err != nil {
 		return traceExitCreateContainerAndContainerManagerError(err, image, containerName, "Failed to create container")
 	}
 
-	handler.containerManagers[containerName] = &ContainerManager{image: image, containerInfo: &ContainerInfo{
+	handler.setContainerManager(containerName, &ContainerManager{image: image, created: true, containerInfo: &ContainerInfo{
 		Name:  containerName,
 		ID:    resp.ID,
 		Image: image,
-	}}
+	}})
 
 	logger.Trace().
 		Str("image", image).
@@ -95,18 +126,18 @@ func (handler *Handler) DeleteContainer(containerName string) error {
 		Str("containerName", containerName).
 		Msg("Attempting to delete container and corresponding container manager")
 
-	if _, ok := handler.containerManagers[containerName]; !ok {
+	manager, ok := handler.getContainerManager(containerName)
+	if !ok {
 		return traceExitDeleteContainerAndContainerManagerError(fmt.Errorf("Could not find container '%s' in Framework registry", containerName),
 			containerName, "", "Attempted to delete unregistered container")
 	}
 
-	manager := handler.containerManagers[containerName]
 	ctx := context.Background()
 	if err := handler.wrapper.DeleteContainer(ctx, manager.containerInfo.ID); err != nil {
 		return traceExitDeleteContainerAndContainerManagerError(err, containerName, manager.containerInfo.ID, "Failed to delete container")
 	}
 
-	delete(handler.containerManagers, containerName)
+	handler.deleteContainerManager(containerName)
 
 	logger.Trace().
 		Str("containerName", containerName).
@@ -138,6 +169,61 @@ func (handler *Handler) GetContainerInfo(showAll bool) ([]*ContainerInfo, error)
 	return convertToContainerInfo(containers), nil
 }
 
+// GetContainerIP will return the IP address of a running container on its docker network, so the framework can reach ports of the container
+// which are not published on the host
+func (handler *Handler) GetContainerIP(containerName string) (string, error) {
+	logger.Trace().
+		Str("containerName", containerName).
+		Msg("Getting IP address of container")
+
+	manager, ok := handler.getContainerManager(containerName)
+	if !ok {
+		return "", fmt.Errorf("Could not find container '%s' in Framework registry", containerName)
+	}
+
+	ctx := context.Background()
+	details, err := handler.wrapper.InspectContainer(ctx, manager.containerInfo.ID)
+	if err != nil {
+		return "", err
+	}
+	ip := getContainerIPAddress(details)
+	if ip == "" {
+		return "", fmt.Errorf("Container '%s' does not have an IP address, it may not be running", containerName)
+	}
+
+	logger.Trace().
+		Str("containerName", containerName).
+		Str("ip", ip).
+		Msg("Successfully got IP address of container")
+	return ip, nil
+}
+
+// GetCreatedContainers returns the containers which were created by steps (or restored with RestoreContainers) and have not been deleted yet
+func (handler *Handler) GetCreatedContainers() []*ContainerInfo {
+	handler.lock.Lock()
+	defer handler.lock.Unlock()
+	infos := []*ContainerInfo{}
+	for _, manager := range handler.containerManagers {
+		if manager.created {
+			infos = append(infos, manager.containerInfo)
+		}
+	}
+	sort.Slice(infos, func(i, j int) bool { return infos[i].Name < infos[j].Name })
+	return infos
+}
+
+// RestoreContainers will manage containers which were created by the steps of an earlier run, so later steps can refer to them by the name
+// they were created with
+func (handler *Handler) RestoreContainers(infos []*ContainerInfo) {
+	for _, info := range infos {
+		logger.Trace().
+			Str("containerName", info.Name).
+			Str("containerID", info.ID).
+			Msg("Restoring container manager from earlier run")
+		handler.setContainerManager(info.Name, &ContainerManager{image: info.Image, created: true, containerInfo: info})
+	}
+}
+
 func convertToContainerInfo(containers []types.Container) []*ContainerInfo {
 	infos := []*ContainerInfo{}
 	for _, container := range containers {
@@ -147,6 +233,27 @@ func convertToContainerInfo(containers []types.Container) []*ContainerInfo {
 	return infos
 }
 
+// getContainerIPAddress returns the IP address of the container on the default bridge network, or on the first of its other networks by name
+func getContainerIPAddress(details types.ContainerJSON) string {
+	if details.NetworkSettings == nil {
+		return ""
+	}
+	if details.NetworkSettings.IPAddress != "" {
+		return details.NetworkSettings.IPAddress
+	}
+	names := make([]string, 0, len(details.NetworkSettings.Networks))
+	for name := range details.NetworkSettings.Networks {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	for _, name := range names {
+		if network := details.NetworkSettings.Networks[name]; network != nil && network.IPAddress != "" {
+			return network.IPAddress
+		}
+	}
+	return ""
+}
+
 func (handler *Handler) initializeContainerManagers() error {
 	logger.Trace().Msg("Attempting to initialize container managers")
 
@@ -156,13 +263,40 @@ func (handler *Handler) initializeContainerManagers() error {
 	}
 
 	for _, containerInfo := range containerInfos {
-		handler.containerManagers[containerInfo.Name] = &ContainerManager{image: containerInfo.Image, containerInfo: containerInfo}
+		handler.setContainerManager(containerInfo.Name, &ContainerManager{image: containerInfo.Image, containerInfo: containerInfo})
 	}
 
 	logger.Trace().Msg("Successfully initialized contianer managers")
 	return nil
 }
 
+// getDaemonContainerName returns the name of the container on the host's daemon
+func (handler *Handler) getDaemonContainerName(containerName string) string {
+	if handler.namespace == "" {
+		return containerName
+	}
+	return fmt.Sprintf("%s-%s", handler.namespace, containerName)
+}
+
+func (handler *Handler) getContainerManager(containerName string) (*ContainerManager, bool) {
+	handler.lock.Lock()
+	defer handler.lock.Unlock()
+	manager, ok := handler.containerManagers[containerName]
+	return manager, ok
+}
+
+func (handler *Handler) setContainerManager(containerName string, manager *ContainerManager) {
+	handler.lock.Lock()
+	defer handler.lock.Unlock()
+	handler.containerManagers[containerName] = manager
+}
+
+func (handler *Handler) deleteContainerManager(containerName string) {
+	handler.lock.Lock()
+	defer handler.lock.Unlock()
+	delete(handler.containerManagers, containerName)
+}
+
 func traceExitCreateContainerAndContainerManagerError(err error, image, containerName, msg string) error {
 	logger.Trace().
 		Str("image", image).
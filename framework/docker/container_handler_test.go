@@ -6,6 +6,7 @@ import (
 	"testing"
 
 	"github.com/docker/docker/api/types"
+	"github.com/docker/docker/api/types/network"
 	"github.com/julianGoh17/simple-e2e/framework/internal"
 	"github.com/stretchr/testify/assert"
 )
@@ -26,6 +27,50 @@ func TestNewHandlerHasNoNils(t *testing.T) {
 	assert.GreaterOrEqual(t, len(handler.containerManagers), 0)
 }
 
+func TestGetDaemonContainerName(t *testing.T) {
+	handler := &Handler{}
+	assert.Equal(t, "database", handler.getDaemonContainerName("database"))
+
+	handler.SetNamespace("simple-e2e-1-test")
+	assert.Equal(t, "simple-e2e-1-test-database", handler.getDaemonContainerName("database"))
+}
+
+func TestWithNamespace(t *testing.T) {
+	handler := &Handler{containerManagers: make(map[string]*ContainerManager)}
+	handler.setContainerManager("/existing", &ContainerManager{containerInfo: &ContainerInfo{Name: "/existing", ID: "1"}})
+	handler.RestoreContainers([]*ContainerInfo{{Name: "database", ID: "2", Image: existingImage}})
+
+	scoped := handler.WithNamespace("TAG-1.0")
+	assert.Equal(t, "TAG-1.0-database", scoped.getDaemonContainerName("database"))
+	assert.Equal(t, []*ContainerInfo{}, scoped.GetCreatedContainers())
+	_, ok := scoped.getContainerManager("/existing")
+	assert.True(t, ok)
+	_, ok = scoped.getContainerManager("database")
+	assert.False(t, ok)
+
+	scoped.RestoreContainers([]*ContainerInfo{{Name: "web", ID: "3", Image: existingImage}})
+	assert.Equal(t, 1, len(handler.GetCreatedContainers()))
+
+	handler.SetNamespace("simple-e2e-1-test")
+	assert.Equal(t, "simple-e2e-1-test-TAG-1.0-database", handler.WithNamespace("TAG-1.0").getDaemonContainerName("database"))
+}
+
+func TestRestoreAndGetCreatedContainers(t *testing.T) {
+	handler := &Handler{containerManagers: make(map[string]*ContainerManager)}
+	handler.setContainerManager("/existing", &ContainerManager{containerInfo: &ContainerInfo{Name: "/existing", ID: "1"}})
+	assert.Equal(t, []*ContainerInfo{}, handler.GetCreatedContainers())
+
+	infos := []*ContainerInfo{
+		{Name: "web", ID: "3", Image: existingImage},
+		{Name: "database", ID: "2", Image: existingImage},
+	}
+	handler.RestoreContainers(infos)
+	assert.Equal(t, []*ContainerInfo{infos[1], infos[0]}, handler.GetCreatedContainers())
+
+	handler.deleteContainerManager("web")
+	assert.Equal(t, []*ContainerInfo{infos[1]}, handler.GetCreatedContainers())
+}
+
 func TestNewHandlerFailsToInitialize(t *testing.T) {
 	os.Setenv("DOCKER_HOST", "random-host")
 	handler, err := NewHandler()
@@ -224,6 +269,42 @@ func TestGetContainerNamesAndIDs(t *testing.T) {
 	}
 }
 
+func TestHandlerGetContainerIPFails(t *testing.T) {
+	os.Setenv(internal.DockerHostEnv, internal.UnconnectableDockerHost)
+	defer os.Unsetenv(internal.DockerHostEnv)
+	handler := &Handler{wrapper: &WrapperClient{}, containerManagers: make(map[string]*ContainerManager)}
+	assert.NoError(t, handler.wrapper.Initialize())
+	handler.setContainerManager("database", &ContainerManager{containerInfo: &ContainerInfo{Name: "database", ID: "1"}})
+
+	ip, err := handler.GetContainerIP("web")
+	assert.Equal(t, "", ip)
+	assert.EqualError(t, err, "Could not find container 'web' in Framework registry")
+
+	ip, err = handler.GetContainerIP("database")
+	assert.Equal(t, "", ip)
+	assert.EqualError(t, err, internal.ErrCanNotConnectToHost.Error())
+}
+
+func TestGetContainerIPAddress(t *testing.T) {
+	testCases := []struct {
+		details types.ContainerJSON
+		ip      string
+	}{
+		{types.ContainerJSON{}, ""},
+		{types.ContainerJSON{NetworkSettings: &types.NetworkSettings{}}, ""},
+		{types.ContainerJSON{NetworkSettings: &types.NetworkSettings{DefaultNetworkSettings: types.DefaultNetworkSettings{IPAddress: "172.17.0.2"}}}, "172.17.0.2"},
+		{types.ContainerJSON{NetworkSettings: &types.NetworkSettings{Networks: map[string]*network.EndpointSettings{
+			"web":     {IPAddress: "172.19.0.3"},
+			"backend": {IPAddress: "172.18.0.2"},
+			"empty":   nil,
+		}}}, "172.18.0.2"},
+	}
+
+	for _, testCase := range testCases {
+		assert.Equal(t, testCase.ip, getContainerIPAddress(testCase.details))
+	}
+}
+
 func TestMain(m *testing.M) {
 	internal.TestCoverageReaches85Percent(m)
 }
@@ -4,4 +4,6 @@ package docker
 type ContainerManager struct {
 	image         string
 	containerInfo *ContainerInfo
+	// created is whether the container was created by a step, rather than already existing on the host's daemon when the handler was created
+	created bool
 }
@@ -1,6 +1,6 @@
 module github.com/julianGoh17/simple-e2e/framework
 
-go 1.14
+go 1.16
 
 require (
 	github.com/Microsoft/go-winio v0.4.14 // indirect
@@ -10,9 +10,13 @@ require (
 	github.com/docker/docker v17.12.0-ce-rc1.0.20200821074627-7ae5222c72cc+incompatible
 	github.com/docker/go-connections v0.4.0 // indirect
 	github.com/docker/go-units v0.4.0 // indirect
+	github.com/go-sql-driver/mysql v1.6.0
 	github.com/go-yaml/yaml v2.1.0+incompatible
 	github.com/gogo/protobuf v1.3.1 // indirect
+	github.com/golang/protobuf v1.4.2
 	github.com/gorilla/mux v1.8.0 // indirect
+	github.com/jhump/protoreflect v1.8.2
+	github.com/lib/pq v1.10.9
 	github.com/moby/term v0.0.0-20200611042045-63b9a826fb74 // indirect
 	github.com/morikuni/aec v1.0.0 // indirect
 	github.com/olekukonko/tablewriter v0.0.4
@@ -22,7 +26,8 @@ require (
 	github.com/spf13/cobra v1.0.0
 	github.com/spf13/viper v1.7.1
 	github.com/stretchr/testify v1.6.1
-	golang.org/x/net v0.0.0-20200813134508-3edf25e44fcc // indirect
-	google.golang.org/grpc v1.31.0 // indirect
+	golang.org/x/net v0.0.0-20210226172049-e18ecbb05110 // indirect
+	google.golang.org/grpc v1.31.0
 	gopkg.in/yaml.v2 v2.3.0
+	modernc.org/sqlite v1.14.6
 )
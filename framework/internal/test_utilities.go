@@ -53,6 +53,30 @@ func SetTestFilesRoot() {
 	}
 }
 
+// SetStateRoot will set 'STATE_DIR' env to a directory in the system's temporary directory if it's not already set, so tests do not save the
+// state of their runs to the default directory
+func SetStateRoot() {
+	if os.Getenv(util.StateDirEnv) == "" {
+		os.Setenv(util.StateDirEnv, filepath.Join(os.TempDir(), "simple-e2e-state"))
+	}
+}
+
+// SetArtifactsRoot will set 'ARTIFACTS_DIR' env to a directory in the system's temporary directory if it's not already set, so tests do not
+// save artifacts to the default directory
+func SetArtifactsRoot() {
+	if os.Getenv(util.ArtifactsDirEnv) == "" {
+		os.Setenv(util.ArtifactsDirEnv, filepath.Join(os.TempDir(), "simple-e2e-artifacts"))
+	}
+}
+
+// SetWorkspaceRoot will set 'WORKSPACE_DIR' env to a directory in the system's temporary directory if it's not already set, so tests do not
+// create workspaces in the default directory
+func SetWorkspaceRoot() {
+	if os.Getenv(util.WorkspaceDirEnv) == "" {
+		os.Setenv(util.WorkspaceDirEnv, filepath.Join(os.TempDir(), "simple-e2e-workspaces"))
+	}
+}
+
 // TestCoverageReaches85Percent will ensure that test coverage passes 85%
 func TestCoverageReaches85Percent(m *testing.M) {
 	// call flag.Parse() here if TestMain uses flags
@@ -1,9 +1,15 @@
 package main
 
-import "github.com/julianGoh17/simple-e2e/framework/cmd"
+import (
+	"os"
+
+	"github.com/julianGoh17/simple-e2e/framework/cmd"
+)
 
 func main() {
 	rootCmd := cmd.NewRootCmd()
 	cmd.InitRootCmd(rootCmd)
-	rootCmd.Execute()
+	if err := rootCmd.Execute(); err != nil {
+		os.Exit(cmd.GetExitCode(err))
+	}
 }
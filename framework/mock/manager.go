@@ -0,0 +1,79 @@
+package mock
+
+import (
+	"fmt"
+	"sort"
+	"sync"
+	"time"
+)
+
+const stopTimeout = 5 * time.Second
+
+// Manager is responsible for the mock servers of a test, which are given to every step so they use the same servers
+type Manager struct {
+	servers map[int]*Server
+	// lock protects servers as the matrix combinations of a test can run at the same time
+	lock sync.Mutex
+}
+
+// NewManager is a constructor function which returns a Manager without any servers
+func NewManager() *Manager {
+	return &Manager{servers: make(map[int]*Server)}
+}
+
+// Start will return the server on the port, starting it if it is not running. A port of 0 always starts a new server on any free port.
+func (manager *Manager) Start(port int) (*Server, error) {
+	manager.lock.Lock()
+	defer manager.lock.Unlock()
+	if server, ok := manager.servers[port]; ok && port != 0 {
+		return server, nil
+	}
+	server, err := newServer(port)
+	if err != nil {
+		return nil, err
+	}
+	manager.servers[server.GetPort()] = server
+	return server, nil
+}
+
+// Get returns the server running on the port
+func (manager *Manager) Get(port int) (*Server, error) {
+	manager.lock.Lock()
+	defer manager.lock.Unlock()
+	server, ok := manager.servers[port]
+	if !ok {
+		return nil, fmt.Errorf("Could not find mock server on port %d", port)
+	}
+	return server, nil
+}
+
+// Stop will shut down the server running on the port
+func (manager *Manager) Stop(port int) error {
+	manager.lock.Lock()
+	server, ok := manager.servers[port]
+	delete(manager.servers, port)
+	manager.lock.Unlock()
+	if !ok {
+		return fmt.Errorf("Could not find mock server on port %d", port)
+	}
+	return server.stop(stopTimeout)
+}
+
+// StopAll will shut down every server, returning the first error after trying to stop all of them
+func (manager *Manager) StopAll() error {
+	manager.lock.Lock()
+	ports := make([]int, 0, len(manager.servers))
+	for port := range manager.servers {
+		ports = append(ports, port)
+	}
+	manager.lock.Unlock()
+	sort.Ints(ports)
+
+	var firstErr error
+	for _, port := range ports {
+		if err := manager.Stop(port); err != nil && firstErr == nil {
+			firstErr = err
+		}
+	}
+	return firstErr
+}
@@ -0,0 +1,49 @@
+package mock
+
+import (
+	"fmt"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestManagerStartReusesServers(t *testing.T) {
+	manager := NewManager()
+	defer manager.StopAll()
+
+	server, err := manager.Start(0)
+	assert.NoError(t, err)
+	other, err := manager.Start(0)
+	assert.NoError(t, err)
+	assert.NotEqual(t, server.GetPort(), other.GetPort())
+
+	same, err := manager.Start(server.GetPort())
+	assert.NoError(t, err)
+	assert.Equal(t, server, same)
+	found, err := manager.Get(server.GetPort())
+	assert.NoError(t, err)
+	assert.Equal(t, server, found)
+}
+
+func TestManagerStop(t *testing.T) {
+	manager := NewManager()
+	server, err := manager.Start(0)
+	assert.NoError(t, err)
+	port := server.GetPort()
+
+	assert.NoError(t, manager.Stop(port))
+	_, err = manager.Get(port)
+	assert.EqualError(t, err, fmt.Sprintf("Could not find mock server on port %d", port))
+	assert.EqualError(t, manager.Stop(port), fmt.Sprintf("Could not find mock server on port %d", port))
+}
+
+func TestManagerStopAll(t *testing.T) {
+	manager := NewManager()
+	for i := 0; i < 2; i++ {
+		_, err := manager.Start(0)
+		assert.NoError(t, err)
+	}
+	assert.NoError(t, manager.StopAll())
+	assert.Len(t, manager.servers, 0)
+	assert.NoError(t, manager.StopAll())
+}
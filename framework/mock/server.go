@@ -0,0 +1,159 @@
+package mock
+
+import (
+	"context"
+	"fmt"
+	"io/ioutil"
+	"net"
+	"net/http"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/julianGoh17/simple-e2e/framework/util"
+)
+
+var logger = util.GetStandardLogger()
+
+// Server is an HTTP server which responds to requests with the routes given to it and records every request it receives
+type Server struct {
+	port     int
+	listener net.Listener
+	server   *http.Server
+	routes   []*Route
+	requests []Request
+	// lock protects routes and requests as the server handles requests while steps change its routes
+	lock sync.Mutex
+}
+
+// newServer will start a server listening on the port of every interface so containers can reach it. A port of 0 uses any free port.
+func newServer(port int) (*Server, error) {
+	listener, err := net.Listen("tcp", fmt.Sprintf(":%d", port))
+	if err != nil {
+		return nil, fmt.Errorf("Could not start mock server on port %d: %v", port, err)
+	}
+	server := &Server{
+		port:     listener.Addr().(*net.TCPAddr).Port,
+		listener: listener,
+		routes:   []*Route{},
+		requests: []Request{},
+	}
+	server.server = &http.Server{Handler: server}
+	go server.server.Serve(listener)
+	logger.Info().
+		Int("port", server.port).
+		Msg("Started mock server")
+	return server, nil
+}
+
+// GetPort returns the port which the server is listening on
+func (server *Server) GetPort() int {
+	return server.port
+}
+
+// GetURL returns the URL of the server on the host
+func (server *Server) GetURL() string {
+	return fmt.Sprintf("http://localhost:%d", server.port)
+}
+
+// AddRoute will add the route to the server, replacing any route with the same method and path
+func (server *Server) AddRoute(route Route) {
+	server.lock.Lock()
+	defer server.lock.Unlock()
+	logger.Debug().
+		Int("port", server.port).
+		Str("method", route.Method).
+		Str("path", route.Path).
+		Int("status", route.GetStatus()).
+		Msg("Adding route to mock server")
+	for index, existing := range server.routes {
+		if strings.EqualFold(existing.Method, route.Method) && existing.Path == route.Path {
+			server.routes[index] = &route
+			return
+		}
+	}
+	server.routes = append(server.routes, &route)
+}
+
+// GetRequests returns the requests the server has received whose method and path match, where an empty method matches every method and the
+// path can use '*' like the path of a route
+func (server *Server) GetRequests(method, path string) []Request {
+	server.lock.Lock()
+	defer server.lock.Unlock()
+	matcher := &Route{Method: method, Path: path}
+	requests := []Request{}
+	for _, request := range server.requests {
+		if matcher.Matches(request.Method, request.Path) {
+			requests = append(requests, request)
+		}
+	}
+	return requests
+}
+
+// ServeHTTP will record the request and respond with the route which was added last that matches it, or with a 404 if no route matches it
+func (server *Server) ServeHTTP(writer http.ResponseWriter, request *http.Request) {
+	body, _ := ioutil.ReadAll(request.Body)
+	headers := make(map[string]string, len(request.Header))
+	for name, values := range request.Header {
+		headers[name] = strings.Join(values, ", ")
+	}
+	route := server.record(Request{
+		Method:  request.Method,
+		Path:    request.URL.Path,
+		Query:   request.URL.RawQuery,
+		Headers: headers,
+		Body:    string(body),
+		Time:    time.Now(),
+	})
+	logger.Debug().
+		Int("port", server.port).
+		Str("method", request.Method).
+		Str("path", request.URL.Path).
+		Bool("matched", route != nil).
+		Msg("Mock server received request")
+
+	if route == nil {
+		http.Error(writer, fmt.Sprintf("No mock route matches %s %s", request.Method, request.URL.Path), http.StatusNotFound)
+		return
+	}
+	if route.Delay > 0 {
+		select {
+		case <-time.After(route.Delay):
+		case <-request.Context().Done():
+			return
+		}
+	}
+	for name, value := range route.Headers {
+		writer.Header().Set(name, value)
+	}
+	writer.WriteHeader(route.GetStatus())
+	writer.Write([]byte(route.Body))
+}
+
+// record will save the request and return a copy of the route which matches it
+func (server *Server) record(request Request) *Route {
+	server.lock.Lock()
+	defer server.lock.Unlock()
+	server.requests = append(server.requests, request)
+	for index := len(server.routes) - 1; index >= 0; index-- {
+		if server.routes[index].Matches(request.Method, request.Path) {
+			route := *server.routes[index]
+			return &route
+		}
+	}
+	return nil
+}
+
+// stop will shut the server down, waiting up to the timeout for the requests it is handling to finish
+func (server *Server) stop(timeout time.Duration) error {
+	ctx, cancel := context.WithTimeout(context.Background(), timeout)
+	defer cancel()
+	if err := server.server.Shutdown(ctx); err != nil {
+		server.server.Close()
+		return fmt.Errorf("Could not stop mock server on port %d: %v", server.port, err)
+	}
+	logger.Info().
+		Int("port", server.port).
+		Msg("Stopped mock server")
+	return nil
+}
@@ -0,0 +1,100 @@
+package mock
+
+import (
+	"bytes"
+	"fmt"
+	"io/ioutil"
+	"net/http"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func sendRequest(t *testing.T, method, url, body string) (int, string, http.Header) {
+	request, err := http.NewRequest(method, url, bytes.NewBufferString(body))
+	assert.NoError(t, err)
+	response, err := http.DefaultClient.Do(request)
+	if !assert.NoError(t, err) {
+		return 0, "", nil
+	}
+	defer response.Body.Close()
+	responseBody, err := ioutil.ReadAll(response.Body)
+	assert.NoError(t, err)
+	return response.StatusCode, string(responseBody), response.Header
+}
+
+func TestServerRespondsWithRoutes(t *testing.T) {
+	server, err := newServer(0)
+	assert.NoError(t, err)
+	defer server.stop(time.Second)
+	assert.NotEqual(t, 0, server.GetPort())
+	assert.Equal(t, fmt.Sprintf("http://localhost:%d", server.GetPort()), server.GetURL())
+
+	server.AddRoute(Route{Path: "/v1/*", Status: 202, Body: "any"})
+	server.AddRoute(Route{Path: "/v1/weather", Method: "GET", Body: "sunny", Headers: map[string]string{"X-Mock": "true"}})
+	server.AddRoute(Route{Path: "/v1/weather", Method: "get", Body: "rainy"})
+
+	tables := []struct {
+		method string
+		path   string
+		status int
+		body   string
+	}{
+		{"GET", "/v1/weather", 200, "rainy"},
+		{"POST", "/v1/weather", 202, "any"},
+		{"GET", "/v1/users", 202, "any"},
+		{"GET", "/v2/weather", 404, "No mock route matches GET /v2/weather\n"},
+	}
+
+	for _, table := range tables {
+		status, body, headers := sendRequest(t, table.method, server.GetURL()+table.path, "")
+		assert.Equal(t, table.status, status)
+		assert.Equal(t, table.body, body)
+		if table.body == "rainy" {
+			assert.Equal(t, "", headers.Get("X-Mock"))
+		}
+	}
+}
+
+func TestServerRecordsRequests(t *testing.T) {
+	server, err := newServer(0)
+	assert.NoError(t, err)
+	defer server.stop(time.Second)
+	server.AddRoute(Route{Path: "/v1/weather"})
+
+	sendRequest(t, "GET", server.GetURL()+"/v1/weather?city=Toronto", "")
+	sendRequest(t, "POST", server.GetURL()+"/v1/weather", `{"city": "Ottawa"}`)
+	sendRequest(t, "GET", server.GetURL()+"/v1/users", "")
+
+	assert.Len(t, server.GetRequests("", "*"), 3)
+	assert.Len(t, server.GetRequests("", "/*/users"), 1)
+	requests := server.GetRequests("", "/v1/weather")
+	assert.Len(t, requests, 2)
+	assert.Equal(t, "city=Toronto", requests[0].Query)
+	assert.Equal(t, `{"city": "Ottawa"}`, requests[1].Body)
+	assert.Len(t, server.GetRequests("post", "/v1/weather"), 1)
+	assert.Len(t, server.GetRequests("DELETE", "/v1/weather"), 0)
+}
+
+func TestServerDelaysResponse(t *testing.T) {
+	server, err := newServer(0)
+	assert.NoError(t, err)
+	defer server.stop(time.Second)
+	server.AddRoute(Route{Path: "/slow", Delay: 50 * time.Millisecond})
+
+	start := time.Now()
+	status, _, _ := sendRequest(t, "GET", server.GetURL()+"/slow", "")
+	assert.Equal(t, 200, status)
+	assert.True(t, time.Since(start) >= 50*time.Millisecond)
+}
+
+func TestNewServerFailsOnUsedPort(t *testing.T) {
+	server, err := newServer(0)
+	assert.NoError(t, err)
+	defer server.stop(time.Second)
+
+	_, err = newServer(server.GetPort())
+	assert.Error(t, err)
+	assert.Contains(t, err.Error(), fmt.Sprintf("Could not start mock server on port %d", server.GetPort()))
+}
@@ -0,0 +1,51 @@
+package mock
+
+import (
+	"net/http"
+	"path"
+	"strings"
+	"time"
+)
+
+// Route is a response which a mock server gives to the requests matching its method and path
+type Route struct {
+	// Method is the method of the requests the route matches. It matches every method when it is empty or '*'.
+	Method string
+	// Path is the path of the requests the route matches, which can use '*' to match any part of a single path segment, for example '/v1/users/*'. A path of '*' matches every path.
+	Path    string
+	Status  int
+	Body    string
+	Headers map[string]string
+	// Delay is how long the server waits before it responds
+	Delay time.Duration
+}
+
+// Matches returns whether the route matches requests with the method and path
+func (route *Route) Matches(method, requestPath string) bool {
+	if route.Method != "" && route.Method != "*" && !strings.EqualFold(route.Method, method) {
+		return false
+	}
+	if route.Path == "*" {
+		return true
+	}
+	matched, err := path.Match(route.Path, requestPath)
+	return err == nil && matched
+}
+
+// GetStatus returns the status code of the route, which is 200 when it is not set
+func (route *Route) GetStatus() int {
+	if route.Status == 0 {
+		return http.StatusOK
+	}
+	return route.Status
+}
+
+// Request is a request received by a mock server
+type Request struct {
+	Method  string
+	Path    string
+	Query   string
+	Headers map[string]string
+	Body    string
+	Time    time.Time
+}
@@ -0,0 +1,34 @@
+package mock
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestRouteMatches(t *testing.T) {
+	tables := []struct {
+		route   Route
+		method  string
+		path    string
+		matches bool
+	}{
+		{Route{Path: "/v1/weather"}, "GET", "/v1/weather", true},
+		{Route{Path: "/v1/weather", Method: "*"}, "DELETE", "/v1/weather", true},
+		{Route{Path: "/v1/weather", Method: "post"}, "POST", "/v1/weather", true},
+		{Route{Path: "/v1/weather", Method: "POST"}, "GET", "/v1/weather", false},
+		{Route{Path: "/v1/users/*"}, "GET", "/v1/users/1", true},
+		{Route{Path: "/v1/users/*"}, "GET", "/v1/users/1/posts", false},
+		{Route{Path: "/v1/weather"}, "GET", "/v1/weather/today", false},
+		{Route{Path: "*"}, "GET", "/v1/weather/today", true},
+	}
+
+	for _, table := range tables {
+		assert.Equal(t, table.matches, table.route.Matches(table.method, table.path), "%v %s %s", table.route, table.method, table.path)
+	}
+}
+
+func TestRouteGetStatus(t *testing.T) {
+	assert.Equal(t, 200, (&Route{}).GetStatus())
+	assert.Equal(t, 503, (&Route{Status: 503}).GetStatus())
+}
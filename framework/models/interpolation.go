@@ -0,0 +1,44 @@
+package models
+
+import "regexp"
+
+var placeholderPattern = regexp.MustCompile(`\$\{\s*([A-Za-z_][A-Za-z0-9_.\-]*)\s*\}`)
+
+// Interpolate will replace every '${name}' in the value with the value returned by lookup. Placeholders that lookup can not find are left as they are.
+func Interpolate(value string, lookup func(name string) (interface{}, bool)) string {
+	return placeholderPattern.ReplaceAllStringFunc(value, func(placeholder string) string {
+		name := placeholderPattern.FindStringSubmatch(placeholder)[1]
+		if replacement, ok := lookup(name); ok {
+			return stringifyVariable(NormalizeVariable(replacement))
+		}
+		return placeholder
+	})
+}
+
+// interpolateValue will replace every '${name}' in the strings of a YAML value. A string which is only a single placeholder is replaced by the
+// looked up value as is, so lists and maps can be passed around.
+func interpolateValue(value interface{}, lookup func(name string) (interface{}, bool)) interface{} {
+	switch typed := value.(type) {
+	case string:
+		if matches := placeholderPattern.FindStringSubmatch(typed); matches != nil && matches[0] == typed {
+			if replacement, ok := lookup(matches[1]); ok {
+				return NormalizeVariable(replacement)
+			}
+		}
+		return Interpolate(typed, lookup)
+	case []interface{}:
+		interpolated := make([]interface{}, len(typed))
+		for index, val := range typed {
+			interpolated[index] = interpolateValue(val, lookup)
+		}
+		return interpolated
+	case map[string]interface{}:
+		interpolated := make(map[string]interface{}, len(typed))
+		for key, val := range typed {
+			interpolated[key] = interpolateValue(val, lookup)
+		}
+		return interpolated
+	default:
+		return value
+	}
+}
@@ -0,0 +1,80 @@
+package models
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestInterpolate(t *testing.T) {
+	variables := map[string]interface{}{
+		"name":  "weather",
+		"port":  8080,
+		"ports": []int{8080, 8081},
+	}
+	lookup := func(name string) (interface{}, bool) {
+		value, ok := variables[name]
+		return value, ok
+	}
+
+	tables := []struct {
+		value    string
+		expected string
+	}{
+		{"${name}", "weather"},
+		{"http://${name}:${ port }/v1", "http://weather:8080/v1"},
+		{"${ports}", "8080,8081"},
+		{"${unknown} stays", "${unknown} stays"},
+		{"'${string}' is not a variable", "'${string}' is not a variable"},
+		{"no placeholders", "no placeholders"},
+	}
+
+	for _, table := range tables {
+		assert.Equal(t, table.expected, Interpolate(table.value, lookup))
+	}
+}
+
+func TestInterpolateStep(t *testing.T) {
+	step := &Step{}
+	assert.NoError(t, unmarshalStepInto(`
+description: "Start '${name}'"
+variables:
+  NAME: "${name}"
+  URL: "http://${name}:${port}"
+  PORTS: "${ports}"
+  RETRIES: 3
+  NESTED:
+    - "${name}"
+    - port: "${port}"
+with:
+  name: "${name}"
+`, step))
+
+	original := step.Clone()
+	step.Interpolate(func(name string) (interface{}, bool) {
+		value, ok := map[string]interface{}{
+			"name":  "weather",
+			"port":  8080,
+			"ports": []int{8080, 8081},
+		}[name]
+		return value, ok
+	})
+
+	assert.Equal(t, "Start 'weather'", step.Description)
+	assert.Equal(t, map[string]string{
+		"NAME":    "weather",
+		"URL":     "http://weather:8080",
+		"PORTS":   "8080,8081",
+		"RETRIES": "3",
+		"NESTED":  "weather,{\"port\":8080}",
+	}, step.Variables)
+	assert.Equal(t, map[string]interface{}{"name": "weather"}, step.GetArguments())
+
+	ports, err := step.GetValueFromVariablesAsIntegerArray("PORTS")
+	assert.NoError(t, err)
+	assert.Equal(t, []int{8080, 8081}, ports)
+
+	// The original step should not have been changed
+	assert.Equal(t, "${name}", original.Variables["NAME"])
+	assert.Equal(t, map[string]interface{}{"name": "${name}"}, original.GetArguments())
+}
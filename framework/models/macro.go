@@ -0,0 +1,8 @@
+package models
+
+// Macro is a named group of steps which can be called from any stage by setting 'call' on a step. Every parameter must be given a value in the
+// 'with' section of the calling step and can be used in the macro's steps as '${parameter}'.
+type Macro struct {
+	Parameters []string `yaml:"parameters,omitempty"`
+	Steps      []Step   `yaml:",flow"`
+}
@@ -0,0 +1,102 @@
+package models
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+)
+
+// Matrix describes the sets of values that a procedure should be run with. The procedure will be run once for every combination of the
+// parameters' values (minus the combinations matching an entry in Exclude, plus every entry in Include) with the values available as variables.
+type Matrix struct {
+	Parameters map[string][]string `yaml:"parameters,omitempty"`
+	Include    []map[string]string `yaml:"include,omitempty"`
+	Exclude    []map[string]string `yaml:"exclude,omitempty"`
+	// Parallel is the maximum number of combinations to run at the same time. Combinations are run one after another if it is not set.
+	Parallel int `yaml:"parallel,omitempty"`
+}
+
+// Combinations will return every combination of the matrix in a deterministic order
+func (m *Matrix) Combinations() []map[string]string {
+	keys := []string{}
+	for key := range m.Parameters {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+
+	combinations := []map[string]string{}
+	if len(keys) > 0 {
+		combinations = append(combinations, map[string]string{})
+	}
+	for _, key := range keys {
+		expanded := []map[string]string{}
+		for _, combination := range combinations {
+			for _, value := range m.Parameters[key] {
+				next := make(map[string]string, len(combination)+1)
+				for k, v := range combination {
+					next[k] = v
+				}
+				next[key] = value
+				expanded = append(expanded, next)
+			}
+		}
+		combinations = expanded
+	}
+
+	filtered := []map[string]string{}
+	for _, combination := range combinations {
+		if !m.isExcluded(combination) {
+			filtered = append(filtered, combination)
+		}
+	}
+
+	for _, include := range m.Include {
+		if !containsCombination(filtered, include) {
+			filtered = append(filtered, include)
+		}
+	}
+	return filtered
+}
+
+func (m *Matrix) isExcluded(combination map[string]string) bool {
+	for _, exclude := range m.Exclude {
+		if len(exclude) > 0 && matchesCombination(combination, exclude) {
+			return true
+		}
+	}
+	return false
+}
+
+// matchesCombination will return true if every value in 'subset' is the same in the combination
+func matchesCombination(combination, subset map[string]string) bool {
+	for key, value := range subset {
+		if combinationValue, ok := combination[key]; !ok || combinationValue != value {
+			return false
+		}
+	}
+	return true
+}
+
+func containsCombination(combinations []map[string]string, wanted map[string]string) bool {
+	for _, combination := range combinations {
+		if len(combination) == len(wanted) && matchesCombination(combination, wanted) {
+			return true
+		}
+	}
+	return false
+}
+
+// CombinationToString will convert a matrix combination into a human readable string such as 'IMAGE_TAG=1.0, NAME=db'
+func CombinationToString(combination map[string]string) string {
+	keys := []string{}
+	for key := range combination {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+
+	pairs := []string{}
+	for _, key := range keys {
+		pairs = append(pairs, fmt.Sprintf("%s=%s", key, combination[key]))
+	}
+	return strings.Join(pairs, ", ")
+}
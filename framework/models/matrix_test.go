@@ -0,0 +1,72 @@
+package models
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestMatrixCombinations(t *testing.T) {
+	tables := []struct {
+		matrix   *Matrix
+		expected []map[string]string
+	}{
+		{
+			&Matrix{},
+			[]map[string]string{},
+		},
+		{
+			&Matrix{
+				Parameters: map[string][]string{
+					"TAG": {"1.0", "2.0"},
+					"DB":  {"postgres", "mysql"},
+				},
+			},
+			[]map[string]string{
+				{"DB": "postgres", "TAG": "1.0"},
+				{"DB": "postgres", "TAG": "2.0"},
+				{"DB": "mysql", "TAG": "1.0"},
+				{"DB": "mysql", "TAG": "2.0"},
+			},
+		},
+		{
+			&Matrix{
+				Parameters: map[string][]string{
+					"TAG": {"1.0", "2.0"},
+					"DB":  {"postgres", "mysql"},
+				},
+				Exclude: []map[string]string{
+					{"DB": "mysql", "TAG": "1.0"},
+					{"TAG": "2.0"},
+				},
+				Include: []map[string]string{
+					{"DB": "postgres", "TAG": "1.0"},
+					{"DB": "sqlite", "TAG": "edge"},
+				},
+			},
+			[]map[string]string{
+				{"DB": "postgres", "TAG": "1.0"},
+				{"DB": "sqlite", "TAG": "edge"},
+			},
+		},
+		{
+			&Matrix{
+				Include: []map[string]string{
+					{"TAG": "edge"},
+				},
+			},
+			[]map[string]string{
+				{"TAG": "edge"},
+			},
+		},
+	}
+
+	for _, table := range tables {
+		assert.Equal(t, table.expected, table.matrix.Combinations())
+	}
+}
+
+func TestCombinationToString(t *testing.T) {
+	assert.Equal(t, "", CombinationToString(map[string]string{}))
+	assert.Equal(t, "DB=postgres, TAG=1.0", CombinationToString(map[string]string{"TAG": "1.0", "DB": "postgres"}))
+}
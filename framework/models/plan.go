@@ -0,0 +1,77 @@
+package models
+
+// PlannedRun is an enum which represents when a stage or step of a dry run will be run
+type PlannedRun int
+
+const (
+	// RunsOnSuccess means that it is run if nothing before it has failed, which is the default
+	RunsOnSuccess PlannedRun = iota
+	// RunsOnFailure means that it is only run if something before it has failed
+	RunsOnFailure
+	// RunsAlways means that it is run whether or not something before it has failed
+	RunsAlways
+	// RunsNever means that its 'if' expression is never true for the variables of the run
+	RunsNever
+	// RunsDependingOnSteps means that its 'if' expression uses the status or outputs of steps, which are only known once the test is run
+	RunsDependingOnSteps
+	// NotSelected means that the stage was not selected by '--stages' or the tag filters
+	NotSelected
+	// Completed means that the stage is skipped as it comes before '--from-stage' or was completed by the run being resumed
+	Completed
+)
+
+// MapPlannedRunToString will convert the planned run to string
+func MapPlannedRunToString(run PlannedRun) string {
+	switch run {
+	case RunsOnSuccess:
+		return "runs on success"
+	case RunsOnFailure:
+		return "runs on failure"
+	case RunsAlways:
+		return "always runs"
+	case RunsNever:
+		return "never runs"
+	case RunsDependingOnSteps:
+		return "runs depending on earlier steps"
+	case NotSelected:
+		return "not selected"
+	case Completed:
+		return "completed"
+	default:
+		return ""
+	}
+}
+
+// StepPlan is what a step of a dry run will do
+type StepPlan struct {
+	Description string
+	ID          string
+	// Function is the name of the Go function which the description matched, and Error is why no function was matched
+	Function string
+	Error    string
+	If       string
+	Runs     PlannedRun
+	// ContinueOnError is whether the stage carries on as if the step passed when it fails
+	ContinueOnError bool
+	// Loop describes how the step uses 'repeat', 'forEach' and 'until', and is empty if it is only run once
+	Loop string
+	// Variables are the step variables after the run's variables have been interpolated and overrides applied
+	Variables map[string]string
+}
+
+// StagePlan is what a stage of a dry run will do
+type StagePlan struct {
+	Name  string
+	Tags  []string
+	If    string
+	Runs  PlannedRun
+	Steps []*StepPlan
+}
+
+// TestPlan is what a single run of a procedure will do, found without running any steps. When the procedure has a matrix, there is one
+// TestPlan for each combination.
+type TestPlan struct {
+	Name        string
+	Combination map[string]string
+	Stages      []*StagePlan
+}
@@ -0,0 +1,18 @@
+package models
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestMapPlannedRunToString(t *testing.T) {
+	assert.Equal(t, "runs on success", MapPlannedRunToString(RunsOnSuccess))
+	assert.Equal(t, "runs on failure", MapPlannedRunToString(RunsOnFailure))
+	assert.Equal(t, "always runs", MapPlannedRunToString(RunsAlways))
+	assert.Equal(t, "never runs", MapPlannedRunToString(RunsNever))
+	assert.Equal(t, "runs depending on earlier steps", MapPlannedRunToString(RunsDependingOnSteps))
+	assert.Equal(t, "not selected", MapPlannedRunToString(NotSelected))
+	assert.Equal(t, "completed", MapPlannedRunToString(Completed))
+	assert.Equal(t, "", MapPlannedRunToString(PlannedRun(-1)))
+}
@@ -4,10 +4,54 @@ import "os"
 
 // Procedure is a struct which represents the entire
 type Procedure struct {
-	Name            string
-	Description     string
-	GlobalVariables map[string]string `yaml:"globalVariables,omitempty"`
-	Stages          []Stage
+	Name        string
+	Description string
+	// Tags are added to the tags of every stage in the procedure and are used to select which tests and stages are run
+	Tags []string `yaml:"tags,omitempty"`
+	// Include is a list of test files (relative to TEST_DIR) whose stages, global variables and step templates can be used in this procedure
+	Include         []string          `yaml:"include,omitempty"`
+	GlobalVariables map[string]string `yaml:"-"`
+	// Secrets are variables whose values are masked in the logs, results and error messages. They can be used in steps the same way as global
+	// variables. A global variable written as '{value: ..., secret: true}' is added to Secrets.
+	Secrets map[string]Secret `yaml:"secrets,omitempty"`
+	// StepTemplates are named steps which can be reused by setting 'template' on a step
+	StepTemplates map[string]Step `yaml:"stepTemplates,omitempty"`
+	// Macros are named groups of steps which can be reused by setting 'call' on a step
+	Macros map[string]Macro `yaml:"macros,omitempty"`
+	// Matrix will run the procedure once for each combination of its values
+	Matrix *Matrix `yaml:"matrix,omitempty"`
+	Stages []Stage
+}
+
+// UnmarshalYAML will unmarshal the procedure from the test file, moving global variables marked with 'secret: true' into Secrets
+func (p *Procedure) UnmarshalYAML(unmarshal func(interface{}) error) error {
+	type plain Procedure
+	procedure := struct {
+		plain           `yaml:",inline"`
+		GlobalVariables map[string]*globalVariable `yaml:"globalVariables,omitempty"`
+	}{}
+	if err := unmarshal(&procedure); err != nil {
+		return err
+	}
+
+	*p = Procedure(procedure.plain)
+	for key, variable := range procedure.GlobalVariables {
+		if variable == nil {
+			variable = &globalVariable{}
+		}
+		if !variable.Secret {
+			if p.GlobalVariables == nil {
+				p.GlobalVariables = make(map[string]string)
+			}
+			p.GlobalVariables[key] = variable.Value
+			continue
+		}
+		if p.Secrets == nil {
+			p.Secrets = make(map[string]Secret)
+		}
+		p.Secrets[key] = Secret{Value: variable.Value}
+	}
+	return nil
 }
 
 // SetGlobalVariables will set all the variables in 'GlobalVariables' in the terminal as an environmental variable so that it can be
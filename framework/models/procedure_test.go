@@ -94,3 +94,44 @@ func unmarshalYaml(fileName string, t *testing.T) Procedure {
 
 	return procedure
 }
+
+func TestNativeVariablesUnmarshal(t *testing.T) {
+	procedure := unmarshalYaml("native-variables-test", t)
+
+	assert.Equal(t, 1, len(procedure.Stages))
+	assert.Equal(t, 1, len(procedure.Stages[0].Steps))
+
+	step := procedure.Stages[0].Steps[0]
+	assert.Equal(t, "Call API endpoints", step.Description)
+	assert.Equal(t, 7, len(step.Variables))
+	assert.Equal(t, "1.10", step.Variables["VERSION"])
+	assert.Equal(t, "3", step.Variables["RETRIES"])
+	assert.Equal(t, "true", step.Variables["VERBOSE"])
+	assert.Equal(t, "8080,8081", step.Variables["PORTS"])
+	assert.Equal(t, "{\"Accept\":\"application/json\",\"Content-Type\":\"application/json\"}", step.Variables["HEADERS"])
+
+	endpoints, err := step.GetValueFromVariablesAsStringArray("ENDPOINTS")
+	assert.NoError(t, err)
+	assert.Equal(t, []string{"localhost:8080/v1/weather?city=Toronto,Canada", "localhost:8080/v1/time"}, endpoints)
+}
+
+func TestSecretsUnmarshal(t *testing.T) {
+	procedure := unmarshalYaml("secrets-test", t)
+
+	assert.Equal(t, map[string]string{"USER": "Julian"}, procedure.GlobalVariables)
+	assert.Equal(t, map[string]Secret{
+		"PASSWORD":  {Value: "hunter2"},
+		"API_TOKEN": {Env: "SIMPLE_E2E_API_TOKEN"},
+	}, procedure.Secrets)
+}
+
+func TestSecretGlobalVariableFailsWithUnknownField(t *testing.T) {
+	var procedure Procedure
+	err := yaml.UnmarshalStrict([]byte(`
+globalVariables:
+  PASSWORD:
+    value: hunter2
+    secrit: true
+`), &procedure)
+	assert.Error(t, err)
+}
@@ -0,0 +1,102 @@
+package models
+
+import "time"
+
+// ResultStatus is an enum which represents the outcome of a test, stage or step
+type ResultStatus int
+
+const (
+	// Passed means that everything ran successfully
+	Passed ResultStatus = iota
+	// Failed means that an error occurred or a step reported that it has failed
+	Failed
+	// Skipped means that it did not run (for example because an earlier stage failed)
+	Skipped
+)
+
+// MapResultStatusToString will convert the result status to string
+func MapResultStatusToString(status ResultStatus) string {
+	switch status {
+	case Passed:
+		return "Passed"
+	case Failed:
+		return "Failed"
+	case Skipped:
+		return "Skipped"
+	default:
+		return ""
+	}
+}
+
+// MapStringToResultStatus will convert the string result status back to ResultStatus enum
+func MapStringToResultStatus(status string) ResultStatus {
+	switch status {
+	case "Passed":
+		return Passed
+	case "Failed":
+		return Failed
+	default:
+		return Skipped
+	}
+}
+
+// StepResult holds the outcome of a single step
+type StepResult struct {
+	Description string
+	Status      ResultStatus
+	// ContinuedOnError is whether the step failed but was allowed to by 'continueOnError', so its stage carried on as if it had passed
+	ContinuedOnError bool
+	// Iteration is the number of the run (starting at 1) of a step which uses 'repeat' or 'forEach', and Item is its 'forEach' item
+	Iteration int
+	Item      string
+	// Attempts is the number of times a step which uses 'until' was run before its condition was met or it gave up
+	Attempts int
+	Error    string
+	Duration time.Duration
+	// Timings is the time measured by each timer which the step checked, by the name of the timer
+	Timings map[string]time.Duration
+}
+
+// StageResult holds the outcome of a stage and each of its steps
+type StageResult struct {
+	Name     string
+	Status   ResultStatus
+	Steps    []StepResult
+	Duration time.Duration
+}
+
+// AddStep will add the result of a step to the stage. A failed step will fail the stage unless it continued on error.
+func (r *StageResult) AddStep(result StepResult) {
+	r.Steps = append(r.Steps, result)
+	if result.Status == Failed && !result.ContinuedOnError {
+		r.Status = Failed
+	}
+}
+
+// SkipSteps will add a skipped result for each of the steps
+func (r *StageResult) SkipSteps(steps []Step) {
+	for _, step := range steps {
+		r.Steps = append(r.Steps, StepResult{Description: step.Description, Status: Skipped})
+	}
+}
+
+// TestResult holds the outcome of a single run of a procedure. When the procedure has a matrix, there is one TestResult for each combination.
+type TestResult struct {
+	Name        string
+	Combination map[string]string
+	Status      ResultStatus
+	Error       string
+	Stages      []*StageResult
+	Duration    time.Duration
+}
+
+// SetErrored will set the status and error of the result from the error
+func (r *TestResult) SetErrored(err error) {
+	if err != nil {
+		r.Status = Failed
+		r.Error = err.Error()
+	} else {
+		r.Status = Passed
+		r.Error = ""
+	}
+}
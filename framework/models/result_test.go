@@ -0,0 +1,51 @@
+package models
+
+import (
+	"fmt"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestMapResultStatusToString(t *testing.T) {
+	assert.Equal(t, "Passed", MapResultStatusToString(Passed))
+	assert.Equal(t, "Failed", MapResultStatusToString(Failed))
+	assert.Equal(t, "Skipped", MapResultStatusToString(Skipped))
+	assert.Equal(t, "", MapResultStatusToString(ResultStatus(-1)))
+}
+
+func TestMapStringToResultStatus(t *testing.T) {
+	assert.Equal(t, Passed, MapStringToResultStatus("Passed"))
+	assert.Equal(t, Failed, MapStringToResultStatus("Failed"))
+	assert.Equal(t, Skipped, MapStringToResultStatus("Skipped"))
+	assert.Equal(t, Skipped, MapStringToResultStatus("random"))
+}
+
+func TestStageResultAddsSteps(t *testing.T) {
+	result := &StageResult{Name: "stage", Status: Passed}
+
+	result.AddStep(StepResult{Description: "first", Status: Passed})
+	assert.Equal(t, Passed, result.Status)
+
+	result.AddStep(StepResult{Description: "allowed", Status: Failed, ContinuedOnError: true, Error: "failed"})
+	assert.Equal(t, Passed, result.Status)
+
+	result.AddStep(StepResult{Description: "second", Status: Failed, Error: "failed"})
+	assert.Equal(t, Failed, result.Status)
+
+	result.SkipSteps([]Step{{Description: "third"}})
+	assert.Equal(t, 4, len(result.Steps))
+	assert.Equal(t, StepResult{Description: "third", Status: Skipped}, result.Steps[3])
+}
+
+func TestTestResultSetErrored(t *testing.T) {
+	result := &TestResult{}
+
+	result.SetErrored(fmt.Errorf("Random Error"))
+	assert.Equal(t, Failed, result.Status)
+	assert.Equal(t, "Random Error", result.Error)
+
+	result.SetErrored(nil)
+	assert.Equal(t, Passed, result.Status)
+	assert.Equal(t, "", result.Error)
+}
@@ -0,0 +1,35 @@
+package models
+
+import "fmt"
+
+// Secret is a variable whose value is masked in the logs, results and error messages of the test. Its value is given directly, read from an
+// environmental variable or read from a file, and exactly one of them must be set.
+type Secret struct {
+	Value string `yaml:"value,omitempty"`
+	// Env is the name of the environmental variable which holds the value of the secret
+	Env string `yaml:"env,omitempty"`
+	// File is the path of the file which holds the value of the secret. Relative paths are relative to TEST_DIR.
+	File string `yaml:"file,omitempty"`
+}
+
+// globalVariable is a single entry of 'globalVariables' in the test file. It is either a plain value or a map with the value and whether it
+// is a secret, for example '{value: hunter2, secret: true}'.
+type globalVariable struct {
+	Value  string `yaml:"value"`
+	Secret bool   `yaml:"secret,omitempty"`
+}
+
+// UnmarshalYAML will read either a plain value or a map with 'value' and 'secret'
+func (variable *globalVariable) UnmarshalYAML(unmarshal func(interface{}) error) error {
+	var value string
+	if err := unmarshal(&value); err == nil {
+		variable.Value = value
+		return nil
+	}
+
+	type plain globalVariable
+	if err := unmarshal((*plain)(variable)); err != nil {
+		return fmt.Errorf("global variable must be a value or a map with 'value' and 'secret': %v", err)
+	}
+	return nil
+}
@@ -2,7 +2,25 @@ package models
 
 // Stage is a struct which represents the associated test steps in a stage
 type Stage struct {
-	Name       string `yaml:"name"`
-	AlwaysRuns bool   `yaml:"alwaysRuns"`
-	Steps      []Step `yaml:",flow"`
+	Name string `yaml:"name"`
+	// Uses is the name of a stage from an included test file which will be used in place of this stage
+	Uses string `yaml:"uses,omitempty"`
+	// Tags are used to select which stages are run
+	Tags       []string `yaml:"tags,omitempty"`
+	AlwaysRuns bool     `yaml:"alwaysRuns"`
+	// If is an expression which decides whether the stage is run. By default a stage is only run if no earlier stage has failed, or always if
+	// AlwaysRuns is set.
+	If    string `yaml:"if,omitempty"`
+	Steps []Step `yaml:",flow"`
+}
+
+// Clone returns a copy of the stage whose steps do not share their variables with the original stage
+func (s *Stage) Clone() Stage {
+	clone := *s
+	clone.Tags = append([]string(nil), s.Tags...)
+	clone.Steps = make([]Step, len(s.Steps))
+	for index := range s.Steps {
+		clone.Steps[index] = s.Steps[index].Clone()
+	}
+	return clone
 }
@@ -1,20 +1,218 @@
 package models
 
 import (
+	"context"
+	"encoding/json"
 	"fmt"
+	"net/url"
 	"os"
+	"regexp"
 	"strings"
+	"time"
 
 	"github.com/julianGoh17/simple-e2e/framework/docker"
+	"github.com/julianGoh17/simple-e2e/framework/mock"
+	"gopkg.in/yaml.v2"
 )
 
 // Step is the struct that represents that will map the human readable string to the function
 type Step struct {
-	Description  string
-	Variables    map[string]string `yaml:"variables,omitempty"`
+	Description string
+	// ID is the name which later steps and 'if' expressions use to refer to the outputs and status of this step
+	ID string `yaml:"id,omitempty"`
+	// If is an expression which decides whether the step is run. By default a step is only run if no earlier step in its stage has failed.
+	If string `yaml:"if,omitempty"`
+	// AlwaysRuns is whether the step is run even if an earlier step in its stage has failed, which is useful for teardown steps
+	AlwaysRuns bool `yaml:"alwaysRuns,omitempty"`
+	// ContinueOnError is whether the rest of the stage is run as if this step passed when it fails
+	ContinueOnError bool `yaml:"continueOnError,omitempty"`
+	// Repeat is the number of times the step is run. When Until is set it is the maximum number of attempts instead.
+	Repeat int `yaml:"repeat,omitempty"`
+	// ForEach is the list of items which the step is run for. It can be a YAML list or a comma separated string such as '${ENDPOINTS}'.
+	ForEach string `yaml:"-"`
+	// As is the name of the step variable which holds the current item of ForEach, 'ITEM' by default
+	As string `yaml:"as,omitempty"`
+	// Until is an expression which is checked after every attempt of the step. The step is run again every Interval until it is true, for at
+	// most Within and at most Repeat attempts.
+	Until    string `yaml:"until,omitempty"`
+	Within   string `yaml:"within,omitempty"`
+	Interval string `yaml:"interval,omitempty"`
+	// Template is the name of a step template whose description and variables will be used by this step
+	Template string `yaml:"template,omitempty"`
+	// Variables holds the string version of every variable in the test file. The original YAML value (lists, maps, etc.) can be retrieved
+	// with GetValueFromVariables or decoded into a Go type with GetValueFromVariablesInto.
+	Variables map[string]string `yaml:"-"`
+	// UpdateSnapshots is whether snapshot steps rewrite their snapshots instead of comparing with them, which is set by 'run --update-snapshots'
+	UpdateSnapshots bool `yaml:"-"`
+	// Call is the name of a macro which this step will be replaced with. The arguments for the macro are given in With.
+	Call         string            `yaml:"call,omitempty"`
+	With         map[string]string `yaml:"-"`
 	Docker       *docker.Handler
+	Mocks        *mock.Manager `yaml:"-"`
 	converter    TypeConverter
 	isSuccessful bool
+	values       map[string]interface{}
+	withValues   map[string]interface{}
+	runVariables map[string]string
+	outputs      map[string]string
+	forEachValue interface{}
+	ctx          context.Context
+	workspace    string
+	combination  map[string]string
+	timers       *Timers
+	timings      map[string]time.Duration
+}
+
+// UnmarshalYAML will unmarshal the step from the test file, allowing step.variables to contain any YAML value (scalars, lists and maps)
+func (s *Step) UnmarshalYAML(unmarshal func(interface{}) error) error {
+	type plain Step
+	step := struct {
+		plain     `yaml:",inline"`
+		Variables map[string]*variableValue `yaml:"variables,omitempty"`
+		With      map[string]*variableValue `yaml:"with,omitempty"`
+		ForEach   *variableValue            `yaml:"forEach,omitempty"`
+	}{}
+	if err := unmarshal(&step); err != nil {
+		return err
+	}
+
+	*s = Step(step.plain)
+	s.Variables, s.values = splitVariableValues(step.Variables)
+	s.With, s.withValues = splitVariableValues(step.With)
+	if step.ForEach != nil {
+		s.ForEach, s.forEachValue = step.ForEach.text, step.ForEach.value
+	}
+	return nil
+}
+
+// Clone returns a copy of the step which does not share its variables with the original step
+func (s *Step) Clone() Step {
+	clone := *s
+	if s.Variables != nil {
+		clone.Variables = make(map[string]string, len(s.Variables))
+		for key, val := range s.Variables {
+			clone.Variables[key] = val
+		}
+	}
+	if s.values != nil {
+		clone.values = make(map[string]interface{}, len(s.values))
+		for key, val := range s.values {
+			clone.values[key] = val
+		}
+	}
+	if s.With != nil {
+		clone.With = make(map[string]string, len(s.With))
+		for key, val := range s.With {
+			clone.With[key] = val
+		}
+	}
+	if s.withValues != nil {
+		clone.withValues = make(map[string]interface{}, len(s.withValues))
+		for key, val := range s.withValues {
+			clone.withValues[key] = val
+		}
+	}
+	clone.outputs = nil
+	clone.timings = nil
+	return clone
+}
+
+// Interpolate will replace every '${name}' in the description, variables and macro arguments of the step with the value returned by lookup.
+// A variable which is only a single placeholder takes the looked up value as is so lists and maps can be passed around.
+func (s *Step) Interpolate(lookup func(name string) (interface{}, bool)) {
+	s.Description = Interpolate(s.Description, lookup)
+	s.Variables, s.values = interpolateVariables(s.Variables, s.values, lookup)
+	s.With, s.withValues = interpolateVariables(s.With, s.withValues, lookup)
+	if s.HasForEach() {
+		forEach, forEachValues := interpolateVariables(map[string]string{"": s.ForEach}, map[string]interface{}{"": s.forEachValue}, lookup)
+		s.ForEach, s.forEachValue = forEach[""], forEachValues[""]
+	}
+}
+
+// HasForEach returns whether the step is run for each item of a list, even if the list is empty
+func (s *Step) HasForEach() bool {
+	return s.ForEach != "" || s.forEachValue != nil
+}
+
+// GetForEachItems returns the items which the step is run for. A string is split on commas.
+func (s *Step) GetForEachItems() []string {
+	if values, ok := s.forEachValue.([]interface{}); ok {
+		return stringifyList(values)
+	}
+	if strings.TrimSpace(s.ForEach) == "" {
+		return []string{}
+	}
+	items := strings.Split(s.ForEach, ",")
+	for index := range items {
+		items[index] = strings.TrimSpace(items[index])
+	}
+	return items
+}
+
+// GetArguments will return the arguments given to the macro called by this step as they were written in the test file
+func (s *Step) GetArguments() map[string]interface{} {
+	arguments := make(map[string]interface{}, len(s.With))
+	for key, val := range s.With {
+		if value, ok := s.withValues[key]; ok {
+			arguments[key] = value
+		} else {
+			arguments[key] = val
+		}
+	}
+	return arguments
+}
+
+// ApplyTemplate will fill in the description and variables of the step from the template. Anything already set on the step takes precedence
+// over the template.
+func (s *Step) ApplyTemplate(template Step) {
+	if s.Description == "" {
+		s.Description = template.Description
+	}
+	if s.If == "" {
+		s.If = template.If
+	}
+	if s.Repeat == 0 {
+		s.Repeat = template.Repeat
+	}
+	if !s.HasForEach() {
+		s.ForEach, s.forEachValue = template.ForEach, template.forEachValue
+	}
+	if s.As == "" {
+		s.As = template.As
+	}
+	if s.Until == "" {
+		s.Until, s.Within, s.Interval = template.Until, template.Within, template.Interval
+	}
+	s.AlwaysRuns = s.AlwaysRuns || template.AlwaysRuns
+	s.ContinueOnError = s.ContinueOnError || template.ContinueOnError
+	for key, val := range template.Variables {
+		if _, ok := s.Variables[key]; ok {
+			continue
+		}
+		value, ok := template.values[key]
+		if !ok {
+			value = val
+		}
+		s.setVariable(key, val, value)
+	}
+}
+
+// SetVariable will set the variable in step.variables. The value can be any value that can be written in a test file (a string, number, boolean,
+// list or map)
+func (s *Step) SetVariable(variableName string, value interface{}) {
+	value = NormalizeVariable(value)
+	s.setVariable(variableName, stringifyVariable(value), value)
+}
+
+func (s *Step) setVariable(variableName, text string, value interface{}) {
+	if s.Variables == nil {
+		s.Variables = make(map[string]string)
+	}
+	if s.values == nil {
+		s.values = make(map[string]interface{})
+	}
+	s.values[variableName] = value
+	s.Variables[variableName] = text
 }
 
 // GetDescriptionVariables will get the variables from TestStep.Description. For example, "this is a 'variable'" will return ["variable"]
@@ -41,6 +239,43 @@ func (s *Step) GetValueFromVariablesAsString(variableName string) (string, error
 	return "", fmt.Errorf("Could not find variable '%s' in step.variables", variableName)
 }
 
+// GetValueFromVariables will return the variable specific to this step from the step.variables as it was written in the test file (a string, number,
+// boolean, []interface{} or map[string]interface{}) if it exists otherwise it will return an error
+func (s *Step) GetValueFromVariables(variableName string) (interface{}, error) {
+	if val, ok := s.values[variableName]; ok {
+		return val, nil
+	}
+	if val, ok := s.Variables[variableName]; ok {
+		return val, nil
+	}
+	return nil, fmt.Errorf("Could not find variable '%s' in step.variables", variableName)
+}
+
+// GetValueFromVariablesInto will decode the variable specific to this step from the step.variables into 'out', which must be a pointer to a struct,
+// slice, map or any other type that YAML can be decoded into, if it exists otherwise it will return an error
+func (s *Step) GetValueFromVariablesInto(variableName string, out interface{}) error {
+	val, err := s.GetValueFromVariables(variableName)
+	if err != nil {
+		return err
+	}
+
+	var bytes []byte
+	if text, isString := val.(string); isString {
+		if str, isStringPointer := out.(*string); isStringPointer {
+			*str = text
+			return nil
+		}
+		bytes = []byte(text)
+	} else if bytes, err = yaml.Marshal(val); err != nil {
+		return fmt.Errorf("Could not convert variable '%s' to type '%T'", variableName, out)
+	}
+
+	if err := yaml.Unmarshal(bytes, out); err != nil {
+		return fmt.Errorf("Could not convert variable '%s' to type '%T'", variableName, out)
+	}
+	return nil
+}
+
 // GetValueFromVariablesAsInteger will return the variable specific to this step from the step.variables as an integer if it exists otherwise it will return an error
 func (s *Step) GetValueFromVariablesAsInteger(variableName string) (int, error) {
 	if val, ok := s.Variables[variableName]; ok {
@@ -73,57 +308,335 @@ func (s *Step) GetValueFromVariablesAsBoolean(variableName string) (bool, error)
 	return false, fmt.Errorf("Could not find variable '%s' in step.variables", variableName)
 }
 
-// GetValueFromVariablesAsStringArray will return the variable specific to this step from the step.variables as a string array (separated by commas)
+// GetValueFromVariablesAsDuration will return the variable specific to this step from the step.variables as a time.Duration (for example "30s")
 // if it exists otherwise it will return an error
-func (s *Step) GetValueFromVariablesAsStringArray(variableName string) ([]string, error) {
+func (s *Step) GetValueFromVariablesAsDuration(variableName string) (time.Duration, error) {
+	if val, ok := s.Variables[variableName]; ok {
+		return s.converter.GetDuration(val)
+	}
+	return time.Duration(0), fmt.Errorf("Could not find variable '%s' in step.variables", variableName)
+}
+
+// GetValueFromVariablesAsByteSize will return the variable specific to this step from the step.variables as a number of bytes (for example "512Mi")
+// if it exists otherwise it will return an error
+func (s *Step) GetValueFromVariablesAsByteSize(variableName string) (int64, error) {
+	if val, ok := s.Variables[variableName]; ok {
+		return s.converter.GetByteSize(val)
+	}
+	return 0, fmt.Errorf("Could not find variable '%s' in step.variables", variableName)
+}
+
+// GetValueFromVariablesAsURL will return the variable specific to this step from the step.variables as a URL if it exists otherwise it will return an error
+func (s *Step) GetValueFromVariablesAsURL(variableName string) (*url.URL, error) {
+	if val, ok := s.Variables[variableName]; ok {
+		return s.converter.GetURL(val)
+	}
+	return nil, fmt.Errorf("Could not find variable '%s' in step.variables", variableName)
+}
+
+// GetValueFromVariablesAsMap will return the variable specific to this step from the step.variables as a map (a YAML map or "key=value" pairs
+// separated by commas) if it exists otherwise it will return an error
+func (s *Step) GetValueFromVariablesAsMap(variableName string) (map[string]string, error) {
+	if values, ok := s.values[variableName].(map[string]interface{}); ok {
+		converted := make(map[string]string, len(values))
+		for key, value := range values {
+			converted[key] = stringifyVariable(value)
+		}
+		return converted, nil
+	}
 	if val, ok := s.Variables[variableName]; ok {
-		return strings.Split(val, ","), nil
+		return s.converter.GetMap(val)
+	}
+	return map[string]string{}, fmt.Errorf("Could not find variable '%s' in step.variables", variableName)
+}
+
+// GetValueFromVariablesAsRegex will return the variable specific to this step from the step.variables as a compiled regular expression
+// if it exists otherwise it will return an error
+func (s *Step) GetValueFromVariablesAsRegex(variableName string) (*regexp.Regexp, error) {
+	if val, ok := s.Variables[variableName]; ok {
+		return s.converter.GetRegex(val)
+	}
+	return nil, fmt.Errorf("Could not find variable '%s' in step.variables", variableName)
+}
+
+// GetValueFromVariablesAsJSON will decode the variable specific to this step from the step.variables into 'out' as JSON. The variable can either be
+// a JSON string or a YAML list/map. If the variable does not exist it will return an error
+func (s *Step) GetValueFromVariablesAsJSON(variableName string, out interface{}) error {
+	val, err := s.GetValueFromVariables(variableName)
+	if err != nil {
+		return err
+	}
+	if text, isString := val.(string); isString {
+		return s.converter.GetJSON(text, out)
+	}
+	bytes, err := json.Marshal(val)
+	if err != nil {
+		return fmt.Errorf("Could not convert variable '%s' to type '%T'", variableName, out)
+	}
+	return s.converter.GetJSON(string(bytes), out)
+}
+
+// GetValueFromVariablesAsStringArray will return the variable specific to this step from the step.variables as a string array (a YAML list or separated by commas)
+// if it exists otherwise it will return an error
+func (s *Step) GetValueFromVariablesAsStringArray(variableName string) ([]string, error) {
+	if values, ok := s.getVariableAsList(variableName); ok {
+		return values, nil
 	}
 	return []string{}, fmt.Errorf("Could not find variable '%s' in step.variables", variableName)
 }
 
-// GetValueFromVariablesAsIntegerArray will return the variable specific to this step from the step.variables as a integer array (separated by commas)
+// GetValueFromVariablesAsIntegerArray will return the variable specific to this step from the step.variables as a integer array (a YAML list or separated by commas)
 // if it exists otherwise it will return an error
 func (s *Step) GetValueFromVariablesAsIntegerArray(variableName string) ([]int, error) {
-	if val, ok := s.Variables[variableName]; ok {
-		return s.converter.GetIntegerArray(val)
+	if values, ok := s.getVariableAsList(variableName); ok {
+		return s.converter.GetIntegerList(values)
 	}
 	return []int{}, fmt.Errorf("Could not find variable '%s' in step.variables", variableName)
 }
 
-// GetValueFromVariablesAsFloat32Array will return the variable specific to this step from the step.variables as a float32 array (separated by commas)
+// GetValueFromVariablesAsFloat32Array will return the variable specific to this step from the step.variables as a float32 array (a YAML list or separated by commas)
 // if it exists otherwise it will return an error
 func (s *Step) GetValueFromVariablesAsFloat32Array(variableName string) ([]float32, error) {
-	if val, ok := s.Variables[variableName]; ok {
-		return s.converter.GetFloat32Array(val)
+	if values, ok := s.getVariableAsList(variableName); ok {
+		return s.converter.GetFloat32List(values)
 	}
 	return []float32{}, fmt.Errorf("Could not find variable '%s' in step.variables", variableName)
 }
 
-// GetValueFromVariablesAsFloat64Array will return the variable specific to this step from the step.variables as a float64 array (separated by commas)
+// GetValueFromVariablesAsFloat64Array will return the variable specific to this step from the step.variables as a float64 array (a YAML list or separated by commas)
 // if it exists otherwise it will return an error
 func (s *Step) GetValueFromVariablesAsFloat64Array(variableName string) ([]float64, error) {
-	if val, ok := s.Variables[variableName]; ok {
-		return s.converter.GetFloat64Array(val)
+	if values, ok := s.getVariableAsList(variableName); ok {
+		return s.converter.GetFloat64List(values)
 	}
 	return []float64{}, fmt.Errorf("Could not find variable '%s' in step.variables", variableName)
 }
 
-// GetValueFromVariablesAsBooleanArray will return the variable specific to this step from the step.variables as a boolean array (separated by commas)
+// GetValueFromVariablesAsBooleanArray will return the variable specific to this step from the step.variables as a boolean array (a YAML list or separated by commas)
 // if it exists otherwise it will return an error
 func (s *Step) GetValueFromVariablesAsBooleanArray(variableName string) ([]bool, error) {
-	if val, ok := s.Variables[variableName]; ok {
-		return s.converter.GetBooleanArray(val)
+	if values, ok := s.getVariableAsList(variableName); ok {
+		return s.converter.GetBooleanList(values)
 	}
 	return []bool{}, fmt.Errorf("Could not find variable '%s' in step.variables", variableName)
 }
 
-// GetGlobalVariable will return the global variable from the Env vars. This just serves as a wrapper method to make it easier to read the
-// test code
+// GetValueFromVariablesAsStringOrDefault will return the variable specific to this step from the step.variables if it exists otherwise it will
+// return the default value
+func (s *Step) GetValueFromVariablesAsStringOrDefault(variableName, defaultValue string) string {
+	if val, ok := s.Variables[variableName]; ok {
+		return val
+	}
+	return defaultValue
+}
+
+// GetValueFromVariablesAsIntegerOrDefault will return the variable specific to this step from the step.variables as an integer if it exists otherwise
+// it will return the default value. An error is only returned when the variable exists but can not be converted.
+func (s *Step) GetValueFromVariablesAsIntegerOrDefault(variableName string, defaultValue int) (int, error) {
+	if _, ok := s.Variables[variableName]; !ok {
+		return defaultValue, nil
+	}
+	return s.GetValueFromVariablesAsInteger(variableName)
+}
+
+// GetValueFromVariablesAsFloat32OrDefault will return the variable specific to this step from the step.variables as a float32 if it exists otherwise
+// it will return the default value. An error is only returned when the variable exists but can not be converted.
+func (s *Step) GetValueFromVariablesAsFloat32OrDefault(variableName string, defaultValue float32) (float32, error) {
+	if _, ok := s.Variables[variableName]; !ok {
+		return defaultValue, nil
+	}
+	return s.GetValueFromVariablesAsFloat32(variableName)
+}
+
+// GetValueFromVariablesAsFloat64OrDefault will return the variable specific to this step from the step.variables as a float64 if it exists otherwise
+// it will return the default value. An error is only returned when the variable exists but can not be converted.
+func (s *Step) GetValueFromVariablesAsFloat64OrDefault(variableName string, defaultValue float64) (float64, error) {
+	if _, ok := s.Variables[variableName]; !ok {
+		return defaultValue, nil
+	}
+	return s.GetValueFromVariablesAsFloat64(variableName)
+}
+
+// GetValueFromVariablesAsBooleanOrDefault will return the variable specific to this step from the step.variables as a boolean if it exists otherwise
+// it will return the default value. An error is only returned when the variable exists but can not be converted.
+func (s *Step) GetValueFromVariablesAsBooleanOrDefault(variableName string, defaultValue bool) (bool, error) {
+	if _, ok := s.Variables[variableName]; !ok {
+		return defaultValue, nil
+	}
+	return s.GetValueFromVariablesAsBoolean(variableName)
+}
+
+// GetValueFromVariablesAsDurationOrDefault will return the variable specific to this step from the step.variables as a time.Duration if it exists otherwise
+// it will return the default value. An error is only returned when the variable exists but can not be converted.
+func (s *Step) GetValueFromVariablesAsDurationOrDefault(variableName string, defaultValue time.Duration) (time.Duration, error) {
+	if _, ok := s.Variables[variableName]; !ok {
+		return defaultValue, nil
+	}
+	return s.GetValueFromVariablesAsDuration(variableName)
+}
+
+// GetValueFromVariablesAsByteSizeOrDefault will return the variable specific to this step from the step.variables as a number of bytes if it exists otherwise
+// it will return the default value. An error is only returned when the variable exists but can not be converted.
+func (s *Step) GetValueFromVariablesAsByteSizeOrDefault(variableName string, defaultValue int64) (int64, error) {
+	if _, ok := s.Variables[variableName]; !ok {
+		return defaultValue, nil
+	}
+	return s.GetValueFromVariablesAsByteSize(variableName)
+}
+
+// GetValueFromVariablesAsStringArrayOrDefault will return the variable specific to this step from the step.variables as a string array if it exists otherwise
+// it will return the default value. An error is only returned when the variable exists but can not be converted.
+func (s *Step) GetValueFromVariablesAsStringArrayOrDefault(variableName string, defaultValue []string) ([]string, error) {
+	if _, ok := s.Variables[variableName]; !ok {
+		return defaultValue, nil
+	}
+	return s.GetValueFromVariablesAsStringArray(variableName)
+}
+
+// GetValueFromVariablesAsMapOrDefault will return the variable specific to this step from the step.variables as a map if it exists otherwise
+// it will return the default value. An error is only returned when the variable exists but can not be converted.
+func (s *Step) GetValueFromVariablesAsMapOrDefault(variableName string, defaultValue map[string]string) (map[string]string, error) {
+	if _, ok := s.Variables[variableName]; !ok {
+		return defaultValue, nil
+	}
+	return s.GetValueFromVariablesAsMap(variableName)
+}
+
+// GetValueFromVariablesAsURLOrDefault will return the variable specific to this step from the step.variables as a URL if it exists otherwise it
+// will return the default value. An error is only returned when the variable exists but can not be converted.
+func (s *Step) GetValueFromVariablesAsURLOrDefault(variableName string, defaultValue *url.URL) (*url.URL, error) {
+	if _, ok := s.Variables[variableName]; !ok {
+		return defaultValue, nil
+	}
+	return s.GetValueFromVariablesAsURL(variableName)
+}
+
+// GetValueFromVariablesAsRegexOrDefault will return the variable specific to this step from the step.variables as a compiled regular expression if
+// it exists otherwise it will return the default value. An error is only returned when the variable exists but can not be converted.
+func (s *Step) GetValueFromVariablesAsRegexOrDefault(variableName string, defaultValue *regexp.Regexp) (*regexp.Regexp, error) {
+	if _, ok := s.Variables[variableName]; !ok {
+		return defaultValue, nil
+	}
+	return s.GetValueFromVariablesAsRegex(variableName)
+}
+
+// GetValueFromVariablesAsJSONOrDefault will decode the variable specific to this step from the step.variables into 'out' as JSON if it exists
+// otherwise it will leave 'out' as it is, so 'out' should hold the default value. An error is only returned when the variable exists but can not
+// be decoded.
+func (s *Step) GetValueFromVariablesAsJSONOrDefault(variableName string, out interface{}) error {
+	if _, ok := s.Variables[variableName]; !ok {
+		return nil
+	}
+	return s.GetValueFromVariablesAsJSON(variableName, out)
+}
+
+// getVariableAsList will return the variable as a list of strings. Lists written in the test file are returned element by element while
+// everything else is split by commas
+func (s *Step) getVariableAsList(variableName string) ([]string, bool) {
+	if values, ok := s.values[variableName].([]interface{}); ok {
+		return stringifyList(values), true
+	}
+	if val, ok := s.Variables[variableName]; ok {
+		return strings.Split(val, ","), true
+	}
+	return nil, false
+}
+
+// GetGlobalVariable will return the global variable of the test run (the test's global variables and the current matrix values) and if it can't
+// find it there it will return it from the Env vars. This just serves as a wrapper method to make it easier to read the test code
 func (s *Step) GetGlobalVariable(variableName string) string {
+	if val, ok := s.runVariables[variableName]; ok {
+		return val
+	}
 	return os.Getenv(variableName)
 }
 
+// SetRunVariables will set the global variables of the test run which is running this step
+func (s *Step) SetRunVariables(variables map[string]string) {
+	s.runVariables = variables
+}
+
+// SetOutput will save a value which later steps can use as '${steps.<id>.outputs.<name>}' or in 'if' expressions as 'steps.<id>.outputs.<name>'.
+// Outputs are only saved for steps which have an id.
+func (s *Step) SetOutput(name, value string) {
+	if s.outputs == nil {
+		s.outputs = make(map[string]string)
+	}
+	s.outputs[name] = value
+}
+
+// GetOutputs returns the outputs set by the step
+func (s *Step) GetOutputs() map[string]string {
+	return s.outputs
+}
+
+// SetContext will set the context of the step, which is cancelled when the run is interrupted
+func (s *Step) SetContext(ctx context.Context) {
+	s.ctx = ctx
+}
+
+// GetContext returns the context of the step. Steps which take a long time should stop once it is done, as it is cancelled when the run is
+// interrupted.
+func (s *Step) GetContext() context.Context {
+	if s.ctx == nil {
+		return context.Background()
+	}
+	return s.ctx
+}
+
+// SetWorkspace will set the directory of the test run which the file steps read and write files in
+func (s *Step) SetWorkspace(workspace string) {
+	s.workspace = workspace
+}
+
+// GetWorkspace returns the directory of the test run which the file steps read and write files in, which is empty if the step is not part
+// of a test run
+func (s *Step) GetWorkspace() string {
+	return s.workspace
+}
+
+// SetCombination will set the matrix combination of the test run which the step is part of
+func (s *Step) SetCombination(combination map[string]string) {
+	s.combination = combination
+}
+
+// GetCombination returns the matrix combination of the test run which the step is part of, which is empty if the test does not have a matrix
+func (s *Step) GetCombination() map[string]string {
+	return s.combination
+}
+
+// SetTimers will set the timers of the test run which the timer steps start and measure
+func (s *Step) SetTimers(timers *Timers) {
+	s.timers = timers
+}
+
+// GetTimers returns the timers of the test run which the timer steps start and measure, which is nil if the step is not part of a test run
+func (s *Step) GetTimers() *Timers {
+	return s.timers
+}
+
+// RecordTiming will save how much time a timer measured so it is added to the result of the step
+func (s *Step) RecordTiming(timer string, elapsed time.Duration) {
+	if s.timings == nil {
+		s.timings = make(map[string]time.Duration)
+	}
+	s.timings[timer] = elapsed
+}
+
+// GetTimings returns the time measured by each timer which the step recorded, by the name of the timer
+func (s *Step) GetTimings() map[string]time.Duration {
+	return s.timings
+}
+
+// GetRunVariables returns a copy of the global variables of the test run which is running this step
+func (s *Step) GetRunVariables() map[string]string {
+	variables := make(map[string]string, len(s.runVariables))
+	for key, val := range s.runVariables {
+		variables[key] = val
+	}
+	return variables
+}
+
 // CheckIfStepVariablesExists takes in any number of string variables and asserts that step.variables has those variables.
 func (s *Step) CheckIfStepVariablesExists(wantedVariableNames ...string) error {
 	for _, wantedVariableName := range wantedVariableNames {
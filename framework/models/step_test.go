@@ -1,14 +1,37 @@
 package models
 
 import (
+	"context"
 	"fmt"
+	"net/url"
 	"os"
+	"regexp"
 	"testing"
+	"time"
 
 	"github.com/julianGoh17/simple-e2e/framework/internal"
 	"github.com/stretchr/testify/assert"
+	"gopkg.in/yaml.v2"
 )
 
+const nativeVariablesStep = `
+description: "This is a step"
+variables:
+  STRING: "RandomVariable"
+  VERSION: 1.10
+  NUMBERS: [1, 2, 3]
+  STRINGS:
+    - "first,with,commas"
+    - "second"
+  HEADERS:
+    Accept: "application/json"
+  SERVICE:
+    name: "weather"
+    port: 8080
+    tags: ["api", "public"]
+  EMPTY:
+`
+
 func TestGettingDescriptionVariables(t *testing.T) {
 	tables := []struct {
 		Step                         *Step
@@ -584,6 +607,427 @@ func TestStepPassesOrFailsTestCorrectlyDueToError(t *testing.T) {
 	}
 }
 
+func TestUnmarshalStepWithNativeVariables(t *testing.T) {
+	step := unmarshalStep(t, nativeVariablesStep)
+
+	assert.Equal(t, "This is a step", step.Description)
+	assert.Equal(t, map[string]string{
+		"STRING":  "RandomVariable",
+		"VERSION": "1.10",
+		"NUMBERS": "1,2,3",
+		"STRINGS": "first,with,commas,second",
+		"HEADERS": "{\"Accept\":\"application/json\"}",
+		"SERVICE": "{\"name\":\"weather\",\"port\":8080,\"tags\":[\"api\",\"public\"]}",
+		"EMPTY":   "",
+	}, step.Variables)
+
+	list, err := step.GetValueFromVariablesAsStringArray("STRINGS")
+	assert.NoError(t, err)
+	assert.Equal(t, []string{"first,with,commas", "second"}, list)
+
+	numbers, err := step.GetValueFromVariablesAsIntegerArray("NUMBERS")
+	assert.NoError(t, err)
+	assert.Equal(t, []int{1, 2, 3}, numbers)
+
+	version, err := step.GetValueFromVariablesAsFloat64("VERSION")
+	assert.NoError(t, err)
+	assert.Equal(t, 1.1, version)
+}
+
+func TestUnmarshalStepFailsWithUnknownFields(t *testing.T) {
+	step := &Step{}
+	assert.Error(t, yaml.UnmarshalStrict([]byte("description: step\nrandom: field"), step))
+}
+
+func TestGettingValueFromStepVariables(t *testing.T) {
+	step := unmarshalStep(t, nativeVariablesStep)
+
+	tables := []struct {
+		variable string
+		expected interface{}
+		err      error
+	}{
+		{
+			"STRING",
+			"RandomVariable",
+			nil,
+		},
+		{
+			"NUMBERS",
+			[]interface{}{1, 2, 3},
+			nil,
+		},
+		{
+			"HEADERS",
+			map[string]interface{}{"Accept": "application/json"},
+			nil,
+		},
+		{
+			"TEST",
+			nil,
+			fmt.Errorf("Could not find variable '%s' in step.variables", "TEST"),
+		},
+	}
+
+	for _, table := range tables {
+		val, err := step.GetValueFromVariables(table.variable)
+		if table.err == nil {
+			assert.NoError(t, err)
+			assert.Equal(t, table.expected, val)
+		} else {
+			assert.Error(t, err)
+			assert.Equal(t, table.err.Error(), err.Error())
+		}
+	}
+
+	fromGo := &Step{Variables: map[string]string{"TEST": "RandomVariable"}}
+	val, err := fromGo.GetValueFromVariables("TEST")
+	assert.NoError(t, err)
+	assert.Equal(t, "RandomVariable", val)
+}
+
+func TestGettingVariableIntoGoTypes(t *testing.T) {
+	type service struct {
+		Name string
+		Port int
+		Tags []string
+	}
+	step := unmarshalStep(t, nativeVariablesStep)
+
+	var decodedService service
+	assert.NoError(t, step.GetValueFromVariablesInto("SERVICE", &decodedService))
+	assert.Equal(t, service{Name: "weather", Port: 8080, Tags: []string{"api", "public"}}, decodedService)
+
+	var headers map[string]string
+	assert.NoError(t, step.GetValueFromVariablesInto("HEADERS", &headers))
+	assert.Equal(t, map[string]string{"Accept": "application/json"}, headers)
+
+	var numbers []int
+	assert.NoError(t, step.GetValueFromVariablesInto("NUMBERS", &numbers))
+	assert.Equal(t, []int{1, 2, 3}, numbers)
+
+	var str string
+	assert.NoError(t, step.GetValueFromVariablesInto("STRING", &str))
+	assert.Equal(t, "RandomVariable", str)
+
+	err := step.GetValueFromVariablesInto("STRING", &decodedService)
+	assert.Error(t, err)
+	assert.Equal(t, "Could not convert variable 'STRING' to type '*models.service'", err.Error())
+
+	err = step.GetValueFromVariablesInto("TEST", &decodedService)
+	assert.Error(t, err)
+	assert.Equal(t, fmt.Sprintf("Could not find variable '%s' in step.variables", "TEST"), err.Error())
+
+	// Steps created in Go code only have the string version of the variables
+	fromGo := &Step{Variables: map[string]string{"SERVICE": "{name: weather, port: 8080}"}}
+	decodedService = service{}
+	assert.NoError(t, fromGo.GetValueFromVariablesInto("SERVICE", &decodedService))
+	assert.Equal(t, service{Name: "weather", Port: 8080}, decodedService)
+}
+
+func TestGettingExtendedTypesFromStepVariables(t *testing.T) {
+	step := unmarshalStep(t, `
+description: "This is a step"
+variables:
+  TIMEOUT: 30s
+  MEMORY: 512Mi
+  URL: "http://localhost:8080/v1/weather"
+  LABELS: "env=test,team=e2e"
+  HEADERS:
+    Accept: "application/json"
+    Retries: 3
+  PATTERN: "^[a-z]+$"
+  PAYLOAD: '{"some":"data"}'
+  BODY:
+    some: data
+`)
+
+	timeout, err := step.GetValueFromVariablesAsDuration("TIMEOUT")
+	assert.NoError(t, err)
+	assert.Equal(t, 30*time.Second, timeout)
+
+	memory, err := step.GetValueFromVariablesAsByteSize("MEMORY")
+	assert.NoError(t, err)
+	assert.Equal(t, int64(512*1024*1024), memory)
+
+	url, err := step.GetValueFromVariablesAsURL("URL")
+	assert.NoError(t, err)
+	assert.Equal(t, "/v1/weather", url.Path)
+
+	labels, err := step.GetValueFromVariablesAsMap("LABELS")
+	assert.NoError(t, err)
+	assert.Equal(t, map[string]string{"env": "test", "team": "e2e"}, labels)
+
+	headers, err := step.GetValueFromVariablesAsMap("HEADERS")
+	assert.NoError(t, err)
+	assert.Equal(t, map[string]string{"Accept": "application/json", "Retries": "3"}, headers)
+
+	regex, err := step.GetValueFromVariablesAsRegex("PATTERN")
+	assert.NoError(t, err)
+	assert.True(t, regex.MatchString("weather"))
+
+	for _, variable := range []string{"PAYLOAD", "BODY"} {
+		var payload map[string]string
+		assert.NoError(t, step.GetValueFromVariablesAsJSON(variable, &payload))
+		assert.Equal(t, map[string]string{"some": "data"}, payload)
+	}
+
+	missing := fmt.Sprintf("Could not find variable '%s' in step.variables", "TEST")
+	_, err = step.GetValueFromVariablesAsDuration("TEST")
+	assert.Equal(t, missing, err.Error())
+	_, err = step.GetValueFromVariablesAsByteSize("TEST")
+	assert.Equal(t, missing, err.Error())
+	_, err = step.GetValueFromVariablesAsURL("TEST")
+	assert.Equal(t, missing, err.Error())
+	_, err = step.GetValueFromVariablesAsMap("TEST")
+	assert.Equal(t, missing, err.Error())
+	_, err = step.GetValueFromVariablesAsRegex("TEST")
+	assert.Equal(t, missing, err.Error())
+	assert.Equal(t, missing, step.GetValueFromVariablesAsJSON("TEST", &map[string]string{}).Error())
+}
+
+func TestGettingDefaultsFromStepVariables(t *testing.T) {
+	step := &Step{
+		Description: "This is a step",
+		Variables: map[string]string{
+			"TIMEOUT": "5s",
+			"INVALID": "RandomVariable",
+			"ADDRESS": "http://localhost:8080",
+			"PATTERN": "[",
+			"JSON":    `{"env": "prod"}`,
+		},
+	}
+
+	assert.Equal(t, "5s", step.GetValueFromVariablesAsStringOrDefault("TIMEOUT", "1s"))
+	assert.Equal(t, "1s", step.GetValueFromVariablesAsStringOrDefault("TEST", "1s"))
+
+	timeout, err := step.GetValueFromVariablesAsDurationOrDefault("TIMEOUT", time.Second)
+	assert.NoError(t, err)
+	assert.Equal(t, 5*time.Second, timeout)
+
+	timeout, err = step.GetValueFromVariablesAsDurationOrDefault("TEST", time.Second)
+	assert.NoError(t, err)
+	assert.Equal(t, time.Second, timeout)
+
+	_, err = step.GetValueFromVariablesAsDurationOrDefault("INVALID", time.Second)
+	assert.Error(t, err)
+
+	integer, err := step.GetValueFromVariablesAsIntegerOrDefault("TEST", 3)
+	assert.NoError(t, err)
+	assert.Equal(t, 3, integer)
+	_, err = step.GetValueFromVariablesAsIntegerOrDefault("INVALID", 3)
+	assert.Error(t, err)
+
+	float32Value, err := step.GetValueFromVariablesAsFloat32OrDefault("TEST", 1.5)
+	assert.NoError(t, err)
+	assert.Equal(t, float32(1.5), float32Value)
+
+	float64Value, err := step.GetValueFromVariablesAsFloat64OrDefault("TEST", 1.5)
+	assert.NoError(t, err)
+	assert.Equal(t, 1.5, float64Value)
+
+	boolean, err := step.GetValueFromVariablesAsBooleanOrDefault("TEST", true)
+	assert.NoError(t, err)
+	assert.True(t, boolean)
+
+	size, err := step.GetValueFromVariablesAsByteSizeOrDefault("TEST", 1024)
+	assert.NoError(t, err)
+	assert.Equal(t, int64(1024), size)
+
+	list, err := step.GetValueFromVariablesAsStringArrayOrDefault("TEST", []string{"default"})
+	assert.NoError(t, err)
+	assert.Equal(t, []string{"default"}, list)
+
+	labels, err := step.GetValueFromVariablesAsMapOrDefault("TEST", map[string]string{"env": "test"})
+	assert.NoError(t, err)
+	assert.Equal(t, map[string]string{"env": "test"}, labels)
+
+	defaultURL := &url.URL{Scheme: "http", Host: "localhost"}
+	address, err := step.GetValueFromVariablesAsURLOrDefault("TEST", defaultURL)
+	assert.NoError(t, err)
+	assert.Equal(t, defaultURL, address)
+	address, err = step.GetValueFromVariablesAsURLOrDefault("ADDRESS", defaultURL)
+	assert.NoError(t, err)
+	assert.Equal(t, "localhost:8080", address.Host)
+
+	defaultPattern := regexp.MustCompile("^ok$")
+	pattern, err := step.GetValueFromVariablesAsRegexOrDefault("TEST", defaultPattern)
+	assert.NoError(t, err)
+	assert.Equal(t, defaultPattern, pattern)
+	_, err = step.GetValueFromVariablesAsRegexOrDefault("PATTERN", defaultPattern)
+	assert.Error(t, err)
+
+	document := map[string]string{"env": "test"}
+	assert.NoError(t, step.GetValueFromVariablesAsJSONOrDefault("TEST", &document))
+	assert.Equal(t, map[string]string{"env": "test"}, document)
+	assert.NoError(t, step.GetValueFromVariablesAsJSONOrDefault("JSON", &document))
+	assert.Equal(t, map[string]string{"env": "prod"}, document)
+	assert.Error(t, step.GetValueFromVariablesAsJSONOrDefault("INVALID", &document))
+}
+
+func TestCloneDoesNotShareVariables(t *testing.T) {
+	step := unmarshalStep(t, nativeVariablesStep)
+	clone := step.Clone()
+
+	clone.SetVariable("STRING", "Changed")
+	clone.SetVariable("NEW", []string{"first", "second"})
+
+	assert.Equal(t, "RandomVariable", step.Variables["STRING"])
+	assert.Equal(t, "Changed", clone.Variables["STRING"])
+	assert.Equal(t, "first,second", clone.Variables["NEW"])
+	_, err := step.GetValueFromVariables("NEW")
+	assert.Error(t, err)
+
+	list, err := clone.GetValueFromVariablesAsStringArray("NEW")
+	assert.NoError(t, err)
+	assert.Equal(t, []string{"first", "second"}, list)
+}
+
+func TestApplyTemplate(t *testing.T) {
+	template := unmarshalStep(t, nativeVariablesStep)
+	step := &Step{Variables: map[string]string{"STRING": "Overridden"}}
+
+	step.ApplyTemplate(*template)
+
+	assert.Equal(t, "This is a step", step.Description)
+	assert.Equal(t, "Overridden", step.Variables["STRING"])
+	assert.Equal(t, "1.10", step.Variables["VERSION"])
+
+	numbers, err := step.GetValueFromVariablesAsIntegerArray("NUMBERS")
+	assert.NoError(t, err)
+	assert.Equal(t, []int{1, 2, 3}, numbers)
+
+	described := &Step{Description: "Already described"}
+	described.ApplyTemplate(*template)
+	assert.Equal(t, "Already described", described.Description)
+
+	conditional := &Step{}
+	conditional.ApplyTemplate(Step{If: "failure()"})
+	assert.Equal(t, "failure()", conditional.If)
+	conditional.ApplyTemplate(Step{If: "always()"})
+	assert.Equal(t, "failure()", conditional.If)
+
+	teardown := &Step{ContinueOnError: true}
+	teardown.ApplyTemplate(Step{AlwaysRuns: true})
+	assert.True(t, teardown.AlwaysRuns)
+	assert.True(t, teardown.ContinueOnError)
+}
+
+func TestSetOutput(t *testing.T) {
+	step := &Step{ID: "build"}
+	assert.Nil(t, step.GetOutputs())
+
+	step.SetOutput("TAG", "1.0")
+	step.SetOutput("TAG", "2.0")
+	step.SetOutput("IMAGE", "postgres")
+	assert.Equal(t, map[string]string{"TAG": "2.0", "IMAGE": "postgres"}, step.GetOutputs())
+
+	clone := step.Clone()
+	assert.Nil(t, clone.GetOutputs())
+}
+
+func TestStepContext(t *testing.T) {
+	step := &Step{}
+	assert.Equal(t, context.Background(), step.GetContext())
+
+	ctx, cancel := context.WithCancel(context.Background())
+	step.SetContext(ctx)
+	cancel()
+	assert.Equal(t, ctx, step.GetContext())
+	assert.Error(t, step.GetContext().Err())
+}
+
+func TestStepWorkspace(t *testing.T) {
+	step := &Step{}
+	assert.Equal(t, "", step.GetWorkspace())
+	step.SetWorkspace("/tmp/workspace")
+	assert.Equal(t, "/tmp/workspace", step.GetWorkspace())
+	clone := step.Clone()
+	assert.Equal(t, "/tmp/workspace", clone.GetWorkspace())
+}
+
+func TestStepCombination(t *testing.T) {
+	step := &Step{}
+	assert.Empty(t, step.GetCombination())
+	step.SetCombination(map[string]string{"NAME": "first"})
+	assert.Equal(t, map[string]string{"NAME": "first"}, step.GetCombination())
+	clone := step.Clone()
+	assert.Equal(t, map[string]string{"NAME": "first"}, clone.GetCombination())
+}
+
+func TestStepTimers(t *testing.T) {
+	step := &Step{}
+	assert.Nil(t, step.GetTimers())
+	assert.Nil(t, step.GetTimings())
+
+	timers := NewTimers()
+	step.SetTimers(timers)
+	step.RecordTiming("request", 250*time.Millisecond)
+	assert.Equal(t, timers, step.GetTimers())
+	assert.Equal(t, map[string]time.Duration{"request": 250 * time.Millisecond}, step.GetTimings())
+
+	clone := step.Clone()
+	assert.Equal(t, timers, clone.GetTimers())
+	assert.Nil(t, clone.GetTimings())
+}
+
+func TestGetRunVariables(t *testing.T) {
+	step := &Step{}
+	assert.Equal(t, map[string]string{}, step.GetRunVariables())
+
+	variables := map[string]string{"NAME": "e2e"}
+	step.SetRunVariables(variables)
+	copied := step.GetRunVariables()
+	assert.Equal(t, variables, copied)
+	copied["NAME"] = "changed"
+	assert.Equal(t, "e2e", step.GetGlobalVariable("NAME"))
+}
+
+func TestGetForEachItems(t *testing.T) {
+	tables := []struct {
+		data       string
+		hasForEach bool
+		items      []string
+	}{
+		{"description: step", false, []string{}},
+		{"forEach: []", true, []string{}},
+		{"forEach: [first, 2, true]", true, []string{"first", "2", "true"}},
+		{"forEach: \"first, second\"", true, []string{"first", "second"}},
+		{"forEach: \"${NAMES}\"", true, []string{"Julian", "Coachella"}},
+		{"forEach: [\"${NAME}\", last]", true, []string{"Julian", "last"}},
+	}
+
+	lookup := func(name string) (interface{}, bool) {
+		value, ok := map[string]interface{}{"NAMES": "Julian,Coachella", "NAME": "Julian"}[name]
+		return value, ok
+	}
+	for _, table := range tables {
+		step := unmarshalStep(t, table.data)
+		step.Interpolate(lookup)
+		assert.Equal(t, table.hasForEach, step.HasForEach(), table.data)
+		assert.Equal(t, table.items, step.GetForEachItems(), table.data)
+	}
+
+	looping := &Step{}
+	looping.ApplyTemplate(*unmarshalStep(t, "{forEach: [first], as: NAME, repeat: 2, until: success(), within: 1s, interval: 1ms}"))
+	assert.Equal(t, []string{"first"}, looping.GetForEachItems())
+	assert.Equal(t, "NAME", looping.As)
+	assert.Equal(t, 2, looping.Repeat)
+	assert.Equal(t, "success()", looping.Until)
+	assert.Equal(t, "1s", looping.Within)
+	assert.Equal(t, "1ms", looping.Interval)
+}
+
+func unmarshalStep(t *testing.T, data string) *Step {
+	step := &Step{}
+	assert.NoError(t, unmarshalStepInto(data, step))
+	return step
+}
+
+func unmarshalStepInto(data string, step *Step) error {
+	return yaml.UnmarshalStrict([]byte(data), step)
+}
+
 func TestMain(m *testing.M) {
 	internal.TestCoverageReaches85Percent(m)
 }
@@ -0,0 +1,39 @@
+package models
+
+import (
+	"fmt"
+	"sync"
+	"time"
+)
+
+// Timers holds when each timer of a test run was started so that later steps of the run can measure how much time has elapsed since then
+type Timers struct {
+	started map[string]time.Time
+	// lock protects started as a step which was interrupted can still be using the timers when the next step is run
+	lock sync.Mutex
+}
+
+// NewTimers is a constructor function which returns Timers without any started timers
+func NewTimers() *Timers {
+	return &Timers{started: make(map[string]time.Time)}
+}
+
+// Start will start the timer with the name, restarting it if it has already been started, and return when it was started
+func (timers *Timers) Start(name string) time.Time {
+	timers.lock.Lock()
+	defer timers.lock.Unlock()
+	now := time.Now()
+	timers.started[name] = now
+	return now
+}
+
+// Elapsed returns how much time has elapsed since the timer with the name was started, or an error if it has not been started
+func (timers *Timers) Elapsed(name string) (time.Duration, error) {
+	timers.lock.Lock()
+	defer timers.lock.Unlock()
+	started, ok := timers.started[name]
+	if !ok {
+		return 0, fmt.Errorf("Timer '%s' has not been started", name)
+	}
+	return time.Since(started), nil
+}
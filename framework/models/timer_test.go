@@ -0,0 +1,31 @@
+package models
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestTimers(t *testing.T) {
+	timers := NewTimers()
+	_, err := timers.Elapsed("request")
+	if assert.Error(t, err) {
+		assert.Equal(t, "Timer 'request' has not been started", err.Error())
+	}
+
+	started := timers.Start("request")
+	assert.WithinDuration(t, time.Now(), started, time.Second)
+	time.Sleep(10 * time.Millisecond)
+	elapsed, err := timers.Elapsed("request")
+	assert.NoError(t, err)
+	assert.True(t, elapsed >= 10*time.Millisecond, elapsed)
+
+	timers.Start("request")
+	restarted, err := timers.Elapsed("request")
+	assert.NoError(t, err)
+	assert.True(t, restarted < elapsed, restarted)
+
+	_, err = timers.Elapsed("other")
+	assert.Error(t, err)
+}
@@ -1,9 +1,70 @@
 package models
 
 import (
+	"encoding/json"
 	"fmt"
+	"math"
+	"net/url"
+	"regexp"
 	"strconv"
 	"strings"
+	"time"
+)
+
+var (
+	byteSizePattern = regexp.MustCompile(`^\s*([0-9]+(?:\.[0-9]+)?)\s*([A-Za-z]*)\s*$`)
+	byteSizeUnits   = map[string]float64{
+		"":    1,
+		"b":   1,
+		"k":   1e3,
+		"kb":  1e3,
+		"ki":  1 << 10,
+		"kib": 1 << 10,
+		"m":   1e6,
+		"mb":  1e6,
+		"mi":  1 << 20,
+		"mib": 1 << 20,
+		"g":   1e9,
+		"gb":  1e9,
+		"gi":  1 << 30,
+		"gib": 1 << 30,
+		"t":   1e12,
+		"tb":  1e12,
+		"ti":  1 << 40,
+		"tib": 1 << 40,
+	}
+	durationPattern     = regexp.MustCompile(`^\s*[0-9]+(?:\.[0-9]+)?\s*[a-zµ]+(?:\s*(?:,|\sand\s)?\s*[0-9]+(?:\.[0-9]+)?\s*[a-zµ]+)*\s*$`)
+	durationPartPattern = regexp.MustCompile(`([0-9]+(?:\.[0-9]+)?)\s*([a-zµ]+)`)
+	durationUnits       = map[string]time.Duration{
+		"ns":           time.Nanosecond,
+		"nanosecond":   time.Nanosecond,
+		"nanoseconds":  time.Nanosecond,
+		"us":           time.Microsecond,
+		"µs":           time.Microsecond,
+		"microsecond":  time.Microsecond,
+		"microseconds": time.Microsecond,
+		"ms":           time.Millisecond,
+		"millisecond":  time.Millisecond,
+		"milliseconds": time.Millisecond,
+		"s":            time.Second,
+		"sec":          time.Second,
+		"secs":         time.Second,
+		"second":       time.Second,
+		"seconds":      time.Second,
+		"m":            time.Minute,
+		"min":          time.Minute,
+		"mins":         time.Minute,
+		"minute":       time.Minute,
+		"minutes":      time.Minute,
+		"h":            time.Hour,
+		"hr":           time.Hour,
+		"hrs":          time.Hour,
+		"hour":         time.Hour,
+		"hours":        time.Hour,
+		"d":            24 * time.Hour,
+		"day":          24 * time.Hour,
+		"days":         24 * time.Hour,
+	}
 )
 
 // TypeConverter aims to convert the string variable in the step.variables and converts it to the appropriate type wanted by the user
@@ -46,62 +107,180 @@ func (converter *TypeConverter) GetBoolean(variable string) (bool, error) {
 	return value, nil
 }
 
+// GetDuration converts the string'd variable (for example "30s", "1h15m", "1.5 minutes" or "1 minute and 30 seconds") and converts it to a
+// time.Duration if possible otherwise will return an error
+func (converter *TypeConverter) GetDuration(variable string) (time.Duration, error) {
+	if value, err := time.ParseDuration(strings.TrimSpace(variable)); err == nil {
+		return value, nil
+	}
+	value, ok := parseHumanDuration(variable)
+	if !ok {
+		return time.Duration(0), fmt.Errorf("Could not convert '%s' to type 'time.Duration'", variable)
+	}
+	return value, nil
+}
+
+// parseHumanDuration adds up the numbers and units of a duration written out in words, such as "2 minutes, 30 seconds" or "1 hour and 5 mins"
+func parseHumanDuration(variable string) (time.Duration, bool) {
+	text := strings.ToLower(variable)
+	if !durationPattern.MatchString(text) {
+		return 0, false
+	}
+	total := float64(0)
+	for _, part := range durationPartPattern.FindAllStringSubmatch(text, -1) {
+		unit, ok := durationUnits[part[2]]
+		if !ok {
+			return 0, false
+		}
+		value, err := strconv.ParseFloat(part[1], 64)
+		if err != nil {
+			return 0, false
+		}
+		total += value * float64(unit)
+	}
+	if total >= math.MaxInt64 {
+		return 0, false
+	}
+	return time.Duration(total), true
+}
+
+// GetByteSize converts the string'd variable (for example "512Mi", "1.5GB" or "1024") and converts it to a number of bytes if possible otherwise
+// will return an error. Decimal units (K, M, G, T) are powers of 1000 and binary units (Ki, Mi, Gi, Ti) are powers of 1024.
+func (converter *TypeConverter) GetByteSize(variable string) (int64, error) {
+	matches := byteSizePattern.FindStringSubmatch(variable)
+	if matches == nil {
+		return 0, fmt.Errorf("Could not convert '%s' to type 'byte size'", variable)
+	}
+	unit, ok := byteSizeUnits[strings.ToLower(matches[2])]
+	if !ok {
+		return 0, fmt.Errorf("Could not convert '%s' to type 'byte size'", variable)
+	}
+	value, err := strconv.ParseFloat(matches[1], 64)
+	if err != nil || value*unit >= math.MaxInt64 {
+		return 0, fmt.Errorf("Could not convert '%s' to type 'byte size'", variable)
+	}
+	return int64(value * unit), nil
+}
+
+// GetURL converts the string'd variable and converts it to an absolute URL (with a scheme and host) if possible otherwise will return an error
+func (converter *TypeConverter) GetURL(variable string) (*url.URL, error) {
+	value, err := url.Parse(strings.TrimSpace(variable))
+	if err != nil || value.Scheme == "" || value.Host == "" {
+		return nil, fmt.Errorf("Could not convert '%s' to type '*url.URL'", variable)
+	}
+	return value, nil
+}
+
+// GetMap converts the string'd variable, splits it by "," into "key=value" pairs, and converts it to a map if possible otherwise will return an error
+func (converter *TypeConverter) GetMap(variable string) (map[string]string, error) {
+	value := map[string]string{}
+	if strings.TrimSpace(variable) == "" {
+		return value, nil
+	}
+	for _, pair := range strings.Split(variable, ",") {
+		components := strings.SplitN(pair, "=", 2)
+		key := strings.TrimSpace(components[0])
+		if len(components) != 2 || key == "" {
+			return map[string]string{}, fmt.Errorf("Could not convert '%s' to type 'map[string]string'", variable)
+		}
+		value[key] = strings.TrimSpace(components[1])
+	}
+	return value, nil
+}
+
+// GetRegex converts the string'd variable and compiles it to a regular expression if possible otherwise will return an error
+func (converter *TypeConverter) GetRegex(variable string) (*regexp.Regexp, error) {
+	value, err := regexp.Compile(variable)
+	if err != nil {
+		return nil, fmt.Errorf("Could not convert '%s' to type '*regexp.Regexp'", variable)
+	}
+	return value, nil
+}
+
+// GetJSON converts the string'd variable and decodes the JSON into 'out' (which must be a pointer) if possible otherwise will return an error
+func (converter *TypeConverter) GetJSON(variable string, out interface{}) error {
+	if err := json.Unmarshal([]byte(variable), out); err != nil {
+		return fmt.Errorf("Could not convert '%s' to type '%T'", variable, out)
+	}
+	return nil
+}
+
 // GetIntegerArray converts the string'd variable, splits it by ",", and converts it to an array of integers
 // if possible otherwise will return an error
 func (converter *TypeConverter) GetIntegerArray(variables string) ([]int, error) {
-	components := strings.Split(variables, ",")
-	intArray := []int{}
-	for _, component := range components {
-		parsedInt, err := converter.GetInteger(component)
+	return converter.GetIntegerList(strings.Split(variables, ","))
+}
+
+// GetIntegerList converts each of the string'd variables to an int and returns them as an array of integers
+// if possible otherwise will return an error
+func (converter *TypeConverter) GetIntegerList(variables []string) ([]int, error) {
+	array := []int{}
+	for _, variable := range variables {
+		parsed, err := converter.GetInteger(variable)
 		if err != nil {
-			return []int{}, fmt.Errorf("Could not convert '%s' to type '[]int'", variables)
+			return []int{}, fmt.Errorf("Could not convert '%s' to type '[]int'", strings.Join(variables, ","))
 		}
-		intArray = append(intArray, parsedInt)
+		array = append(array, parsed)
 	}
-	return intArray, nil
+	return array, nil
 }
 
 // GetFloat32Array converts the string'd variable, splits it by ",", and converts it to an array of float32s
 // if possible otherwise will return an error
 func (converter *TypeConverter) GetFloat32Array(variables string) ([]float32, error) {
-	components := strings.Split(variables, ",")
-	intArray := []float32{}
-	for _, component := range components {
-		parsedInt, err := converter.GetFloat32(component)
+	return converter.GetFloat32List(strings.Split(variables, ","))
+}
+
+// GetFloat32List converts each of the string'd variables to a float32 and returns them as an array of float32s
+// if possible otherwise will return an error
+func (converter *TypeConverter) GetFloat32List(variables []string) ([]float32, error) {
+	array := []float32{}
+	for _, variable := range variables {
+		parsed, err := converter.GetFloat32(variable)
 		if err != nil {
-			return []float32{}, fmt.Errorf("Could not convert '%s' to type '[]float32'", variables)
+			return []float32{}, fmt.Errorf("Could not convert '%s' to type '[]float32'", strings.Join(variables, ","))
 		}
-		intArray = append(intArray, parsedInt)
+		array = append(array, parsed)
 	}
-	return intArray, nil
+	return array, nil
 }
 
 // GetFloat64Array converts the string'd variable, splits it by ",", and converts it to an array of float64s
 // if possible otherwise will return an error
 func (converter *TypeConverter) GetFloat64Array(variables string) ([]float64, error) {
-	components := strings.Split(variables, ",")
-	intArray := []float64{}
-	for _, component := range components {
-		parsedInt, err := converter.GetFloat64(component)
+	return converter.GetFloat64List(strings.Split(variables, ","))
+}
+
+// GetFloat64List converts each of the string'd variables to a float64 and returns them as an array of float64s
+// if possible otherwise will return an error
+func (converter *TypeConverter) GetFloat64List(variables []string) ([]float64, error) {
+	array := []float64{}
+	for _, variable := range variables {
+		parsed, err := converter.GetFloat64(variable)
 		if err != nil {
-			return []float64{}, fmt.Errorf("Could not convert '%s' to type '[]float64'", variables)
+			return []float64{}, fmt.Errorf("Could not convert '%s' to type '[]float64'", strings.Join(variables, ","))
 		}
-		intArray = append(intArray, parsedInt)
+		array = append(array, parsed)
 	}
-	return intArray, nil
+	return array, nil
 }
 
 // GetBooleanArray converts the string'd variable, splits it by ",", and converts it to an array of booleans
 // if possible otherwise will return an error
 func (converter *TypeConverter) GetBooleanArray(variables string) ([]bool, error) {
-	components := strings.Split(variables, ",")
-	intArray := []bool{}
-	for _, component := range components {
-		parsedInt, err := converter.GetBoolean(component)
+	return converter.GetBooleanList(strings.Split(variables, ","))
+}
+
+// GetBooleanList converts each of the string'd variables to a bool and returns them as an array of booleans
+// if possible otherwise will return an error
+func (converter *TypeConverter) GetBooleanList(variables []string) ([]bool, error) {
+	array := []bool{}
+	for _, variable := range variables {
+		parsed, err := converter.GetBoolean(variable)
 		if err != nil {
-			return []bool{}, fmt.Errorf("Could not convert '%s' to type '[]bool'", variables)
+			return []bool{}, fmt.Errorf("Could not convert '%s' to type '[]bool'", strings.Join(variables, ","))
 		}
-		intArray = append(intArray, parsedInt)
+		array = append(array, parsed)
 	}
-	return intArray, nil
+	return array, nil
 }
@@ -2,8 +2,10 @@ package models
 
 import (
 	"fmt"
-	"github.com/stretchr/testify/assert"
 	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
 )
 
 func TestConvertingToInteger(t *testing.T) {
@@ -301,3 +303,268 @@ func TestConvertingToBooleanArray(t *testing.T) {
 		}
 	}
 }
+
+func TestConvertingListsKeepsWholeErrorMessage(t *testing.T) {
+	converter := TypeConverter{}
+
+	ints, err := converter.GetIntegerList([]string{"1", "2"})
+	assert.NoError(t, err)
+	assert.Equal(t, []int{1, 2}, ints)
+
+	_, err = converter.GetIntegerList([]string{"1", "two"})
+	assert.Error(t, err)
+	assert.Equal(t, "Could not convert '1,two' to type '[]int'", err.Error())
+}
+
+func TestConvertingToDuration(t *testing.T) {
+	tables := []struct {
+		variable string
+		expected time.Duration
+		err      error
+	}{
+		{
+			"30s",
+			30 * time.Second,
+			nil,
+		},
+		{
+			"1h15m",
+			75 * time.Minute,
+			nil,
+		},
+		{
+			"30",
+			0,
+			fmt.Errorf("Could not convert '30' to type 'time.Duration'"),
+		},
+		{
+			"2 seconds",
+			2 * time.Second,
+			nil,
+		},
+		{
+			"1.5 Minutes",
+			90 * time.Second,
+			nil,
+		},
+		{
+			"1 hour, 5 mins and 30 sec",
+			time.Hour + 5*time.Minute + 30*time.Second,
+			nil,
+		},
+		{
+			"1 day 500 ms",
+			24*time.Hour + 500*time.Millisecond,
+			nil,
+		},
+		{
+			"5 fortnights",
+			0,
+			fmt.Errorf("Could not convert '5 fortnights' to type 'time.Duration'"),
+		},
+		{
+			"1.2.3 seconds",
+			0,
+			fmt.Errorf("Could not convert '1.2.3 seconds' to type 'time.Duration'"),
+		},
+		{
+			"seconds",
+			0,
+			fmt.Errorf("Could not convert 'seconds' to type 'time.Duration'"),
+		},
+		{
+			"9223372036854775808 nanoseconds",
+			0,
+			fmt.Errorf("Could not convert '9223372036854775808 nanoseconds' to type 'time.Duration'"),
+		},
+		{
+			"106000 days",
+			106000 * 24 * time.Hour,
+			nil,
+		},
+		{
+			"1 second and",
+			0,
+			fmt.Errorf("Could not convert '1 second and' to type 'time.Duration'"),
+		},
+		{
+			"300000000 days",
+			0,
+			fmt.Errorf("Could not convert '300000000 days' to type 'time.Duration'"),
+		},
+	}
+
+	converter := TypeConverter{}
+
+	for _, table := range tables {
+		val, err := converter.GetDuration(table.variable)
+		if table.err == nil {
+			assert.NoError(t, err)
+			assert.Equal(t, table.expected, val)
+		} else {
+			assert.Error(t, err)
+			assert.Equal(t, table.err.Error(), err.Error())
+		}
+	}
+}
+
+func TestConvertingToByteSize(t *testing.T) {
+	tables := []struct {
+		variable string
+		expected int64
+		err      error
+	}{
+		{
+			"1024",
+			1024,
+			nil,
+		},
+		{
+			"512Mi",
+			512 * 1024 * 1024,
+			nil,
+		},
+		{
+			"1.5GB",
+			1500000000,
+			nil,
+		},
+		{
+			"2 ki",
+			2048,
+			nil,
+		},
+		{
+			"12 parsecs",
+			0,
+			fmt.Errorf("Could not convert '12 parsecs' to type 'byte size'"),
+		},
+		{
+			"Mi",
+			0,
+			fmt.Errorf("Could not convert 'Mi' to type 'byte size'"),
+		},
+		{
+			"8388608Ti",
+			0,
+			fmt.Errorf("Could not convert '8388608Ti' to type 'byte size'"),
+		},
+		{
+			"8388607Ti",
+			8388607 << 40,
+			nil,
+		},
+	}
+
+	converter := TypeConverter{}
+
+	for _, table := range tables {
+		val, err := converter.GetByteSize(table.variable)
+		if table.err == nil {
+			assert.NoError(t, err)
+			assert.Equal(t, table.expected, val)
+		} else {
+			assert.Error(t, err)
+			assert.Equal(t, table.err.Error(), err.Error())
+		}
+	}
+}
+
+func TestConvertingToURL(t *testing.T) {
+	tables := []struct {
+		variable string
+		expected string
+		err      error
+	}{
+		{
+			"http://localhost:8080/v1/weather?city=Toronto",
+			"localhost:8080",
+			nil,
+		},
+		{
+			"localhost:8080",
+			"",
+			fmt.Errorf("Could not convert 'localhost:8080' to type '*url.URL'"),
+		},
+		{
+			"http://%zz",
+			"",
+			fmt.Errorf("Could not convert 'http://%%zz' to type '*url.URL'"),
+		},
+	}
+
+	converter := TypeConverter{}
+
+	for _, table := range tables {
+		val, err := converter.GetURL(table.variable)
+		if table.err == nil {
+			assert.NoError(t, err)
+			assert.Equal(t, table.expected, val.Host)
+		} else {
+			assert.Error(t, err)
+			assert.Equal(t, table.err.Error(), err.Error())
+		}
+	}
+}
+
+func TestConvertingToMap(t *testing.T) {
+	tables := []struct {
+		variable string
+		expected map[string]string
+		err      error
+	}{
+		{
+			"first=1, second = 2,empty=",
+			map[string]string{"first": "1", "second": "2", "empty": ""},
+			nil,
+		},
+		{
+			"",
+			map[string]string{},
+			nil,
+		},
+		{
+			"first=1,second",
+			map[string]string{},
+			fmt.Errorf("Could not convert 'first=1,second' to type 'map[string]string'"),
+		},
+	}
+
+	converter := TypeConverter{}
+
+	for _, table := range tables {
+		val, err := converter.GetMap(table.variable)
+		if table.err == nil {
+			assert.NoError(t, err)
+			assert.Equal(t, table.expected, val)
+		} else {
+			assert.Error(t, err)
+			assert.Equal(t, table.err.Error(), err.Error())
+		}
+	}
+}
+
+func TestConvertingToRegex(t *testing.T) {
+	converter := TypeConverter{}
+
+	regex, err := converter.GetRegex("^[a-z]+$")
+	assert.NoError(t, err)
+	assert.True(t, regex.MatchString("weather"))
+
+	regex, err = converter.GetRegex("[a-z")
+	assert.Nil(t, regex)
+	assert.Error(t, err)
+	assert.Equal(t, "Could not convert '[a-z' to type '*regexp.Regexp'", err.Error())
+}
+
+func TestConvertingToJSON(t *testing.T) {
+	converter := TypeConverter{}
+
+	var payload map[string]interface{}
+	assert.NoError(t, converter.GetJSON(`{"some":"data"}`, &payload))
+	assert.Equal(t, map[string]interface{}{"some": "data"}, payload)
+
+	err := converter.GetJSON(`{"some":`, &payload)
+	assert.Error(t, err)
+	assert.Equal(t, "Could not convert '{\"some\":' to type '*map[string]interface {}'", err.Error())
+}
@@ -0,0 +1,145 @@
+package models
+
+import (
+	"encoding/json"
+	"fmt"
+	"reflect"
+	"strings"
+)
+
+// variableValue is a single entry of step.variables as it was written in the test file. It keeps both the original YAML value (which can be a
+// scalar, list or map) and the string version of it so that the string based accessors on the Step keep working.
+type variableValue struct {
+	text  string
+	value interface{}
+}
+
+// UnmarshalYAML will read any YAML value. Scalars keep the exact text written in the test file (so '1.10' does not become '1.1') while lists and
+// maps are converted to a comma separated list and JSON respectively.
+func (variable *variableValue) UnmarshalYAML(unmarshal func(interface{}) error) error {
+	var value interface{}
+	if err := unmarshal(&value); err != nil {
+		return err
+	}
+	variable.value = NormalizeVariable(value)
+
+	var text string
+	if err := unmarshal(&text); err == nil {
+		variable.text = text
+	} else {
+		variable.text = stringifyVariable(variable.value)
+	}
+	return nil
+}
+
+// splitVariableValues splits the variables read from the test file into their string versions and their original YAML values
+func splitVariableValues(variables map[string]*variableValue) (map[string]string, map[string]interface{}) {
+	if variables == nil {
+		return nil, nil
+	}
+	texts := make(map[string]string, len(variables))
+	values := make(map[string]interface{}, len(variables))
+	for key, variable := range variables {
+		if variable == nil {
+			variable = &variableValue{}
+		}
+		texts[key] = variable.text
+		values[key] = variable.value
+	}
+	return texts, values
+}
+
+// interpolateVariables returns a copy of the variables with every '${name}' replaced by the value returned by lookup. Numbers and booleans are
+// left as they were written.
+func interpolateVariables(texts map[string]string, values map[string]interface{}, lookup func(name string) (interface{}, bool)) (map[string]string, map[string]interface{}) {
+	if texts == nil {
+		return nil, values
+	}
+	interpolatedTexts := make(map[string]string, len(texts))
+	interpolatedValues := make(map[string]interface{}, len(texts))
+	for key, text := range texts {
+		value, ok := values[key]
+		if !ok {
+			value = text
+		}
+		switch value.(type) {
+		case string, []interface{}, map[string]interface{}:
+			value = interpolateValue(value, lookup)
+			text = stringifyVariable(value)
+		}
+		interpolatedTexts[key] = text
+		interpolatedValues[key] = value
+	}
+	return interpolatedTexts, interpolatedValues
+}
+
+// NormalizeVariable converts the 'map[interface{}]interface{}' created by the YAML library (and any other Go slice or map) into
+// '[]interface{}' and 'map[string]interface{}' so the value can be converted to JSON and easily worked with
+func NormalizeVariable(value interface{}) interface{} {
+	switch typed := value.(type) {
+	case map[interface{}]interface{}:
+		normalized := make(map[string]interface{}, len(typed))
+		for key, val := range typed {
+			normalized[fmt.Sprint(key)] = NormalizeVariable(val)
+		}
+		return normalized
+	case map[string]interface{}:
+		normalized := make(map[string]interface{}, len(typed))
+		for key, val := range typed {
+			normalized[key] = NormalizeVariable(val)
+		}
+		return normalized
+	case []interface{}:
+		normalized := make([]interface{}, len(typed))
+		for index, val := range typed {
+			normalized[index] = NormalizeVariable(val)
+		}
+		return normalized
+	}
+
+	reflected := reflect.ValueOf(value)
+	switch reflected.Kind() {
+	case reflect.Slice, reflect.Array:
+		normalized := make([]interface{}, reflected.Len())
+		for index := range normalized {
+			normalized[index] = NormalizeVariable(reflected.Index(index).Interface())
+		}
+		return normalized
+	case reflect.Map:
+		normalized := make(map[string]interface{}, reflected.Len())
+		for _, key := range reflected.MapKeys() {
+			normalized[fmt.Sprint(key.Interface())] = NormalizeVariable(reflected.MapIndex(key).Interface())
+		}
+		return normalized
+	default:
+		return value
+	}
+}
+
+// stringifyVariable converts a YAML value into the string representation stored in step.variables
+func stringifyVariable(value interface{}) string {
+	switch typed := value.(type) {
+	case nil:
+		return ""
+	case string:
+		return typed
+	case []interface{}:
+		return strings.Join(stringifyList(typed), ",")
+	case map[string]interface{}:
+		bytes, err := json.Marshal(typed)
+		if err != nil {
+			return fmt.Sprint(typed)
+		}
+		return string(bytes)
+	default:
+		return fmt.Sprint(typed)
+	}
+}
+
+func stringifyList(values []interface{}) []string {
+	list := make([]string, len(values))
+	for index, value := range values {
+		list[index] = stringifyVariable(value)
+	}
+	return list
+}
@@ -0,0 +1,30 @@
+package models
+
+import (
+	"encoding/json"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"gopkg.in/yaml.v2"
+)
+
+func TestNormalizeVariable(t *testing.T) {
+	tables := []struct {
+		data     string
+		expected string
+	}{
+		{"text", `"text"`},
+		{"[1, two]", `[1,"two"]`},
+		{"{a: {1: [b, {c: true}]}}", `{"a":{"1":["b",{"c":true}]}}`},
+	}
+
+	for _, table := range tables {
+		var value interface{}
+		assert.NoError(t, yaml.Unmarshal([]byte(table.data), &value))
+		bytes, err := json.Marshal(NormalizeVariable(value))
+		assert.NoError(t, err)
+		assert.Equal(t, table.expected, string(bytes))
+	}
+	assert.Equal(t, map[string]interface{}{"a": 1}, NormalizeVariable(map[string]interface{}{"a": 1}))
+	assert.Equal(t, []interface{}{"a", "b"}, NormalizeVariable([]string{"a", "b"}))
+}
@@ -0,0 +1,34 @@
+package operations
+
+import (
+	"fmt"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+
+	model "github.com/julianGoh17/simple-e2e/framework/models"
+	"github.com/julianGoh17/simple-e2e/framework/util"
+)
+
+// newArtifactDir will create a directory under '<ARTIFACTS_DIR>' for the files of a single run of the step. The directory starts with the time
+// it was created so the artifacts sort by when they were saved and ends with the ID of the step, or its description if it has no ID.
+func newArtifactDir(step *model.Step) (string, error) {
+	name := step.ID
+	if name == "" {
+		name = step.Description
+	}
+	dir := filepath.Join(config.GetOrDefault(util.ArtifactsDirEnv), fmt.Sprintf("%s-%s", newRunID(), cleanName(name)))
+	if err := os.MkdirAll(dir, 0755); err != nil {
+		return "", fmt.Errorf("Could not create artifact directory for step '%s': %v", step.Description, err)
+	}
+	return dir, nil
+}
+
+// saveArtifact will write the contents to a file in the artifact directory, with every registered secret masked, and return the path of the file
+func saveArtifact(dir, name string, contents []byte) (string, error) {
+	path := filepath.Join(dir, name)
+	if err := ioutil.WriteFile(path, []byte(util.MaskSecrets(string(contents))), 0644); err != nil {
+		return "", fmt.Errorf("Could not save artifact '%s': %v", path, err)
+	}
+	return path, nil
+}
@@ -0,0 +1,185 @@
+package operations
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"os"
+	"os/exec"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/julianGoh17/simple-e2e/framework/models"
+)
+
+const defaultCommandTimeout = 5 * time.Minute
+
+// RunCommand will run a command on the machine running the tests and check its exit code. The exit code, stdout and stderr of the command are
+// saved as the outputs 'exitCode', 'stdout' and 'stderr', without their trailing new lines. Stdout and stderr are also saved as the artifacts
+// 'stdout.log' and 'stderr.log' under '<ARTIFACTS_DIR>', whose paths are the outputs 'stdoutFile' and 'stderrFile'.
+// Environmental Variables:
+//   - COMMAND: The command to run. Text is run by 'sh -c' while a YAML list is run as the program and its arguments without a shell
+//   - DIR: The working directory of the command, relative to the test directory (default the working directory of the tests)
+//   - ENV: A map of environment variables which are added to the environment of the tests for the command
+//   - STDIN: Text which is given to the command as its stdin
+//   - TIMEOUT: How long the command can run before it is killed (default 5m)
+//   - EXPECTED_EXIT_CODE: The exit codes the command can have, as a list or separated by commas (default 0)
+//   - SAVE_ARTIFACTS: Whether to save stdout and stderr as artifacts (default true)
+func RunCommand(step *models.Step) error {
+	traceStepEntrance(step)
+	args, name, err := getCommandArgs(step)
+	if err != nil {
+		return traceStepExit(step, err)
+	}
+	timeout, err := step.GetValueFromVariablesAsDurationOrDefault("TIMEOUT", defaultCommandTimeout)
+	if err != nil {
+		return traceStepExit(step, err)
+	}
+	expectedCodes := []int{0}
+	if _, ok := step.Variables["EXPECTED_EXIT_CODE"]; ok {
+		if expectedCodes, err = step.GetValueFromVariablesAsIntegerArray("EXPECTED_EXIT_CODE"); err != nil {
+			return traceStepExit(step, err)
+		}
+	}
+	saveArtifacts, err := step.GetValueFromVariablesAsBooleanOrDefault("SAVE_ARTIFACTS", true)
+	if err != nil {
+		return traceStepExit(step, err)
+	}
+	env, err := step.GetValueFromVariablesAsMapOrDefault("ENV", map[string]string{})
+	if err != nil {
+		return traceStepExit(step, err)
+	}
+
+	ctx, cancel := context.WithTimeout(step.GetContext(), timeout)
+	defer cancel()
+	command := exec.Command(args[0], args[1:]...)
+	setProcessGroup(command)
+	if dir := step.GetValueFromVariablesAsStringOrDefault("DIR", ""); dir != "" {
+		command.Dir = getTestDirPath(dir)
+	}
+	command.Env = os.Environ()
+	for _, key := range getSortedStringKeys(env) {
+		command.Env = append(command.Env, fmt.Sprintf("%s=%s", key, env[key]))
+	}
+	if stdin, err := step.GetValueFromVariablesAsString("STDIN"); err == nil {
+		command.Stdin = strings.NewReader(stdin)
+	}
+	var stdout, stderr bytes.Buffer
+	command.Stdout = &stdout
+	command.Stderr = &stderr
+
+	start := time.Now()
+	runErr := runCommand(ctx, command)
+	exitCode := -1
+	if command.ProcessState != nil {
+		exitCode = command.ProcessState.ExitCode()
+	}
+	logger.Info().
+		Str("command", name).
+		Int("exitCode", exitCode).
+		Dur("duration", time.Since(start)).
+		Msg("Ran command")
+
+	step.SetOutput("exitCode", strconv.Itoa(exitCode))
+	step.SetOutput("stdout", strings.TrimRight(stdout.String(), "\r\n"))
+	step.SetOutput("stderr", strings.TrimRight(stderr.String(), "\r\n"))
+	if saveArtifacts {
+		if err := saveCommandArtifacts(step, stdout.Bytes(), stderr.Bytes()); err != nil {
+			return traceStepExit(step, err)
+		}
+	}
+
+	switch {
+	case ctx.Err() == context.DeadlineExceeded:
+		return traceStepExit(step, fmt.Errorf("Command '%s' did not finish within %s", name, timeout))
+	case ctx.Err() != nil:
+		return traceStepExit(step, fmt.Errorf("Command '%s' was interrupted", name))
+	case command.ProcessState == nil:
+		return traceStepExit(step, fmt.Errorf("Could not run command '%s': %v", name, runErr))
+	}
+	for _, code := range expectedCodes {
+		if code == exitCode {
+			return traceStepExit(step, nil)
+		}
+	}
+	return traceStepExit(step, fmt.Errorf("Command '%s' exited with code %d but expected %s. Stderr: '%s'", name, exitCode,
+		joinIntegers(expectedCodes, " or "), strings.TrimRight(stderr.String(), "\r\n")))
+}
+
+// runCommand will run the command until it exits or the context is done, when the command and every process it started are killed. Killing only
+// the command is not enough as processes started by a shell keep its stdout and stderr open, so waiting for the command would wait for them.
+func runCommand(ctx context.Context, command *exec.Cmd) error {
+	if err := command.Start(); err != nil {
+		return err
+	}
+	exited := make(chan struct{})
+	defer close(exited)
+	go func() {
+		select {
+		case <-ctx.Done():
+			killProcessGroup(command)
+		case <-exited:
+		}
+	}()
+	return command.Wait()
+}
+
+// getCommandArgs returns the program and arguments of the command in the step variables, and the command as it is shown in logs and errors
+func getCommandArgs(step *models.Step) ([]string, string, error) {
+	value, err := step.GetValueFromVariables("COMMAND")
+	if err != nil {
+		return nil, "", err
+	}
+	if script, isString := value.(string); isString {
+		if strings.TrimSpace(script) == "" {
+			return nil, "", fmt.Errorf("Variable 'COMMAND' of step '%s' can not be empty", step.Description)
+		}
+		return []string{"sh", "-c", script}, script, nil
+	}
+	args, err := step.GetValueFromVariablesAsStringArray("COMMAND")
+	if err != nil {
+		return nil, "", err
+	}
+	if len(args) == 0 {
+		return nil, "", fmt.Errorf("Variable 'COMMAND' of step '%s' can not be empty", step.Description)
+	}
+	return args, strings.Join(args, " "), nil
+}
+
+// saveCommandArtifacts will save stdout and stderr of the command as artifacts and set the outputs to their paths
+func saveCommandArtifacts(step *models.Step, stdout, stderr []byte) error {
+	dir, err := newArtifactDir(step)
+	if err != nil {
+		return err
+	}
+	stdoutFile, err := saveArtifact(dir, "stdout.log", stdout)
+	if err != nil {
+		return err
+	}
+	stderrFile, err := saveArtifact(dir, "stderr.log", stderr)
+	if err != nil {
+		return err
+	}
+	step.SetOutput("stdoutFile", stdoutFile)
+	step.SetOutput("stderrFile", stderrFile)
+	return nil
+}
+
+func getSortedStringKeys(values map[string]string) []string {
+	keys := make([]string, 0, len(values))
+	for key := range values {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+	return keys
+}
+
+func joinIntegers(values []int, separator string) string {
+	text := make([]string, len(values))
+	for index, value := range values {
+		text[index] = strconv.Itoa(value)
+	}
+	return strings.Join(text, separator)
+}
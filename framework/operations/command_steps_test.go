@@ -0,0 +1,127 @@
+package operations
+
+import (
+	"fmt"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/julianGoh17/simple-e2e/framework/util"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestRunCommand(t *testing.T) {
+	dir, err := ioutil.TempDir("", "simple-e2e-command")
+	assert.NoError(t, err)
+	defer os.RemoveAll(dir)
+	assert.NoError(t, ioutil.WriteFile(filepath.Join(dir, "data.txt"), []byte("from file"), 0644))
+
+	tables := []struct {
+		variables string
+		exitCode  string
+		stdout    string
+		stderr    string
+	}{
+		{"  COMMAND: echo hello && echo world >&2", "0", "hello", "world"},
+		{"  COMMAND: [echo, 'a && b']", "0", "a && b", ""},
+		{"  COMMAND: echo $GREETING $NAME\n  ENV: {GREETING: hi, NAME: e2e}", "0", "hi e2e", ""},
+		{"  COMMAND: cat\n  STDIN: piped", "0", "piped", ""},
+		{fmt.Sprintf("  COMMAND: cat data.txt\n  DIR: %s", dir), "0", "from file", ""},
+		{"  COMMAND: exit 3\n  EXPECTED_EXIT_CODE: [0, 3]", "3", "", ""},
+		{"  COMMAND: printf 'line\\n\\n'", "0", "line", ""},
+	}
+
+	for _, table := range tables {
+		step := newStep(t, "Run command", table.variables)
+		assert.NoError(t, RunCommand(step), table.variables)
+		assert.True(t, step.HasSucceeded())
+		outputs := step.GetOutputs()
+		assert.Equal(t, table.exitCode, outputs["exitCode"], table.variables)
+		assert.Equal(t, table.stdout, outputs["stdout"], table.variables)
+		assert.Equal(t, table.stderr, outputs["stderr"], table.variables)
+	}
+}
+
+func TestRunCommandFails(t *testing.T) {
+	tables := []struct {
+		variables string
+		exitCode  string
+		err       string
+	}{
+		{"  COMMAND: echo broken >&2; exit 2", "2", "Command 'echo broken >&2; exit 2' exited with code 2 but expected 0. Stderr: 'broken'"},
+		{"  COMMAND: exit 0\n  EXPECTED_EXIT_CODE: 1,2", "0", "Command 'exit 0' exited with code 0 but expected 1 or 2"},
+		{"  COMMAND: [sleep, '5']\n  TIMEOUT: 50ms", "-1", "Command 'sleep 5' did not finish within 50ms"},
+		{"  COMMAND: [simple-e2e-missing-program]", "-1", "Could not run command 'simple-e2e-missing-program'"},
+		{"  COMMAND: ' '", "", "Variable 'COMMAND' of step 'Run command' can not be empty"},
+		{"  COMMAND: []", "", "Variable 'COMMAND' of step 'Run command' can not be empty"},
+		{"  STDIN: text", "", "Could not find variable 'COMMAND' in step.variables"},
+		{"  COMMAND: exit 0\n  TIMEOUT: soon", "", "soon"},
+		{"  COMMAND: exit 0\n  EXPECTED_EXIT_CODE: zero", "", "zero"},
+	}
+
+	for _, table := range tables {
+		step := newStep(t, "Run command", table.variables)
+		err := RunCommand(step)
+		if assert.Error(t, err, table.variables) {
+			assert.Contains(t, err.Error(), table.err)
+		}
+		assert.False(t, step.HasSucceeded())
+		assert.Equal(t, table.exitCode, step.GetOutputs()["exitCode"], table.variables)
+	}
+}
+
+func TestRunCommandKillsProcessesStartedByShellOnTimeout(t *testing.T) {
+	step := newStep(t, "Run command", "  COMMAND: sleep 3; echo done\n  TIMEOUT: 100ms")
+	start := time.Now()
+	err := RunCommand(step)
+	if assert.Error(t, err) {
+		assert.Equal(t, "Command 'sleep 3; echo done' did not finish within 100ms", err.Error())
+	}
+	assert.Less(t, int64(time.Since(start)), int64(2*time.Second))
+	assert.Equal(t, "", step.GetOutputs()["stdout"])
+}
+
+func TestRunCommandSavesArtifacts(t *testing.T) {
+	dir, err := ioutil.TempDir("", "simple-e2e-artifacts")
+	assert.NoError(t, err)
+	defer os.RemoveAll(dir)
+	previous := os.Getenv(util.ArtifactsDirEnv)
+	os.Setenv(util.ArtifactsDirEnv, dir)
+	defer os.Setenv(util.ArtifactsDirEnv, previous)
+
+	step := newStep(t, "Run command", "  COMMAND: echo out; echo err >&2; exit 1")
+	step.ID = "my script"
+	assert.Error(t, RunCommand(step))
+	outputs := step.GetOutputs()
+	assert.True(t, strings.HasPrefix(outputs["stdoutFile"], dir))
+	assert.True(t, strings.HasSuffix(filepath.Dir(outputs["stdoutFile"]), "-my-script"))
+	stdout, err := ioutil.ReadFile(outputs["stdoutFile"])
+	assert.NoError(t, err)
+	assert.Equal(t, "out\n", string(stdout))
+	stderr, err := ioutil.ReadFile(outputs["stderrFile"])
+	assert.NoError(t, err)
+	assert.Equal(t, "err\n", string(stderr))
+
+	step = newStep(t, "Run command", "  COMMAND: echo out\n  SAVE_ARTIFACTS: false")
+	assert.NoError(t, RunCommand(step))
+	assert.NotContains(t, step.GetOutputs(), "stdoutFile")
+
+	util.RegisterSecret("artifact-password")
+	step = newStep(t, "Run command", "  COMMAND: echo logging in with artifact-password")
+	assert.NoError(t, RunCommand(step))
+	stdout, err = ioutil.ReadFile(step.GetOutputs()["stdoutFile"])
+	assert.NoError(t, err)
+	assert.Equal(t, "logging in with ****\n", string(stdout))
+
+	file := filepath.Join(dir, "file")
+	assert.NoError(t, ioutil.WriteFile(file, []byte{}, 0644))
+	os.Setenv(util.ArtifactsDirEnv, file)
+	step = newStep(t, "Run command", "  COMMAND: echo out")
+	err = RunCommand(step)
+	if assert.Error(t, err) {
+		assert.Contains(t, err.Error(), "Could not create artifact directory for step 'Run command'")
+	}
+}
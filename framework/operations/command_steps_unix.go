@@ -0,0 +1,21 @@
+//go:build !windows
+// +build !windows
+
+package operations
+
+import (
+	"os/exec"
+	"syscall"
+)
+
+// setProcessGroup will start the command in its own process group so that it can be killed along with every process which it starts
+func setProcessGroup(command *exec.Cmd) {
+	command.SysProcAttr = &syscall.SysProcAttr{Setpgid: true}
+}
+
+// killProcessGroup will kill the command and every process in its process group
+func killProcessGroup(command *exec.Cmd) {
+	if err := syscall.Kill(-command.Process.Pid, syscall.SIGKILL); err != nil {
+		command.Process.Kill()
+	}
+}
@@ -0,0 +1,13 @@
+package operations
+
+import (
+	"os/exec"
+)
+
+// setProcessGroup does nothing on Windows, where only the command itself is killed
+func setProcessGroup(command *exec.Cmd) {}
+
+// killProcessGroup will kill the command
+func killProcessGroup(command *exec.Cmd) {
+	command.Process.Kill()
+}
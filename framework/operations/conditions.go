@@ -0,0 +1,380 @@
+package operations
+
+import (
+	"fmt"
+	"strings"
+	"unicode"
+
+	model "github.com/julianGoh17/simple-e2e/framework/models"
+)
+
+// statusFunctions are the functions which can be used in an 'if' expression. 'failed' is whether an earlier stage (for a stage) or an earlier step
+// in the same stage (for a step) has failed.
+var statusFunctions = map[string]func(failed bool) bool{
+	"success": func(failed bool) bool { return !failed },
+	"failure": func(failed bool) bool { return failed },
+	"always":  func(failed bool) bool { return true },
+}
+
+// conditionContext is what an 'if' expression is evaluated against
+type conditionContext struct {
+	failed bool
+	lookup func(name string) (interface{}, bool)
+}
+
+type conditionNode interface {
+	evaluate(context *conditionContext) interface{}
+}
+
+type literalNode struct {
+	value interface{}
+}
+
+func (node *literalNode) evaluate(context *conditionContext) interface{} {
+	return node.value
+}
+
+type referenceNode struct {
+	name string
+}
+
+func (node *referenceNode) evaluate(context *conditionContext) interface{} {
+	value, ok := context.lookup(node.name)
+	if !ok || value == nil {
+		return ""
+	}
+	return fmt.Sprint(value)
+}
+
+type functionNode struct {
+	name string
+}
+
+func (node *functionNode) evaluate(context *conditionContext) interface{} {
+	return statusFunctions[node.name](context.failed)
+}
+
+type notNode struct {
+	operand conditionNode
+}
+
+func (node *notNode) evaluate(context *conditionContext) interface{} {
+	return !isTruthy(node.operand.evaluate(context))
+}
+
+type binaryNode struct {
+	operator    string
+	left, right conditionNode
+}
+
+func (node *binaryNode) evaluate(context *conditionContext) interface{} {
+	switch node.operator {
+	case "&&":
+		return isTruthy(node.left.evaluate(context)) && isTruthy(node.right.evaluate(context))
+	case "||":
+		return isTruthy(node.left.evaluate(context)) || isTruthy(node.right.evaluate(context))
+	case "==":
+		return fmt.Sprint(node.left.evaluate(context)) == fmt.Sprint(node.right.evaluate(context))
+	default:
+		return fmt.Sprint(node.left.evaluate(context)) != fmt.Sprint(node.right.evaluate(context))
+	}
+}
+
+// isTruthy returns false for false, an empty string and the string 'false'. Everything else is true.
+func isTruthy(value interface{}) bool {
+	switch typed := value.(type) {
+	case bool:
+		return typed
+	case string:
+		return typed != "" && typed != "false"
+	default:
+		return value != nil
+	}
+}
+
+// condition is a parsed 'if' expression
+type condition struct {
+	root conditionNode
+	// usesStatus is whether the expression calls a status function. Expressions which do not are only true if nothing has failed yet.
+	usesStatus bool
+}
+
+// evaluateCondition will evaluate the 'if' expression of a stage or step. An empty expression is true if nothing has failed yet, or always if
+// alwaysRuns is set.
+func evaluateCondition(expression string, failed, alwaysRuns bool, lookup func(name string) (interface{}, bool)) (bool, error) {
+	defaultStatus := alwaysRuns || !failed
+	if strings.TrimSpace(expression) == "" {
+		return defaultStatus, nil
+	}
+	parsed, err := parseCondition(expression)
+	if err != nil {
+		return false, err
+	}
+	result := isTruthy(parsed.root.evaluate(&conditionContext{failed: failed, lookup: lookup}))
+	if !parsed.usesStatus {
+		result = result && defaultStatus
+	}
+	return result, nil
+}
+
+// validateConditions will parse every 'if' expression in the procedure so that invalid expressions are found before the test is run
+func validateConditions(procedure *model.Procedure) error {
+	for _, stage := range procedure.Stages {
+		if _, err := parseCondition(stage.If); err != nil {
+			return fmt.Errorf("Invalid 'if' expression in stage '%s': %v", stage.Name, err)
+		}
+		for _, step := range stage.Steps {
+			if _, err := parseCondition(step.If); err != nil {
+				return fmt.Errorf("Invalid 'if' expression in step '%s': %v", step.Description, err)
+			}
+		}
+	}
+	return nil
+}
+
+// combineConditions returns an expression which is only true if all of the given expressions are true
+func combineConditions(expressions ...string) string {
+	combined := []string{}
+	for _, expression := range expressions {
+		if strings.TrimSpace(expression) != "" {
+			combined = append(combined, expression)
+		}
+	}
+	if len(combined) < 2 {
+		return strings.Join(combined, "")
+	}
+	return "(" + strings.Join(combined, ") && (") + ")"
+}
+
+type conditionToken struct {
+	kind     string
+	value    string
+	position int
+}
+
+const (
+	operatorToken   = "operator"
+	stringToken     = "string"
+	identifierToken = "identifier"
+	endToken        = "end"
+)
+
+// conditionParser is a recursive descent parser for 'if' expressions. From lowest to highest precedence the grammar is:
+//
+//	or         := and ('||' and)*
+//	and        := unary ('&&' unary)*
+//	unary      := '!' unary | comparison
+//	comparison := primary (('==' | '!=') primary)?
+//	primary    := '(' or ')' | string | number | 'true' | 'false' | function '()' | reference
+type conditionParser struct {
+	expression string
+	tokens     []conditionToken
+	position   int
+	usesStatus bool
+}
+
+func parseCondition(expression string) (*condition, error) {
+	if strings.TrimSpace(expression) == "" {
+		return &condition{root: &literalNode{value: true}}, nil
+	}
+	tokens, err := tokenizeCondition(expression)
+	if err != nil {
+		return nil, err
+	}
+	parser := &conditionParser{expression: expression, tokens: tokens}
+	root, err := parser.parseOr()
+	if err != nil {
+		return nil, err
+	}
+	if token := parser.peek(); token.kind != endToken {
+		return nil, fmt.Errorf("Unexpected '%s' at position %d", token.value, token.position)
+	}
+	return &condition{root: root, usesStatus: parser.usesStatus}, nil
+}
+
+func (parser *conditionParser) peek() conditionToken {
+	return parser.tokens[parser.position]
+}
+
+func (parser *conditionParser) next() conditionToken {
+	token := parser.tokens[parser.position]
+	if token.kind != endToken {
+		parser.position++
+	}
+	return token
+}
+
+func (parser *conditionParser) isOperator(value string) bool {
+	token := parser.peek()
+	return token.kind == operatorToken && token.value == value
+}
+
+func (parser *conditionParser) parseOr() (conditionNode, error) {
+	left, err := parser.parseAnd()
+	if err != nil {
+		return nil, err
+	}
+	for parser.isOperator("||") {
+		parser.next()
+		right, err := parser.parseAnd()
+		if err != nil {
+			return nil, err
+		}
+		left = &binaryNode{operator: "||", left: left, right: right}
+	}
+	return left, nil
+}
+
+func (parser *conditionParser) parseAnd() (conditionNode, error) {
+	left, err := parser.parseUnary()
+	if err != nil {
+		return nil, err
+	}
+	for parser.isOperator("&&") {
+		parser.next()
+		right, err := parser.parseUnary()
+		if err != nil {
+			return nil, err
+		}
+		left = &binaryNode{operator: "&&", left: left, right: right}
+	}
+	return left, nil
+}
+
+func (parser *conditionParser) parseUnary() (conditionNode, error) {
+	if parser.isOperator("!") {
+		parser.next()
+		operand, err := parser.parseUnary()
+		if err != nil {
+			return nil, err
+		}
+		return &notNode{operand: operand}, nil
+	}
+	return parser.parseComparison()
+}
+
+func (parser *conditionParser) parseComparison() (conditionNode, error) {
+	left, err := parser.parsePrimary()
+	if err != nil {
+		return nil, err
+	}
+	if parser.isOperator("==") || parser.isOperator("!=") {
+		operator := parser.next().value
+		right, err := parser.parsePrimary()
+		if err != nil {
+			return nil, err
+		}
+		return &binaryNode{operator: operator, left: left, right: right}, nil
+	}
+	return left, nil
+}
+
+func (parser *conditionParser) parsePrimary() (conditionNode, error) {
+	token := parser.next()
+	switch token.kind {
+	case stringToken:
+		return &literalNode{value: token.value}, nil
+	case identifierToken:
+		switch token.value {
+		case "true":
+			return &literalNode{value: true}, nil
+		case "false":
+			return &literalNode{value: false}, nil
+		}
+		if !parser.isOperator("(") {
+			return &referenceNode{name: token.value}, nil
+		}
+		if _, ok := statusFunctions[token.value]; !ok {
+			return nil, fmt.Errorf("Unknown function '%s' at position %d", token.value, token.position)
+		}
+		parser.next()
+		if !parser.isOperator(")") {
+			return nil, fmt.Errorf("Function '%s' at position %d does not take any arguments", token.value, token.position)
+		}
+		parser.next()
+		parser.usesStatus = true
+		return &functionNode{name: token.value}, nil
+	case operatorToken:
+		if token.value == "(" {
+			node, err := parser.parseOr()
+			if err != nil {
+				return nil, err
+			}
+			if !parser.isOperator(")") {
+				return nil, fmt.Errorf("Expected ')' at position %d", parser.peek().position)
+			}
+			parser.next()
+			return node, nil
+		}
+		return nil, fmt.Errorf("Unexpected '%s' at position %d", token.value, token.position)
+	default:
+		return nil, fmt.Errorf("Unexpected end of expression '%s'", parser.expression)
+	}
+}
+
+func tokenizeCondition(expression string) ([]conditionToken, error) {
+	tokens := []conditionToken{}
+	runes := []rune(expression)
+	for index := 0; index < len(runes); {
+		char := runes[index]
+		switch {
+		case unicode.IsSpace(char):
+			index++
+		case char == '\'' || char == '"':
+			end := index + 1
+			for end < len(runes) && runes[end] != char {
+				end++
+			}
+			if end == len(runes) {
+				return nil, fmt.Errorf("Unterminated string at position %d", index)
+			}
+			tokens = append(tokens, conditionToken{kind: stringToken, value: string(runes[index+1 : end]), position: index})
+			index = end + 1
+		case char == '$' && index+1 < len(runes) && runes[index+1] == '{':
+			end := index + 2
+			for end < len(runes) && runes[end] != '}' {
+				end++
+			}
+			if end == len(runes) {
+				return nil, fmt.Errorf("Unterminated '${' at position %d", index)
+			}
+			tokens = append(tokens, conditionToken{kind: identifierToken, value: strings.TrimSpace(string(runes[index+2 : end])), position: index})
+			index = end + 1
+		case isIdentifierRune(char):
+			end := index
+			for end < len(runes) && isIdentifierRune(runes[end]) {
+				end++
+			}
+			kind := identifierToken
+			if unicode.IsDigit(char) {
+				kind = stringToken
+			}
+			tokens = append(tokens, conditionToken{kind: kind, value: string(runes[index:end]), position: index})
+			index = end
+		case char == '(' || char == ')':
+			tokens = append(tokens, conditionToken{kind: operatorToken, value: string(char), position: index})
+			index++
+		default:
+			operator := ""
+			if index+1 < len(runes) {
+				operator = string(runes[index : index+2])
+			}
+			switch operator {
+			case "==", "!=", "&&", "||":
+				tokens = append(tokens, conditionToken{kind: operatorToken, value: operator, position: index})
+				index += 2
+			default:
+				if char != '!' {
+					return nil, fmt.Errorf("Unexpected '%c' at position %d", char, index)
+				}
+				tokens = append(tokens, conditionToken{kind: operatorToken, value: "!", position: index})
+				index++
+			}
+		}
+	}
+	return append(tokens, conditionToken{kind: endToken, position: len(runes)}), nil
+}
+
+func isIdentifierRune(char rune) bool {
+	return unicode.IsLetter(char) || unicode.IsDigit(char) || char == '_' || char == '.' || char == '-'
+}
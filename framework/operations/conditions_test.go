@@ -0,0 +1,110 @@
+package operations
+
+import (
+	"testing"
+
+	model "github.com/julianGoh17/simple-e2e/framework/models"
+	"github.com/stretchr/testify/assert"
+)
+
+func conditionLookup(name string) (interface{}, bool) {
+	value, ok := map[string]interface{}{
+		"DEBUG":                   "true",
+		"EMPTY":                   "",
+		"DATABASE":                "postgres",
+		"steps.build.outputs.tag": "1.0",
+		"steps.build.status":      "Passed",
+		"RETRIES":                 3,
+	}[name]
+	return value, ok
+}
+
+func TestEvaluateCondition(t *testing.T) {
+	tables := []struct {
+		expression string
+		failed     bool
+		alwaysRuns bool
+		expected   bool
+	}{
+		{"", false, false, true},
+		{"", true, false, false},
+		{"", true, true, true},
+		{"DEBUG", false, false, true},
+		{"${DEBUG}", false, false, true},
+		{"EMPTY", false, false, false},
+		{"MISSING", false, false, false},
+		{"!MISSING", false, false, true},
+		{"DATABASE == 'postgres'", false, false, true},
+		{"DATABASE == \"postgres\"", false, false, true},
+		{"DATABASE != 'postgres'", false, false, false},
+		{"RETRIES == 3", false, false, true},
+		{"steps.build.outputs.tag == '1.0' && steps.build.status == 'Passed'", false, false, true},
+		{"DATABASE == 'mysql' || DEBUG", false, false, true},
+		{"!(DATABASE == 'mysql' || DEBUG)", false, false, false},
+		{"DEBUG == true", false, false, true},
+		{"DEBUG", true, false, false},
+		{"DEBUG", true, true, true},
+		{"success()", false, false, true},
+		{"success()", true, true, false},
+		{"failure()", false, false, false},
+		{"failure()", true, false, true},
+		{"always()", true, false, true},
+		{"always() && DEBUG", true, false, true},
+		{"failure() && DATABASE == 'mysql'", true, false, false},
+	}
+
+	for _, table := range tables {
+		result, err := evaluateCondition(table.expression, table.failed, table.alwaysRuns, conditionLookup)
+		assert.NoError(t, err, table.expression)
+		assert.Equal(t, table.expected, result, table.expression)
+	}
+}
+
+func TestParseConditionFails(t *testing.T) {
+	tables := []struct {
+		expression string
+		err        string
+	}{
+		{"DEBUG ==", "Unexpected end of expression 'DEBUG =='"},
+		{"DEBUG DATABASE", "Unexpected 'DATABASE' at position 6"},
+		{"(DEBUG", "Expected ')' at position 6"},
+		{"'DEBUG", "Unterminated string at position 0"},
+		{"${DEBUG", "Unterminated '${' at position 0"},
+		{"DEBUG = 'true'", "Unexpected '=' at position 6"},
+		{"cancel()", "Unknown function 'cancel' at position 0"},
+		{"success(DEBUG)", "Function 'success' at position 0 does not take any arguments"},
+		{"&& DEBUG", "Unexpected '&&' at position 0"},
+	}
+
+	for _, table := range tables {
+		_, err := parseCondition(table.expression)
+		if assert.Error(t, err, table.expression) {
+			assert.Equal(t, table.err, err.Error())
+		}
+	}
+}
+
+func TestValidateConditions(t *testing.T) {
+	procedure := &model.Procedure{
+		Stages: []model.Stage{
+			{Name: "stage", If: "failure()", Steps: []model.Step{{Description: "step", If: "DEBUG"}}},
+		},
+	}
+	assert.NoError(t, validateConditions(procedure))
+
+	procedure.Stages[0].Steps[0].If = "DEBUG =="
+	err := validateConditions(procedure)
+	assert.Error(t, err)
+	assert.Equal(t, "Invalid 'if' expression in step 'step': Unexpected end of expression 'DEBUG =='", err.Error())
+
+	procedure.Stages[0].If = "failure("
+	err = validateConditions(procedure)
+	assert.Error(t, err)
+	assert.Equal(t, "Invalid 'if' expression in stage 'stage': Function 'failure' at position 0 does not take any arguments", err.Error())
+}
+
+func TestCombineConditions(t *testing.T) {
+	assert.Equal(t, "", combineConditions("", " "))
+	assert.Equal(t, "DEBUG", combineConditions("", "DEBUG"))
+	assert.Equal(t, "(DEBUG) && (failure())", combineConditions("DEBUG", "failure()"))
+}
@@ -4,8 +4,11 @@ import (
 	"fmt"
 	"io/ioutil"
 	"strings"
+	"sync"
+	"time"
 
 	"github.com/julianGoh17/simple-e2e/framework/docker"
+	"github.com/julianGoh17/simple-e2e/framework/mock"
 	model "github.com/julianGoh17/simple-e2e/framework/models"
 	"github.com/julianGoh17/simple-e2e/framework/util"
 	"gopkg.in/yaml.v2"
@@ -22,6 +25,18 @@ type Controller struct {
 	stepManager *StepManager
 	procedure   *model.Procedure
 	docker      *docker.Handler
+	mocks       *mock.Manager
+	tags        *tagFilter
+	overrides   map[string]VariableOverride
+	secrets     map[string]string
+	state       *RunState
+	fromStage   string
+	testPath    string
+	interrupt   *Interrupt
+	results     []*model.TestResult
+	resultsLock sync.Mutex
+	// updateSnapshots is whether the snapshot steps of the test rewrite their snapshots instead of comparing with them
+	updateSnapshots bool
 }
 
 // NewController is a constructor function which returns a pointer to the variable to work with
@@ -33,6 +48,7 @@ func NewController() (*Controller, error) {
 	return &Controller{
 		stepManager: NewStepManager(),
 		docker:      docker,
+		mocks:       mock.NewManager(),
 	}, nil
 }
 
@@ -45,6 +61,59 @@ func (controller *Controller) AddTestStep(description string, function func(*mod
 	return controller.stepManager.AddStepToManager(description, function)
 }
 
+// SetTagFilter will only run the stages whose tags (including the tags of their test) match the include expression and do not match the exclude
+// expression. Tests without any matching stages are skipped. An empty expression does not filter anything.
+func (controller *Controller) SetTagFilter(include, exclude string) error {
+	logger.Trace().
+		Str("tags", include).
+		Str("excludeTags", exclude).
+		Msg("Setting tag filter of controller")
+	filter, err := newTagFilter(include, exclude)
+	if err != nil {
+		return err
+	}
+	controller.tags = filter
+	return nil
+}
+
+// SetVariableOverrides will set variables given from outside of the test file, which take precedence over the step variables, matrix values and
+// global variables of the test with the same name
+func (controller *Controller) SetVariableOverrides(overrides map[string]VariableOverride) {
+	controller.overrides = overrides
+}
+
+// SetRunState will save the state of the test to the run state after every stage which passes. If the run state was loaded from an earlier run
+// then the stages which it completed are skipped and the containers, step statuses and step outputs it saved are restored.
+func (controller *Controller) SetRunState(state *RunState) {
+	controller.state = state
+}
+
+// SetFromStage will skip every stage before the named stage and run the named stage and every stage after it, even if the run being resumed
+// completed them. An empty name runs every stage.
+func (controller *Controller) SetFromStage(stage string) {
+	controller.fromStage = stage
+}
+
+// SetInterrupt will stop the test when the interrupt is triggered, cancelling the step which is running and then only running the stages and
+// steps which run on failure
+func (controller *Controller) SetInterrupt(interrupt *Interrupt) {
+	controller.interrupt = interrupt
+}
+
+// SetUpdateSnapshots will make the snapshot steps of the test rewrite their snapshots with the values they are given instead of comparing with them
+func (controller *Controller) SetUpdateSnapshots(update bool) {
+	controller.updateSnapshots = update
+}
+
+// SetDockerNamespace will prefix the names of the containers created by the test with the namespace so that it does not clash with other tests
+// running at the same time
+func (controller *Controller) SetDockerNamespace(namespace string) {
+	logger.Trace().
+		Str("namespace", namespace).
+		Msg("Setting Docker namespace of controller")
+	controller.docker.SetNamespace(namespace)
+}
+
 // SetProcedure takes the read byte data from the test file and converts it to the Procedure object
 func (controller *Controller) SetProcedure(procedureData []byte) error {
 	logger.Trace().
@@ -64,10 +133,30 @@ func (controller *Controller) SetProcedure(procedureData []byte) error {
 			Msg("Test did not contain any stages")
 		return err
 	}
+	if err := resolveIncludes(procedure); err != nil {
+		return err
+	}
+	if err := expandMacros(procedure); err != nil {
+		return err
+	}
+	if err := validateConditions(procedure); err != nil {
+		logger.Error().
+			Err(err).
+			Msg("Test file has an invalid 'if' expression")
+		return err
+	}
+	if err := validateLoops(procedure); err != nil {
+		logger.Error().
+			Err(err).
+			Msg("Test file has an invalid loop")
+		return err
+	}
 	// Need to pass in snapshot manager/docker/etc into each step so they access same instance
 	for stage := range procedure.Stages {
 		for step := range procedure.Stages[stage].Steps {
 			procedure.Stages[stage].Steps[step].Docker = controller.docker
+			procedure.Stages[stage].Steps[step].Mocks = controller.mocks
+			procedure.Stages[stage].Steps[step].UpdateSnapshots = controller.updateSnapshots
 		}
 	}
 	controller.procedure = procedure
@@ -88,6 +177,7 @@ func (controller *Controller) RunTest(testPath string, stages ...string) error {
 		return fmt.Errorf("unable to read file: %v", err)
 	}
 
+	controller.testPath = testPath
 	return controller.runTest(body, stages...)
 }
 
@@ -98,41 +188,191 @@ func (controller *Controller) runTest(test []byte, stages ...string) error {
 	if err := controller.SetProcedure(test); err != nil {
 		return err
 	}
+	controller.results = []*model.TestResult{}
+	logVariableOverrides(controller.procedure, controller.overrides)
 
 	set := make(map[string]bool)
 	for _, value := range stages {
 		set[value] = true
 	}
 
+	if !controller.hasSelectedStages(set) {
+		logger.Info().
+			Str("test", controller.procedure.Name).
+			Strs("tags", controller.procedure.Tags).
+			Msg("Skipping test as none of its stages match the tags")
+		controller.addResult(&model.TestResult{
+			Name:        controller.procedure.Name,
+			Combination: map[string]string{},
+			Status:      model.Skipped,
+			Stages:      []*model.StageResult{},
+		})
+		return nil
+	}
+
+	secrets, err := resolveSecrets(controller.procedure, controller.overrides)
+	if err != nil {
+		return err
+	}
+	controller.secrets = secrets
+	if err := controller.checkFromStage(); err != nil {
+		return err
+	}
+	defer controller.stopMocks()
+
+	if controller.procedure.Matrix == nil {
+		return controller.runCombination(map[string]string{}, set)
+	}
+
+	combinations := controller.procedure.Matrix.Combinations()
+	if len(combinations) == 0 {
+		err := fmt.Errorf("Matrix of test '%s' does not have any combinations", controller.procedure.Name)
+		logger.Error().
+			Err(err).
+			Msg("Test has nothing to run")
+		return err
+	}
+
+	parallel := controller.procedure.Matrix.Parallel
+	if parallel < 1 {
+		parallel = 1
+	}
+	logger.Info().
+		Int("combinations", len(combinations)).
+		Int("parallel", parallel).
+		Msg("Running test for each matrix combination")
+
+	errs := make([]error, len(combinations))
+	semaphore := make(chan struct{}, parallel)
+	var wg sync.WaitGroup
+	for index, combination := range combinations {
+		wg.Add(1)
+		semaphore <- struct{}{}
+		go func(index int, combination map[string]string) {
+			defer wg.Done()
+			errs[index] = controller.runCombination(combination, set)
+			<-semaphore
+		}(index, combination)
+	}
+	wg.Wait()
+
+	failed := 0
+	for _, err := range errs {
+		if err != nil {
+			failed++
+		}
+	}
+	if failed > 0 {
+		return fmt.Errorf("Test failed for %d of %d matrix combinations", failed, len(combinations))
+	}
+	return nil
+}
+
+// runCombination will run through the stages of the procedure once with the values of the matrix combination added to the global variables
+func (controller *Controller) runCombination(combination map[string]string, set map[string]bool) error {
+	run := newTestRun(controller.procedure, controller.secrets, combination, controller.overrides)
+	if controller.docker != nil && len(combination) > 0 {
+		// combinations running at the same time create their containers with the same names, so each has its own namespace
+		run.docker = controller.docker.WithNamespace(getCombinationNamespace(combination))
+	}
+	if controller.state != nil {
+		run.state = controller.state.getTest(controller.testPath, combination)
+		run.restore(run.state)
+		if handler := controller.getDocker(run); handler != nil {
+			handler.RestoreContainers(run.state.Containers)
+		}
+	}
+	controller.addResult(run.result)
+	logger.Info().
+		Str("test", run.result.Name).
+		Str("combination", model.CombinationToString(combination)).
+		Msg("Beginning test run")
+
+	if err := run.createWorkspace(); err != nil {
+		run.result.SetErrored(err)
+		return err
+	}
+	start := time.Now()
+	err := controller.runStages(run, set)
+	run.result.Duration = time.Since(start)
+	run.removeWorkspace(err)
+	run.result.SetErrored(err)
+	controller.saveState()
+	return err
+}
+
+func (controller *Controller) runStages(run *testRun, set map[string]bool) error {
+	if controller.interrupt.IsInterrupted() {
+		logger.Warn().
+			Str("test", run.result.Name).
+			Msg("Skipping test as the run was interrupted before it started")
+		for index := range controller.procedure.Stages {
+			run.skipStage(&controller.procedure.Stages[index])
+		}
+		return ErrInterrupted
+	}
+
 	testPassed := true
 	failedStage := ""
-	for _, stage := range controller.procedure.Stages {
-		if testPassed {
-			logger.Debug().
+	skippedByInterrupt := false
+	for index, stage := range controller.procedure.Stages {
+		if !controller.isStageSelected(&stage, set) {
+			run.skipStage(&stage)
+			continue
+		}
+		if controller.isStageCompleted(run, index) {
+			logger.Info().
 				Str("stage", stage.Name).
-				Bool("failed", false).
-				Msg("Test has not failed, continuing to run stage.")
-			if len(set) == 0 || set[stage.Name] {
-				if err := controller.runStage(&stage); err != nil {
-					testPassed = false
+				Str("fromStage", controller.fromStage).
+				Msg("Skipping stage as it was completed by the run being resumed")
+			run.skipStage(&stage)
+			continue
+		}
+		interrupted := controller.interrupt.IsInterrupted()
+		shouldRun, err := evaluateCondition(stage.If, !testPassed || interrupted, stage.AlwaysRuns, run.lookup)
+		if err != nil {
+			return err
+		}
+		logger.Debug().
+			Str("stage", stage.Name).
+			Bool("failed", !testPassed).
+			Bool("interrupted", interrupted).
+			Bool("alwaysRun", stage.AlwaysRuns).
+			Str("if", stage.If).
+			Bool("willRun", shouldRun).
+			Msg("Checked whether stage should run")
+		if !shouldRun {
+			if interrupted && !skippedByInterrupt {
+				skippedByInterrupt = true
+				if failedStage == "" {
 					failedStage = stage.Name
-					continue
 				}
 			}
-		} else {
-			logger.Debug().
-				Str("stage", stage.Name).
-				Bool("failed", false).
-				Bool("alwaysRun", stage.AlwaysRuns).
-				Msg("Test has failed, continuing to run stages with 'alwaysRun' is true.")
-			if (len(set) == 0 || set[stage.Name]) && stage.AlwaysRuns {
-				if err := controller.runStage(&stage); err != nil {
-					return err
+			run.skipStage(&stage)
+			continue
+		}
+		if err := controller.runStage(run, &stage); err != nil {
+			if !testPassed {
+				if controller.interrupt.IsInterrupted() {
+					return fmt.Errorf("%w at stage: %s: %v", ErrInterrupted, failedStage, err)
 				}
+				return err
 			}
+			testPassed = false
+			if failedStage == "" {
+				failedStage = stage.Name
+			}
+			continue
+		}
+		// a stage which passes after the test failed or was interrupted is teardown, so it must run again when the test is resumed
+		if testPassed && !controller.interrupt.IsInterrupted() {
+			controller.saveStage(run, stage.Name)
 		}
 	}
 
+	if controller.interrupt.IsInterrupted() && (!testPassed || skippedByInterrupt) {
+		return fmt.Errorf("%w at stage: %s", ErrInterrupted, failedStage)
+	}
 	if !testPassed {
 		return fmt.Errorf("Test failed at stage: %s", failedStage)
 	}
@@ -140,56 +380,228 @@ func (controller *Controller) runTest(test []byte, stages ...string) error {
 	return nil
 }
 
-func (controller *Controller) runStage(stagePointer *model.Stage) error {
+// checkFromStage returns an error if the stage to run from is not a stage of the test
+func (controller *Controller) checkFromStage() error {
+	if controller.fromStage != "" && controller.getStageIndex(controller.fromStage) < 0 {
+		return fmt.Errorf("Could not find stage '%s' to run from in test '%s'", controller.fromStage, controller.procedure.Name)
+	}
+	return nil
+}
+
+// isStageCompleted returns whether the stage comes before the stage to run from, or was completed by the run being resumed when there is no
+// stage to run from
+func (controller *Controller) isStageCompleted(run *testRun, index int) bool {
+	if controller.fromStage != "" {
+		return index < controller.getStageIndex(controller.fromStage)
+	}
+	return run.state != nil && containsString(run.state.CompletedStages, controller.procedure.Stages[index].Name)
+}
+
+// saveStage will save that the stage has passed to the run state
+func (controller *Controller) saveStage(run *testRun, stage string) {
+	if controller.state == nil || run.state == nil {
+		return
+	}
+	containers := []*docker.ContainerInfo{}
+	if handler := controller.getDocker(run); handler != nil {
+		containers = handler.GetCreatedContainers()
+	}
+	controller.state.completeStage(run.state, stage, run, containers)
+	controller.saveState()
+}
+
+// getDocker returns the Docker handler which creates the containers of the run's steps
+func (controller *Controller) getDocker(run *testRun) *docker.Handler {
+	if run.docker != nil {
+		return run.docker
+	}
+	return controller.docker
+}
+
+// saveState will write the run state to its file. Failing to save the state does not fail the test.
+func (controller *Controller) saveState() {
+	if controller.state == nil {
+		return
+	}
+	if err := controller.state.save(); err != nil {
+		logger.Warn().
+			Err(err).
+			Str("runID", controller.state.ID).
+			Msg("Could not save state of run")
+	}
+}
+
+// stopMocks will shut down the mock servers started by the steps of the test, logging a warning if any can not be stopped
+func (controller *Controller) stopMocks() {
+	if err := controller.mocks.StopAll(); err != nil {
+		logger.Warn().
+			Err(err).
+			Msg("Could not stop mock servers")
+	}
+}
+
+func (controller *Controller) getStageIndex(name string) int {
+	for index, stage := range controller.procedure.Stages {
+		if stage.Name == name {
+			return index
+		}
+	}
+	return -1
+}
+
+// hasSelectedStages returns whether any stage of the procedure will be run. Only the tag filter can cause a test to be skipped.
+func (controller *Controller) hasSelectedStages(set map[string]bool) bool {
+	if controller.tags == nil {
+		return true
+	}
+	for index := range controller.procedure.Stages {
+		if controller.isStageSelected(&controller.procedure.Stages[index], set) {
+			return true
+		}
+	}
+	return false
+}
+
+func (controller *Controller) isStageSelected(stage *model.Stage, set map[string]bool) bool {
+	if len(set) != 0 && !set[stage.Name] {
+		return false
+	}
+	return controller.tags.matches(getStageTags(controller.procedure, stage))
+}
+
+func (controller *Controller) runStage(run *testRun, stagePointer *model.Stage) error {
 	stage := *stagePointer
+	result := run.beginStage(stage.Name)
+	start := time.Now()
+	defer func() {
+		result.Duration = time.Since(start)
+	}()
 	logger.Info().
 		Str("stage", stage.Name).
 		Msg("Beginning to run through steps in stage")
-	for _, step := range stage.Steps {
-		function, err := controller.stepManager.GetTestMethod(step.Description)
+	var stageErr error
+	// a stage which was running when the run was interrupted carries on as if it had failed, whereas a stage started afterwards is teardown
+	startedBeforeInterrupt := !controller.interrupt.IsInterrupted()
+	for _, templateStep := range stage.Steps {
+		interrupted := startedBeforeInterrupt && controller.interrupt.IsInterrupted()
+		shouldRun, err := evaluateCondition(templateStep.If, stageErr != nil || interrupted, templateStep.AlwaysRuns, run.lookup)
 		if err != nil {
-			logger.Error().
+			return err
+		}
+		if !shouldRun {
+			if interrupted && stageErr == nil {
+				stageErr = fmt.Errorf("Stage '%s' was interrupted before step '%s'", stage.Name, templateStep.Description)
+			}
+			logger.Debug().
+				Str("stage", stage.Name).
+				Str("step", templateStep.Description).
+				Str("if", templateStep.If).
+				Msg("Skipping step")
+			result.AddStep(model.StepResult{Description: templateStep.Description, Status: model.Skipped})
+			run.recordStep(&templateStep, model.Skipped)
+			continue
+		}
+
+		err = controller.runStepIterations(run, templateStep, result)
+		if err != nil && templateStep.ContinueOnError {
+			logger.Warn().
 				Err(err).
 				Str("stage", stage.Name).
-				Str("step", step.Description).
-				Bool("hasFailed", true).
-				Msg("Could not find step in stage manager")
-			return err
+				Str("step", templateStep.Description).
+				Msg("Step has failed but is allowed to, continuing with stage")
+			continue
 		}
-		if err := runStep(function, step); err != nil {
+		if err != nil {
 			logger.Error().
 				Err(err).
 				Str("stage", stage.Name).
 				Bool("hasFailed", true).
 				Msg("Stage has failed at step")
-			return err
+			if stageErr == nil {
+				stageErr = err
+			}
 		}
 	}
+	if stageErr != nil {
+		return stageErr
+	}
+	result.Status = model.Passed
 	logger.Info().
 		Str("stage", stage.Name).
 		Msg("Completed running through steps in stage")
 	return nil
 }
 
-func runStep(function func(*model.Step) error, step model.Step) error {
+func runStep(function func(*model.Step) error, step *model.Step) (model.StepResult, error) {
 	logger.Info().
 		Str("step", step.Description).
 		Msg("Beginning to run step")
-	if err := function(&step); err != nil {
-		return err
-	}
-	if !step.HasSucceeded() {
-		err := fmt.Errorf("Step '%s' has failed", step.Description)
+	start := time.Now()
+	err := callStep(function, step)
+	if err == nil && !step.HasSucceeded() {
+		err = fmt.Errorf("Step '%s' has failed", step.Description)
 		logger.Error().
 			Err(err).
 			Str("step", step.Description).
 			Msg("Step has errored")
-		return err
 	}
+
+	result := model.StepResult{Description: step.Description, Status: model.Passed, Duration: time.Since(start), Timings: step.GetTimings()}
+	if err != nil {
+		result.Status = model.Failed
+		result.ContinuedOnError = step.ContinueOnError
+		result.Error = err.Error()
+		return result, err
+	}
+
 	logger.Info().
 		Str("step", step.Description).
 		Msg("Finished running to run step")
-	return nil
+	return result, nil
+}
+
+// callStep will call the step function and wait for it to return, unless the context of the step is cancelled first. A step which does not
+// check its context is then left to finish in the background.
+func callStep(function func(*model.Step) error, step *model.Step) error {
+	ctx := step.GetContext()
+	if ctx.Done() == nil {
+		return function(step)
+	}
+	if ctx.Err() != nil {
+		return fmt.Errorf("Step '%s' was interrupted: %v", step.Description, ctx.Err())
+	}
+
+	done := make(chan error, 1)
+	go func() {
+		done <- function(step)
+	}()
+	select {
+	case err := <-done:
+		return err
+	case <-ctx.Done():
+		select {
+		case err := <-done:
+			return err
+		default:
+		}
+		logger.Warn().
+			Str("step", step.Description).
+			Msg("Stopped waiting for step as it was interrupted")
+		return fmt.Errorf("Step '%s' was interrupted: %v", step.Description, ctx.Err())
+	}
+}
+
+// GetResults will return the results of the last test that was run. There is a result for each matrix combination of the test.
+func (controller *Controller) GetResults() []*model.TestResult {
+	controller.resultsLock.Lock()
+	defer controller.resultsLock.Unlock()
+	return append([]*model.TestResult{}, controller.results...)
+}
+
+func (controller *Controller) addResult(result *model.TestResult) {
+	controller.resultsLock.Lock()
+	defer controller.resultsLock.Unlock()
+	controller.results = append(controller.results, result)
 }
 
 // GetContainerInfo will return a list of ContainerInfo containing information about containers present on the host's daemon
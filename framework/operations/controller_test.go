@@ -4,8 +4,10 @@ import (
 	"errors"
 	"fmt"
 	"os"
+	"sync"
 	"testing"
 
+	"github.com/julianGoh17/simple-e2e/framework/docker"
 	"github.com/julianGoh17/simple-e2e/framework/internal"
 	models "github.com/julianGoh17/simple-e2e/framework/models"
 	"github.com/julianGoh17/simple-e2e/framework/util"
@@ -173,6 +175,400 @@ func TestFailsWhenCanNotGetStep(t *testing.T) {
 	assert.Error(t, controller.runTest([]byte(multiStageRun)))
 }
 
+const matrixRun = `
+name: example-test
+description: example description
+globalVariables:
+  GREETING: hello
+matrix:
+  parameters:
+    NAME: [first, second, third]
+  parallel: 3
+stages:
+  - name: example-stage
+    steps:
+      - description: "example-step"
+        variables:
+          MESSAGE: "${GREETING} ${NAME}"
+  - name: example-stage2
+    steps:
+      - description: "example-step"
+        variables:
+          MESSAGE: "${GREETING} again ${NAME}"
+`
+
+const emptyMatrix = `
+name: example-test
+description: example description
+matrix:
+  parameters:
+    NAME: []
+stages:
+  - name: example-stage
+    steps:
+      - description: "example-step"
+`
+
+func TestRunTestForEachMatrixCombination(t *testing.T) {
+	controller, err := NewController()
+	assert.NoError(t, err)
+
+	messages := []string{}
+	globals := []string{}
+	lock := sync.Mutex{}
+	assert.NoError(t, controller.AddTestStep("example-step", func(step *models.Step) error {
+		lock.Lock()
+		defer lock.Unlock()
+		message, err := step.GetValueFromVariablesAsString("MESSAGE")
+		messages = append(messages, message)
+		globals = append(globals, step.GetGlobalVariable("NAME"))
+		assert.Equal(t, map[string]string{"NAME": step.GetGlobalVariable("NAME")}, step.GetCombination())
+		step.SetErrored(err)
+		return err
+	}))
+
+	assert.NoError(t, controller.runTest([]byte(matrixRun)))
+	assert.ElementsMatch(t, []string{"hello first", "hello second", "hello third", "hello again first", "hello again second", "hello again third"}, messages)
+	assert.ElementsMatch(t, []string{"first", "second", "third", "first", "second", "third"}, globals)
+
+	results := controller.GetResults()
+	assert.Equal(t, 3, len(results))
+	for _, result := range results {
+		assert.Equal(t, "example-test", result.Name)
+		assert.Equal(t, models.Passed, result.Status)
+		assert.Equal(t, 2, len(result.Stages))
+		assert.Equal(t, models.Passed, result.Stages[1].Status)
+		assert.Equal(t, "example-step", result.Stages[1].Steps[0].Description)
+	}
+}
+
+func TestRunTestGivesMatrixCombinationsTheirOwnDocker(t *testing.T) {
+	controller, err := NewController()
+	assert.NoError(t, err)
+
+	handlers := make(map[string]*docker.Handler)
+	lock := sync.Mutex{}
+	assert.NoError(t, controller.AddTestStep("example-step", func(step *models.Step) error {
+		lock.Lock()
+		defer lock.Unlock()
+		handlers[step.GetGlobalVariable("NAME")] = step.Docker
+		step.SetPassed()
+		return nil
+	}))
+
+	assert.NoError(t, controller.runTest([]byte(matrixRun)))
+	assert.Equal(t, 3, len(handlers))
+	for name, handler := range handlers {
+		assert.NotNil(t, handler, name)
+		assert.NotEqual(t, controller.docker, handler, name)
+	}
+	assert.NotEqual(t, handlers["first"], handlers["second"])
+
+	assert.NoError(t, controller.runTest([]byte(correctlyFormated)))
+	assert.Equal(t, controller.docker, handlers[""])
+}
+
+func TestGetCombinationNamespace(t *testing.T) {
+	assert.Equal(t, "NAME-first", getCombinationNamespace(map[string]string{"NAME": "first"}))
+	assert.Equal(t, "DB-postgres-VERSION-12.1", getCombinationNamespace(map[string]string{"VERSION": "12.1", "DB": "postgres"}))
+	assert.Equal(t, "PATH-api-v1-users", getCombinationNamespace(map[string]string{"PATH": "/api/v1/users?"}))
+}
+
+func TestRunTestFailsForSomeMatrixCombinations(t *testing.T) {
+	controller, err := NewController()
+	assert.NoError(t, err)
+	assert.NoError(t, controller.AddTestStep("example-step", func(step *models.Step) error {
+		if step.GetGlobalVariable("NAME") == "second" {
+			return errors.New("This will error")
+		}
+		step.SetPassed()
+		return nil
+	}))
+
+	err = controller.runTest([]byte(matrixRun))
+	assert.Error(t, err)
+	assert.Equal(t, "Test failed for 1 of 3 matrix combinations", err.Error())
+
+	for _, result := range controller.GetResults() {
+		if result.Combination["NAME"] == "second" {
+			assert.Equal(t, models.Failed, result.Status)
+			assert.Equal(t, "Test failed at stage: example-stage", result.Error)
+			assert.Equal(t, models.Failed, result.Stages[0].Status)
+			assert.Equal(t, "This will error", result.Stages[0].Steps[0].Error)
+			assert.Equal(t, models.Skipped, result.Stages[1].Status)
+			assert.Equal(t, models.Skipped, result.Stages[1].Steps[0].Status)
+		} else {
+			assert.Equal(t, models.Passed, result.Status)
+		}
+	}
+}
+
+func TestRunTestFailsWithEmptyMatrix(t *testing.T) {
+	controller, err := NewController()
+	assert.NoError(t, err)
+	assert.NoError(t, controller.AddTestStep("example-step", testFuncPassStep))
+
+	err = controller.runTest([]byte(emptyMatrix))
+	assert.Error(t, err)
+	assert.Equal(t, "Matrix of test 'example-test' does not have any combinations", err.Error())
+}
+
+func TestRunTestWithExampleMatrix(t *testing.T) {
+	internal.SetTestFilesRoot()
+	controller, err := NewController()
+	assert.NoError(t, err)
+
+	assert.NoError(t, controller.RunTest(fmt.Sprintf("%s/examples/matrix-test.yaml", os.Getenv(util.TestDirEnv))))
+	assert.Equal(t, 4, len(controller.GetResults()))
+}
+
+const conditionalStages = `
+name: example-test
+description: example description
+globalVariables:
+  DEBUG: ""
+stages:
+  - name: setup
+    steps:
+      - description: "fail-step"
+  - name: test
+    steps:
+      - description: "pass-step"
+  - name: diagnostics
+    if: failure()
+    steps:
+      - description: "pass-step"
+  - name: debug
+    if: DEBUG
+    alwaysRuns: true
+    steps:
+      - description: "pass-step"
+  - name: cleanup
+    alwaysRuns: true
+    steps:
+      - description: "pass-step"
+`
+
+const conditionalSteps = `
+name: example-test
+description: example description
+stages:
+  - name: example-stage
+    steps:
+      - id: build
+        description: "output-step"
+      - description: "check-step"
+        if: steps.build.status == 'Passed'
+        variables:
+          TAG: "${steps.build.outputs.TAG}"
+      - description: "fail-step"
+      - description: "check-step"
+        variables:
+          TAG: "skipped"
+      - description: "check-step"
+        if: failure()
+        variables:
+          TAG: "diagnostics"
+      - description: "check-step"
+        if: always() && steps.build.outputs.TAG == '2.0'
+        variables:
+          TAG: "skipped"
+`
+
+const stepFailureHandling = `
+name: example-test
+description: example description
+stages:
+  - name: example-stage
+    steps:
+      - description: "fail-step"
+        continueOnError: true
+      - description: "pass-step"
+      - description: "fail-step"
+      - description: "pass-step"
+      - description: "pass-step"
+        alwaysRuns: true
+      - description: "fail-step"
+        alwaysRuns: true
+        continueOnError: true
+`
+
+const invalidCondition = `
+name: example-test
+description: example description
+stages:
+  - name: example-stage
+    if: failure() &&
+    steps:
+      - description: "pass-step"
+`
+
+func TestRunStagesWithConditions(t *testing.T) {
+	controller, err := NewController()
+	assert.NoError(t, err)
+	assert.NoError(t, controller.AddTestStep("fail-step", testFuncFailStep))
+	assert.NoError(t, controller.AddTestStep("pass-step", testFuncPassStep))
+
+	err = controller.runTest([]byte(conditionalStages))
+	assert.Error(t, err)
+	assert.Equal(t, "Test failed at stage: setup", err.Error())
+
+	expected := []models.ResultStatus{models.Failed, models.Skipped, models.Passed, models.Skipped, models.Passed}
+	stages := controller.GetResults()[0].Stages
+	assert.Equal(t, len(expected), len(stages))
+	for index, status := range expected {
+		assert.Equal(t, status, stages[index].Status, stages[index].Name)
+	}
+}
+
+func TestRunStepsWithConditionsAndOutputs(t *testing.T) {
+	controller, err := NewController()
+	assert.NoError(t, err)
+
+	tags := []string{}
+	assert.NoError(t, controller.AddTestStep("output-step", func(step *models.Step) error {
+		step.SetOutput("TAG", "1.0")
+		step.SetPassed()
+		return nil
+	}))
+	assert.NoError(t, controller.AddTestStep("check-step", func(step *models.Step) error {
+		tag, err := step.GetValueFromVariablesAsString("TAG")
+		tags = append(tags, tag)
+		step.SetErrored(err)
+		return err
+	}))
+	assert.NoError(t, controller.AddTestStep("fail-step", testFuncFailStep))
+
+	err = controller.runTest([]byte(conditionalSteps))
+	assert.Error(t, err)
+	assert.Equal(t, "Test failed at stage: example-stage", err.Error())
+	assert.Equal(t, []string{"1.0", "diagnostics"}, tags)
+
+	expected := []models.ResultStatus{models.Passed, models.Passed, models.Failed, models.Skipped, models.Passed, models.Skipped}
+	stage := controller.GetResults()[0].Stages[0]
+	assert.Equal(t, models.Failed, stage.Status)
+	assert.Equal(t, len(expected), len(stage.Steps))
+	for index, status := range expected {
+		assert.Equal(t, status, stage.Steps[index].Status)
+	}
+}
+
+func TestRunStepsWithAlwaysRunsAndContinueOnError(t *testing.T) {
+	controller, err := NewController()
+	assert.NoError(t, err)
+	assert.NoError(t, controller.AddTestStep("fail-step", testFuncFailStep))
+	assert.NoError(t, controller.AddTestStep("pass-step", testFuncPassStep))
+
+	err = controller.runTest([]byte(stepFailureHandling))
+	assert.Error(t, err)
+	assert.Equal(t, "Test failed at stage: example-stage", err.Error())
+
+	stage := controller.GetResults()[0].Stages[0]
+	assert.Equal(t, models.Failed, stage.Status)
+	expected := []struct {
+		status           models.ResultStatus
+		continuedOnError bool
+	}{
+		{models.Failed, true},
+		{models.Passed, false},
+		{models.Failed, false},
+		{models.Skipped, false},
+		{models.Passed, false},
+		{models.Failed, true},
+	}
+	assert.Equal(t, len(expected), len(stage.Steps))
+	for index, step := range expected {
+		assert.Equal(t, step.status, stage.Steps[index].Status)
+		assert.Equal(t, step.continuedOnError, stage.Steps[index].ContinuedOnError)
+	}
+}
+
+func TestRunStepsPassWhenFailuresAreAllowed(t *testing.T) {
+	controller, err := NewController()
+	assert.NoError(t, err)
+	assert.NoError(t, controller.AddTestStep("fail-step", testFuncFailStep))
+	assert.NoError(t, controller.AddTestStep("pass-step", testFuncPassStep))
+
+	assert.NoError(t, controller.runTest([]byte(`
+name: example-test
+description: example description
+stages:
+  - name: example-stage
+    steps:
+      - description: "fail-step"
+        continueOnError: true
+      - description: "missing-step"
+        continueOnError: true
+      - description: "pass-step"
+`)))
+	stage := controller.GetResults()[0].Stages[0]
+	assert.Equal(t, models.Passed, stage.Status)
+	assert.Equal(t, "Step 'missing-step' is not registered in step list", stage.Steps[1].Error)
+	assert.True(t, stage.Steps[1].ContinuedOnError)
+}
+
+func TestSetProcedureFailsWithInvalidCondition(t *testing.T) {
+	controller, err := NewController()
+	assert.NoError(t, err)
+
+	err = controller.SetProcedure([]byte(invalidCondition))
+	assert.Error(t, err)
+	assert.Equal(t, "Invalid 'if' expression in stage 'example-stage': Unexpected end of expression 'failure() &&'", err.Error())
+}
+
+func TestRunTestWithExampleConditions(t *testing.T) {
+	internal.SetTestFilesRoot()
+	controller, err := NewController()
+	assert.NoError(t, err)
+
+	assert.NoError(t, controller.RunTest(fmt.Sprintf("%s/examples/conditions-test.yaml", os.Getenv(util.TestDirEnv))))
+	stages := controller.GetResults()[0].Stages
+	assert.Equal(t, []models.StepResult{stages[0].Steps[0], {Description: "Say hello to", Status: models.Skipped}}, stages[0].Steps)
+	assert.Equal(t, models.Skipped, stages[1].Status)
+	assert.Equal(t, models.Passed, stages[2].Status)
+}
+
+func TestRunTestWithTagFilter(t *testing.T) {
+	internal.SetTestFilesRoot()
+	testPath := fmt.Sprintf("%s/examples/tags-test.yaml", os.Getenv(util.TestDirEnv))
+	tables := []struct {
+		include  string
+		exclude  string
+		stages   []string
+		expected []models.ResultStatus
+	}{
+		{"", "", []string{}, []models.ResultStatus{models.Passed, models.Passed, models.Passed}},
+		{"smoke", "", []string{}, []models.ResultStatus{models.Passed, models.Skipped, models.Passed}},
+		{"smoke && !slow", "", []string{}, []models.ResultStatus{models.Passed, models.Skipped, models.Skipped}},
+		{"example", "slow", []string{}, []models.ResultStatus{models.Passed, models.Skipped, models.Skipped}},
+		{"smoke", "", []string{"smoke-and-soak"}, []models.ResultStatus{models.Skipped, models.Skipped, models.Passed}},
+		{"missing", "", []string{}, []models.ResultStatus{}},
+	}
+
+	for _, table := range tables {
+		controller, err := NewController()
+		assert.NoError(t, err)
+		assert.NoError(t, controller.SetTagFilter(table.include, table.exclude))
+
+		assert.NoError(t, controller.RunTest(testPath, table.stages...))
+		result := controller.GetResults()[0]
+		assert.Equal(t, len(table.expected), len(result.Stages))
+		for index, status := range table.expected {
+			assert.Equal(t, status, result.Stages[index].Status)
+		}
+		if len(table.expected) == 0 {
+			assert.Equal(t, models.Skipped, result.Status)
+		}
+	}
+}
+
+func TestSetTagFilterFails(t *testing.T) {
+	controller, err := NewController()
+	assert.NoError(t, err)
+	assert.Error(t, controller.SetTagFilter("smoke &&", ""))
+	assert.Nil(t, controller.tags)
+}
+
 func testFuncPassStep(step *models.Step) error {
 	step.SetPassed()
 	return nil
@@ -8,11 +8,43 @@ import (
 
 func getDefaultSteps() map[string]func(step *models.Step) error {
 	defaultSteps := map[string]func(step *models.Step) error{
-		"Say hello to":     SayHelloTo,
-		"Pull image":       PullImage,
-		"Build image":      BuildImage,
-		"Create container": CreateContainer,
-		"Delete container": DeleteContainer,
+		"Say hello to":       SayHelloTo,
+		"Pull image":         PullImage,
+		"Build image":        BuildImage,
+		"Create container":   CreateContainer,
+		"Delete container":   DeleteContainer,
+		"Send HTTP request":  SendHTTPRequest,
+		"Run command":        RunCommand,
+		"Wait for port":      WaitForPort,
+		"Assert port closed": AssertPortClosed,
+		// The example tests describe getting data from an API, which is just a GET request
+		"Get data from API Endpoint": SendHTTPRequest,
+		"Assert JSON path equals":    AssertJSONPathEquals,
+		"Assert JSON matches schema": AssertJSONMatchesSchema,
+		"Assert JSON subset":         AssertJSONSubset,
+		// The example tests create a mock API and load data into it before getting the data back
+		"Create mock API endpoint":            CreateMockEndpoint,
+		"Load fake data to mock API endpoint": LoadMockData,
+		"Assert mock received requests":       AssertMockReceivedRequests,
+		"Stop mock API endpoint":              StopMockEndpoint,
+		// File steps read and write files in the workspace of the test run
+		"Write file":             WriteFile,
+		"Render template":        RenderTemplate,
+		"Compare file to golden": CompareFileToGolden,
+		"Assert file":            AssertFile,
+		"Delete file":            DeleteFile,
+		// Snapshots are saved under '<TEST_DIR>/__snapshots__' and rewritten by 'run --update-snapshots'
+		"Match snapshot": MatchSnapshot,
+		// Timers are shared by the steps of a test run and durations can be written out such as '1 minute and 30 seconds'
+		"Sleep for '${duration}'":  Sleep,
+		"Start timer":              StartTimer,
+		"Assert elapsed less than": AssertElapsedLessThan,
+		// SQL steps connect to the database at 'DSN' with the 'DRIVER' 'postgres', 'mysql' or 'sqlite'
+		"Execute SQL":          ExecuteSQL,
+		"Execute SQL file":     ExecuteSQLFile,
+		"Assert query returns": AssertQueryReturns,
+		// gRPC requests find the types of their messages with server reflection or in '.proto' files
+		"Send gRPC request": SendGRPCRequest,
 	}
 
 	return defaultSteps
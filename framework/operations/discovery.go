@@ -0,0 +1,146 @@
+package operations
+
+import (
+	"fmt"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/julianGoh17/simple-e2e/framework/util"
+	"gopkg.in/yaml.v2"
+)
+
+// FindTestFiles returns the paths of the test files for the given test names, which are relative to the test directory and do not need the file
+// extension. Names can be globs such as 'examples/*'. When all is true every test file under the test directory is found. A glob or all only finds
+// YAML files with stages. Test files which are included by another found test file are libraries and are only left out when they were found by a
+// glob or by all.
+func FindTestFiles(testDir string, tests []string, all bool) ([]string, error) {
+	discovered := []string{}
+	explicit := make(map[string]bool)
+
+	if all {
+		err := filepath.Walk(testDir, func(path string, info os.FileInfo, err error) error {
+			if err != nil {
+				return err
+			}
+			if !info.IsDir() && isTestFile(path) {
+				discovered = append(discovered, path)
+			}
+			return nil
+		})
+		if err != nil {
+			return nil, fmt.Errorf("unable to find test files: %v", err)
+		}
+		if len(discovered) == 0 {
+			return nil, fmt.Errorf("Could not find any test files in '%s'", testDir)
+		}
+	}
+
+	for _, test := range tests {
+		if !strings.ContainsAny(test, "*?[") {
+			path := fmt.Sprintf("%s/%s.yaml", testDir, test)
+			discovered = append(discovered, path)
+			explicit[path] = true
+			continue
+		}
+		pattern := test
+		if filepath.Ext(pattern) == "" {
+			pattern = pattern + ".yaml"
+		}
+		matches, err := filepath.Glob(filepath.Join(testDir, pattern))
+		if err != nil {
+			return nil, fmt.Errorf("Invalid test pattern '%s': %v", test, err)
+		}
+		found := 0
+		for _, match := range matches {
+			if info, err := os.Stat(match); err == nil && !info.IsDir() && isTestFile(match) {
+				discovered = append(discovered, match)
+				found++
+			}
+		}
+		if found == 0 {
+			return nil, fmt.Errorf("Could not find any test files matching '%s'", test)
+		}
+	}
+
+	paths := []string{}
+	libraries := getIncludedFiles(discovered)
+	for _, path := range discovered {
+		if explicit[path] || !libraries[filepath.Clean(path)] {
+			paths = append(paths, path)
+		}
+	}
+	logger.Trace().
+		Strs("tests", tests).
+		Bool("all", all).
+		Strs("paths", paths).
+		Msg("Found test files")
+	return removeDuplicatePaths(paths), nil
+}
+
+// getTestDirPath returns the path relative to the test directory, or the path itself if it is absolute
+func getTestDirPath(path string) string {
+	if filepath.IsAbs(path) {
+		return filepath.Clean(path)
+	}
+	return filepath.Join(config.GetOrDefault(util.TestDirEnv), path)
+}
+
+// isTestFile returns whether the file is a YAML file with stages. Other YAML files under the test directory, such as '--var-file' files, expected
+// documents and libraries which only have templates or macros, are not tests.
+func isTestFile(path string) bool {
+	ext := filepath.Ext(path)
+	if ext != ".yaml" && ext != ".yml" {
+		return false
+	}
+	body, err := ioutil.ReadFile(path)
+	if err != nil {
+		return false
+	}
+	procedure := struct {
+		Stages []interface{} `yaml:"stages"`
+	}{}
+	if err := yaml.Unmarshal(body, &procedure); err != nil {
+		logger.Trace().
+			Err(err).
+			Str("path", path).
+			Msg("Skipping YAML file which is not a test")
+		return false
+	}
+	return len(procedure.Stages) > 0
+}
+
+// getIncludedFiles returns the set of files which are included by any of the test files
+func getIncludedFiles(paths []string) map[string]bool {
+	included := make(map[string]bool)
+	for _, path := range paths {
+		body, err := ioutil.ReadFile(path)
+		if err != nil {
+			continue
+		}
+		procedure := struct {
+			Include []string `yaml:"include"`
+		}{}
+		if err := yaml.Unmarshal(body, &procedure); err != nil {
+			continue
+		}
+		for _, include := range procedure.Include {
+			included[filepath.Clean(getIncludePath(include))] = true
+		}
+	}
+	return included
+}
+
+func removeDuplicatePaths(paths []string) []string {
+	seen := make(map[string]bool, len(paths))
+	unique := []string{}
+	for _, path := range paths {
+		if seen[filepath.Clean(path)] {
+			continue
+		}
+		seen[filepath.Clean(path)] = true
+		unique = append(unique, path)
+	}
+	return unique
+}
@@ -0,0 +1,108 @@
+package operations
+
+import (
+	"fmt"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/julianGoh17/simple-e2e/framework/internal"
+	"github.com/julianGoh17/simple-e2e/framework/util"
+	"github.com/stretchr/testify/assert"
+)
+
+var exampleTests = []string{
+	"examples/conditions-test.yaml",
+	"examples/include-test.yaml",
+	"examples/loops-test.yaml",
+	"examples/macro-test.yaml",
+	"examples/matrix-test.yaml",
+	"examples/multi-stage-test.yaml",
+	"examples/native-variables-test.yaml",
+	"examples/secrets-test.yaml",
+	"examples/simple-test.yaml",
+	"examples/tags-test.yaml",
+}
+
+func TestFindTestFiles(t *testing.T) {
+	internal.SetTestFilesRoot()
+	testDir := filepath.Clean(os.Getenv(util.TestDirEnv))
+
+	tables := []struct {
+		tests    []string
+		all      bool
+		expected []string
+	}{
+		{[]string{"test"}, false, []string{"test.yaml"}},
+		{[]string{"examples/simple-test", "test", "examples/simple-test"}, false, []string{"examples/simple-test.yaml", "test.yaml"}},
+		{[]string{"examples/*"}, false, exampleTests},
+		{[]string{"examples/m*-test.yaml"}, false, []string{"examples/macro-test.yaml", "examples/matrix-test.yaml", "examples/multi-stage-test.yaml"}},
+		{[]string{"examples/library/*"}, false, []string{"examples/library/teardown.yaml"}},
+		{[]string{"examples/library/setup"}, false, []string{"examples/library/setup.yaml"}},
+		{[]string{}, true, append(exampleTests, "test.yaml")},
+	}
+
+	for _, table := range tables {
+		paths, err := FindTestFiles(testDir, table.tests, table.all)
+		assert.NoError(t, err)
+		expected := []string{}
+		for _, path := range table.expected {
+			expected = append(expected, filepath.Join(testDir, path))
+		}
+		cleaned := []string{}
+		for _, path := range paths {
+			cleaned = append(cleaned, filepath.Clean(path))
+		}
+		assert.Equal(t, expected, cleaned, fmt.Sprint(table.tests))
+	}
+}
+
+func TestFindTestFilesSkipsYAMLFilesWithoutStages(t *testing.T) {
+	dir, err := ioutil.TempDir("", "simple-e2e-discovery")
+	assert.NoError(t, err)
+	defer os.RemoveAll(dir)
+	files := map[string]string{
+		"smoke-test.yaml":       "name: smoke\ndescription: smoke test\nstages:\n  - name: test\n    steps: []\n",
+		"vars.yaml":             "HOST: localhost\nPORT: 8080\n",
+		"fixtures/rows.yml":     "- name: Toronto\n- name: Vancouver\n",
+		"library/macros.yaml":   "name: macros\nmacros:\n  login:\n    steps: []\n",
+		"library/empty.yaml":    "name: empty\nstages: []\n",
+		"templates/invalid.yml": "stages: {{ .Stages }}\n",
+	}
+	for name, body := range files {
+		assert.NoError(t, os.MkdirAll(filepath.Dir(filepath.Join(dir, name)), 0755))
+		assert.NoError(t, ioutil.WriteFile(filepath.Join(dir, name), []byte(body), 0644))
+	}
+
+	paths, err := FindTestFiles(dir, []string{}, true)
+	assert.NoError(t, err)
+	assert.Equal(t, []string{filepath.Join(dir, "smoke-test.yaml")}, paths)
+
+	paths, err = FindTestFiles(dir, []string{"*"}, false)
+	assert.NoError(t, err)
+	assert.Equal(t, []string{filepath.Join(dir, "smoke-test.yaml")}, paths)
+
+	_, err = FindTestFiles(dir, []string{"library/*"}, false)
+	assert.EqualError(t, err, "Could not find any test files matching 'library/*'")
+
+	paths, err = FindTestFiles(dir, []string{"vars"}, false)
+	assert.NoError(t, err)
+	assert.Equal(t, []string{fmt.Sprintf("%s/vars.yaml", dir)}, paths)
+}
+
+func TestFindTestFilesFails(t *testing.T) {
+	internal.SetTestFilesRoot()
+	testDir := os.Getenv(util.TestDirEnv)
+
+	_, err := FindTestFiles(testDir, []string{"random/*"}, false)
+	assert.Error(t, err)
+	assert.Equal(t, "Could not find any test files matching 'random/*'", err.Error())
+
+	_, err = FindTestFiles(testDir, []string{"[examples"}, false)
+	assert.Error(t, err)
+	assert.Equal(t, "Invalid test pattern '[examples': syntax error in pattern", err.Error())
+
+	_, err = FindTestFiles(filepath.Join(testDir, "random"), []string{}, true)
+	assert.Error(t, err)
+}
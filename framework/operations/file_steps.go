@@ -0,0 +1,308 @@
+package operations
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"text/template"
+
+	"github.com/julianGoh17/simple-e2e/framework/models"
+	"github.com/julianGoh17/simple-e2e/framework/util"
+	"gopkg.in/yaml.v2"
+)
+
+const defaultFileMode os.FileMode = 0644
+
+// WriteFile will write a file in the workspace of the test run, creating the directories it is in. The path of the file is saved as the
+// output 'path'.
+// Environmental Variables:
+//   - PATH: The path of the file, relative to the workspace
+//   - CONTENT: The content of the file. A YAML map or list is written as JSON if the file ends with '.json' and as YAML otherwise
+//   - MODE: The permissions of the file as an octal number such as '0600' (default 0644)
+func WriteFile(step *models.Step) error {
+	traceStepEntrance(step)
+	path, err := getFileStepPath(step)
+	if err != nil {
+		return traceStepExit(step, err)
+	}
+	value, err := step.GetValueFromVariables("CONTENT")
+	if err != nil {
+		return traceStepExit(step, err)
+	}
+	content, err := encodeFileContent(path, value)
+	if err != nil {
+		return traceStepExit(step, err)
+	}
+	return traceStepExit(step, writeWorkspaceFile(step, path, content))
+}
+
+// RenderTemplate will render a Go 'text/template' with the variables of the test run, such as '{{ .DATABASE_URL }}', and write it to a file
+// in the workspace of the test run. Using a variable which does not exist is an error. The path of the file is saved as the output 'path'.
+// Environmental Variables:
+//   - TEMPLATE: The template to render
+//   - TEMPLATE_FILE: A file with the template to render, relative to the test directory, which is used when TEMPLATE is not set
+//   - DATA: A map of values to render the template with as well as the variables of the test run, which it takes precedence over
+//   - PATH: The path of the rendered file, relative to the workspace
+//   - MODE: The permissions of the file as an octal number such as '0600' (default 0644)
+func RenderTemplate(step *models.Step) error {
+	traceStepEntrance(step)
+	path, err := getFileStepPath(step)
+	if err != nil {
+		return traceStepExit(step, err)
+	}
+	name, text, err := getTemplate(step)
+	if err != nil {
+		return traceStepExit(step, err)
+	}
+
+	data := make(map[string]interface{})
+	for key, value := range step.GetRunVariables() {
+		data[key] = value
+	}
+	if value, err := step.GetValueFromVariables("DATA"); err == nil {
+		values, isMap := value.(map[string]interface{})
+		if !isMap {
+			return traceStepExit(step, fmt.Errorf("Variable 'DATA' of step '%s' must be a map", step.Description))
+		}
+		for key, value := range values {
+			data[key] = value
+		}
+	}
+
+	parsed, err := template.New(name).Option("missingkey=error").Parse(text)
+	if err != nil {
+		return traceStepExit(step, fmt.Errorf("Could not read template '%s': %v", name, err))
+	}
+	var rendered bytes.Buffer
+	if err := parsed.Execute(&rendered, data); err != nil {
+		return traceStepExit(step, fmt.Errorf("Could not render template '%s': %v", name, err))
+	}
+	return traceStepExit(step, writeWorkspaceFile(step, path, rendered.Bytes()))
+}
+
+// CompareFileToGolden will check that a file in the workspace of the test run is the same as a golden copy, failing with a unified diff of
+// the golden copy and the file when they are different
+// Environmental Variables:
+//   - PATH: The path of the file, relative to the workspace
+//   - GOLDEN_FILE: The path of the golden copy, relative to the test directory
+//   - UPDATE_GOLDEN: Whether to overwrite the golden copy with the file instead of comparing them (default false)
+func CompareFileToGolden(step *models.Step) error {
+	traceStepEntrance(step)
+	path, err := getFileStepPath(step)
+	if err != nil {
+		return traceStepExit(step, err)
+	}
+	golden, err := step.GetValueFromVariablesAsString("GOLDEN_FILE")
+	if err != nil {
+		return traceStepExit(step, err)
+	}
+	update, err := step.GetValueFromVariablesAsBooleanOrDefault("UPDATE_GOLDEN", false)
+	if err != nil {
+		return traceStepExit(step, err)
+	}
+
+	actual, err := ioutil.ReadFile(path)
+	if err != nil {
+		return traceStepExit(step, fmt.Errorf("Could not read file '%s': %v", path, err))
+	}
+	goldenPath := getTestDirPath(golden)
+	if update {
+		if err := ioutil.WriteFile(goldenPath, actual, defaultFileMode); err != nil {
+			return traceStepExit(step, fmt.Errorf("Could not update golden file '%s': %v", golden, err))
+		}
+		logger.Info().
+			Str("path", path).
+			Str("golden", goldenPath).
+			Msg("Updated golden file")
+		return traceStepExit(step, nil)
+	}
+	expected, err := ioutil.ReadFile(goldenPath)
+	if err != nil {
+		return traceStepExit(step, fmt.Errorf("Could not read golden file '%s': %v", golden, err))
+	}
+	if diff := util.UnifiedDiff(golden, path, string(expected), string(actual)); diff != "" {
+		return traceStepExit(step, fmt.Errorf("File '%s' does not match golden file '%s':\n%s", path, golden, diff))
+	}
+	return traceStepExit(step, nil)
+}
+
+// AssertFile will check whether a file in the workspace of the test run exists, and its permissions and contents
+// Environmental Variables:
+//   - PATH: The path of the file, relative to the workspace
+//   - EXISTS: Whether the file must exist. When it is false, the other checks are not done (default true)
+//   - MODE: The permissions the file must have as an octal number such as '0600'
+//   - CONTAINS: Text, or a list of text, which the file must contain
+//   - MATCHES: A regular expression which the contents of the file must match
+func AssertFile(step *models.Step) error {
+	traceStepEntrance(step)
+	path, err := getFileStepPath(step)
+	if err != nil {
+		return traceStepExit(step, err)
+	}
+	exists, err := step.GetValueFromVariablesAsBooleanOrDefault("EXISTS", true)
+	if err != nil {
+		return traceStepExit(step, err)
+	}
+	info, statErr := os.Stat(path)
+	if !exists {
+		if statErr == nil {
+			return traceStepExit(step, fmt.Errorf("File '%s' exists but expected it not to", path))
+		}
+		return traceStepExit(step, nil)
+	}
+	if statErr != nil {
+		return traceStepExit(step, fmt.Errorf("File '%s' does not exist: %v", path, statErr))
+	}
+
+	failures := []string{}
+	if _, ok := step.Variables["MODE"]; ok {
+		mode, err := getFileMode(step, defaultFileMode)
+		if err != nil {
+			return traceStepExit(step, err)
+		}
+		if info.Mode().Perm() != mode {
+			failures = append(failures, fmt.Sprintf("mode was %#o but expected %#o", info.Mode().Perm(), mode))
+		}
+	}
+
+	contains, err := getTextList(step, "CONTAINS")
+	if err != nil {
+		return traceStepExit(step, err)
+	}
+	_, hasPattern := step.Variables["MATCHES"]
+	if len(contains) > 0 || hasPattern {
+		content, err := ioutil.ReadFile(path)
+		if err != nil {
+			return traceStepExit(step, fmt.Errorf("Could not read file '%s': %v", path, err))
+		}
+		for _, text := range contains {
+			if !strings.Contains(string(content), text) {
+				failures = append(failures, fmt.Sprintf("contents do not contain '%s'", text))
+			}
+		}
+		if hasPattern {
+			pattern, err := step.GetValueFromVariablesAsRegex("MATCHES")
+			if err != nil {
+				return traceStepExit(step, err)
+			}
+			if !pattern.Match(content) {
+				failures = append(failures, fmt.Sprintf("contents do not match '%s'", pattern))
+			}
+		}
+	}
+
+	if len(failures) > 0 {
+		return traceStepExit(step, fmt.Errorf("File '%s' did not match: %s", path, strings.Join(failures, "; ")))
+	}
+	return traceStepExit(step, nil)
+}
+
+// DeleteFile will delete a file, or a directory and everything in it, from the workspace of the test run. Paths which do not exist are ignored.
+// Environmental Variables:
+//   - PATH: The path of the file or directory, relative to the workspace
+func DeleteFile(step *models.Step) error {
+	traceStepEntrance(step)
+	path, err := getFileStepPath(step)
+	if err != nil {
+		return traceStepExit(step, err)
+	}
+	if path == filepath.Clean(step.GetWorkspace()) {
+		return traceStepExit(step, fmt.Errorf("Step '%s' can not delete the workspace itself", step.Description))
+	}
+	if err := os.RemoveAll(path); err != nil {
+		return traceStepExit(step, fmt.Errorf("Could not delete '%s': %v", path, err))
+	}
+	return traceStepExit(step, nil)
+}
+
+// getFileStepPath returns the absolute path of the 'PATH' variable in the workspace of the step's run
+func getFileStepPath(step *models.Step) (string, error) {
+	path, err := step.GetValueFromVariablesAsString("PATH")
+	if err != nil {
+		return "", err
+	}
+	return getWorkspacePath(step, path)
+}
+
+// getTemplate returns the name and text of the template written in 'TEMPLATE' or read from 'TEMPLATE_FILE'
+func getTemplate(step *models.Step) (string, string, error) {
+	if text, err := step.GetValueFromVariablesAsString("TEMPLATE"); err == nil {
+		return "TEMPLATE", text, nil
+	}
+	path, err := step.GetValueFromVariablesAsString("TEMPLATE_FILE")
+	if err != nil {
+		return "", "", fmt.Errorf("Step '%s' must have 'TEMPLATE' or 'TEMPLATE_FILE'", step.Description)
+	}
+	body, err := ioutil.ReadFile(getTestDirPath(path))
+	if err != nil {
+		return "", "", fmt.Errorf("Could not read template '%s': %v", path, err)
+	}
+	return path, string(body), nil
+}
+
+// encodeFileContent returns text as it is, and YAML maps and lists as JSON if the file ends with '.json' or as YAML otherwise
+func encodeFileContent(path string, value interface{}) ([]byte, error) {
+	switch typed := value.(type) {
+	case string:
+		return []byte(typed), nil
+	case map[string]interface{}, []interface{}:
+		if strings.EqualFold(filepath.Ext(path), ".json") {
+			content, err := json.MarshalIndent(typed, "", "  ")
+			if err != nil {
+				return nil, fmt.Errorf("Could not convert variable 'CONTENT' to JSON: %v", err)
+			}
+			return append(content, '\n'), nil
+		}
+		content, err := yaml.Marshal(typed)
+		if err != nil {
+			return nil, fmt.Errorf("Could not convert variable 'CONTENT' to YAML: %v", err)
+		}
+		return content, nil
+	default:
+		return []byte(fmt.Sprint(typed)), nil
+	}
+}
+
+// writeWorkspaceFile will write the file with the mode of the step variables, creating the directories it is in, and save its path as an output
+func writeWorkspaceFile(step *models.Step, path string, content []byte) error {
+	mode, err := getFileMode(step, defaultFileMode)
+	if err != nil {
+		return err
+	}
+	if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+		return fmt.Errorf("Could not create directory of '%s': %v", path, err)
+	}
+	if err := ioutil.WriteFile(path, content, mode); err != nil {
+		return fmt.Errorf("Could not write file '%s': %v", path, err)
+	}
+	// The file may have been created with fewer permissions because of the umask, or already existed with other permissions
+	if err := os.Chmod(path, mode); err != nil {
+		return fmt.Errorf("Could not set mode of '%s': %v", path, err)
+	}
+	step.SetOutput("path", path)
+	return nil
+}
+
+// getFileMode returns the 'MODE' variable as file permissions. YAML reads unquoted numbers starting with 0, such as 0644, as octal numbers
+// already, so only quoted modes are read as octal here.
+func getFileMode(step *models.Step, defaultMode os.FileMode) (os.FileMode, error) {
+	value, err := step.GetValueFromVariables("MODE")
+	if err != nil {
+		return defaultMode, nil
+	}
+	var mode uint64
+	if number, isInt := value.(int); isInt {
+		mode = uint64(number)
+	} else if mode, err = strconv.ParseUint(fmt.Sprint(value), 8, 32); err != nil {
+		mode = 1 << 32
+	}
+	if mode > 0777 {
+		return 0, fmt.Errorf("Variable 'MODE' of step '%s' must be an octal file mode such as '0644'", step.Description)
+	}
+	return os.FileMode(mode), nil
+}
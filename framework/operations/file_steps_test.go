@@ -0,0 +1,253 @@
+package operations
+
+import (
+	"fmt"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/julianGoh17/simple-e2e/framework/models"
+	"github.com/stretchr/testify/assert"
+)
+
+func newFileStep(t *testing.T, workspace, description, variables string) *models.Step {
+	step := newStep(t, description, variables)
+	step.SetWorkspace(workspace)
+	return step
+}
+
+func newWorkspace(t *testing.T) string {
+	workspace, err := ioutil.TempDir("", "simple-e2e-files")
+	assert.NoError(t, err)
+	return workspace
+}
+
+func TestWriteFile(t *testing.T) {
+	workspace := newWorkspace(t)
+	defer os.RemoveAll(workspace)
+
+	tables := []struct {
+		variables string
+		path      string
+		content   string
+		mode      os.FileMode
+	}{
+		{"  PATH: hello.txt\n  CONTENT: hello", "hello.txt", "hello", 0644},
+		{"  PATH: configs/app.yaml\n  CONTENT: {port: 8080, hosts: [a, b]}\n  MODE: 0600", "configs/app.yaml", "hosts:\n- a\n- b\nport: 8080\n", 0600},
+		{"  PATH: configs/app.json\n  CONTENT: {port: 8080}\n  MODE: '0640'", "configs/app.json", "{\n  \"port\": 8080\n}\n", 0640},
+		{"  PATH: number.txt\n  CONTENT: 42", "number.txt", "42", 0644},
+		{"  PATH: hello.txt\n  CONTENT: replaced\n  MODE: '755'", "hello.txt", "replaced", 0755},
+	}
+
+	for _, table := range tables {
+		step := newFileStep(t, workspace, "Write file", table.variables)
+		assert.NoError(t, WriteFile(step), table.variables)
+		assert.True(t, step.HasSucceeded())
+		path := filepath.Join(workspace, table.path)
+		assert.Equal(t, path, step.GetOutputs()["path"])
+		content, err := ioutil.ReadFile(path)
+		assert.NoError(t, err)
+		assert.Equal(t, table.content, string(content))
+		info, err := os.Stat(path)
+		assert.NoError(t, err)
+		assert.Equal(t, table.mode, info.Mode().Perm(), table.variables)
+	}
+}
+
+func TestWriteFileFails(t *testing.T) {
+	workspace := newWorkspace(t)
+	defer os.RemoveAll(workspace)
+	assert.NoError(t, ioutil.WriteFile(filepath.Join(workspace, "file"), []byte{}, 0644))
+
+	tables := []struct {
+		variables string
+		err       string
+	}{
+		{"  CONTENT: hello", "Could not find variable 'PATH' in step.variables"},
+		{"  PATH: hello.txt", "Could not find variable 'CONTENT' in step.variables"},
+		{"  PATH: ../hello.txt\n  CONTENT: hello", "Path '../hello.txt' of step 'Write file' must be inside the workspace"},
+		{"  PATH: hello.txt\n  CONTENT: hello\n  MODE: rw", "Variable 'MODE' of step 'Write file' must be an octal file mode such as '0644'"},
+		{"  PATH: hello.txt\n  CONTENT: hello\n  MODE: 644", "Variable 'MODE' of step 'Write file' must be an octal file mode such as '0644'"},
+		{"  PATH: file/hello.txt\n  CONTENT: hello", "Could not create directory of"},
+		{"  PATH: .\n  CONTENT: hello", "Could not write file"},
+	}
+
+	for _, table := range tables {
+		step := newFileStep(t, workspace, "Write file", table.variables)
+		err := WriteFile(step)
+		if assert.Error(t, err, table.variables) {
+			assert.Contains(t, err.Error(), table.err)
+		}
+		assert.False(t, step.HasSucceeded())
+	}
+}
+
+func TestRenderTemplate(t *testing.T) {
+	workspace := newWorkspace(t)
+	defer os.RemoveAll(workspace)
+	templates := newWorkspace(t)
+	defer os.RemoveAll(templates)
+	templateFile := filepath.Join(templates, "app.yaml.tmpl")
+	assert.NoError(t, ioutil.WriteFile(templateFile, []byte("url: {{ .DATABASE_URL }}\n"), 0644))
+
+	tables := []struct {
+		variables string
+		content   string
+		err       string
+	}{
+		{"  PATH: app.yaml\n  TEMPLATE: 'name: {{ .NAME }}, port: {{ .PORT }}'\n  DATA: {PORT: 8080}", "name: e2e, port: 8080", ""},
+		{"  PATH: app.yaml\n  TEMPLATE: '{{ range .HOSTS }}{{ . }};{{ end }}'\n  DATA: {HOSTS: [a, b]}", "a;b;", ""},
+		{"  PATH: app.yaml\n  TEMPLATE: '{{ .NAME }}'\n  DATA: {NAME: overridden}", "overridden", ""},
+		{fmt.Sprintf("  PATH: app.yaml\n  TEMPLATE_FILE: %s", templateFile), "url: postgres://localhost\n", ""},
+		{"  PATH: app.yaml\n  TEMPLATE: '{{ .MISSING }}'", "", "Could not render template 'TEMPLATE'"},
+		{"  PATH: app.yaml\n  TEMPLATE: '{{ .NAME '", "", "Could not read template 'TEMPLATE'"},
+		{"  PATH: app.yaml\n  TEMPLATE: '{{ .NAME }}'\n  DATA: [a]", "", "Variable 'DATA' of step 'Render template' must be a map"},
+		{"  PATH: app.yaml", "", "Step 'Render template' must have 'TEMPLATE' or 'TEMPLATE_FILE'"},
+		{"  PATH: app.yaml\n  TEMPLATE_FILE: missing.tmpl", "", "Could not read template 'missing.tmpl'"},
+		{"  TEMPLATE: '{{ .NAME }}'", "", "Could not find variable 'PATH' in step.variables"},
+	}
+
+	for _, table := range tables {
+		step := newFileStep(t, workspace, "Render template", table.variables)
+		step.SetRunVariables(map[string]string{"NAME": "e2e", "DATABASE_URL": "postgres://localhost"})
+		err := RenderTemplate(step)
+		if table.err != "" {
+			if assert.Error(t, err, table.variables) {
+				assert.Contains(t, err.Error(), table.err)
+			}
+			assert.False(t, step.HasSucceeded())
+			continue
+		}
+		assert.NoError(t, err, table.variables)
+		content, err := ioutil.ReadFile(filepath.Join(workspace, "app.yaml"))
+		assert.NoError(t, err)
+		assert.Equal(t, table.content, string(content))
+	}
+}
+
+func TestCompareFileToGolden(t *testing.T) {
+	workspace := newWorkspace(t)
+	defer os.RemoveAll(workspace)
+	goldens := newWorkspace(t)
+	defer os.RemoveAll(goldens)
+	golden := filepath.Join(goldens, "app.golden")
+	assert.NoError(t, ioutil.WriteFile(golden, []byte("name: e2e\nport: 8080\n"), 0644))
+	assert.NoError(t, ioutil.WriteFile(filepath.Join(workspace, "same.yaml"), []byte("name: e2e\nport: 8080\n"), 0644))
+	assert.NoError(t, ioutil.WriteFile(filepath.Join(workspace, "different.yaml"), []byte("name: e2e\nport: 9090\n"), 0644))
+
+	tables := []struct {
+		variables string
+		err       string
+	}{
+		{fmt.Sprintf("  PATH: same.yaml\n  GOLDEN_FILE: %s", golden), ""},
+		{fmt.Sprintf("  PATH: different.yaml\n  GOLDEN_FILE: %s", golden), fmt.Sprintf("File '%s' does not match golden file '%s':\n--- %s\n+++ %s\n@@ -1,2 +1,2 @@\n name: e2e\n-port: 8080\n+port: 9090\n",
+			filepath.Join(workspace, "different.yaml"), golden, golden, filepath.Join(workspace, "different.yaml"))},
+		{fmt.Sprintf("  PATH: missing.yaml\n  GOLDEN_FILE: %s", golden), "Could not read file"},
+		{"  PATH: same.yaml\n  GOLDEN_FILE: missing.golden", "Could not read golden file 'missing.golden'"},
+		{"  PATH: same.yaml", "Could not find variable 'GOLDEN_FILE' in step.variables"},
+		{fmt.Sprintf("  PATH: same.yaml\n  GOLDEN_FILE: %s\n  UPDATE_GOLDEN: maybe", golden), "maybe"},
+	}
+
+	for _, table := range tables {
+		step := newFileStep(t, workspace, "Compare file to golden", table.variables)
+		err := CompareFileToGolden(step)
+		if table.err != "" {
+			if assert.Error(t, err, table.variables) {
+				assert.Contains(t, err.Error(), table.err)
+			}
+			assert.False(t, step.HasSucceeded())
+			continue
+		}
+		assert.NoError(t, err, table.variables)
+		assert.True(t, step.HasSucceeded())
+	}
+}
+
+func TestCompareFileToGoldenUpdatesGolden(t *testing.T) {
+	workspace := newWorkspace(t)
+	defer os.RemoveAll(workspace)
+	golden := filepath.Join(workspace, "app.golden")
+	assert.NoError(t, ioutil.WriteFile(filepath.Join(workspace, "app.yaml"), []byte("port: 9090\n"), 0644))
+
+	step := newFileStep(t, workspace, "Compare file to golden", fmt.Sprintf("  PATH: app.yaml\n  GOLDEN_FILE: %s\n  UPDATE_GOLDEN: true", golden))
+	assert.NoError(t, CompareFileToGolden(step))
+	content, err := ioutil.ReadFile(golden)
+	assert.NoError(t, err)
+	assert.Equal(t, "port: 9090\n", string(content))
+
+	step = newFileStep(t, workspace, "Compare file to golden", fmt.Sprintf("  PATH: app.yaml\n  GOLDEN_FILE: %s\n  UPDATE_GOLDEN: true", filepath.Join(golden, "app.golden")))
+	err = CompareFileToGolden(step)
+	if assert.Error(t, err) {
+		assert.Contains(t, err.Error(), "Could not update golden file")
+	}
+}
+
+func TestAssertFile(t *testing.T) {
+	workspace := newWorkspace(t)
+	defer os.RemoveAll(workspace)
+	path := filepath.Join(workspace, "app.yaml")
+	assert.NoError(t, ioutil.WriteFile(path, []byte("name: e2e\nport: 8080\n"), 0600))
+	assert.NoError(t, os.Chmod(path, 0600))
+
+	tables := []struct {
+		variables string
+		err       string
+	}{
+		{"  PATH: app.yaml", ""},
+		{"  PATH: app.yaml\n  MODE: 0600\n  CONTAINS: [name, 'port: 8080']\n  MATCHES: 'port: \\d+'", ""},
+		{"  PATH: missing.yaml\n  EXISTS: false", ""},
+		{"  PATH: app.yaml\n  EXISTS: false", fmt.Sprintf("File '%s' exists but expected it not to", path)},
+		{"  PATH: missing.yaml", "does not exist"},
+		{"  PATH: app.yaml\n  MODE: '0644'\n  CONTAINS: 'port: 9090'\n  MATCHES: '^port'", fmt.Sprintf("File '%s' did not match: mode was 0600 but expected 0644; contents do not contain 'port: 9090'; contents do not match '^port'", path)},
+		{"  PATH: app.yaml\n  MODE: rw", "Variable 'MODE' of step 'Assert file' must be an octal file mode"},
+		{"  PATH: app.yaml\n  MATCHES: '('", "("},
+		{"  PATH: app.yaml\n  EXISTS: maybe", "maybe"},
+		{"  PATH: ../app.yaml", "must be inside the workspace"},
+		{"  PATH: .\n  CONTAINS: text", "Could not read file"},
+	}
+
+	for _, table := range tables {
+		step := newFileStep(t, workspace, "Assert file", table.variables)
+		err := AssertFile(step)
+		if table.err != "" {
+			if assert.Error(t, err, table.variables) {
+				assert.Contains(t, err.Error(), table.err)
+			}
+			assert.False(t, step.HasSucceeded())
+			continue
+		}
+		assert.NoError(t, err, table.variables)
+		assert.True(t, step.HasSucceeded())
+	}
+}
+
+func TestDeleteFile(t *testing.T) {
+	workspace := newWorkspace(t)
+	defer os.RemoveAll(workspace)
+	assert.NoError(t, os.MkdirAll(filepath.Join(workspace, "configs"), 0755))
+	assert.NoError(t, ioutil.WriteFile(filepath.Join(workspace, "configs", "app.yaml"), []byte{}, 0644))
+	assert.NoError(t, ioutil.WriteFile(filepath.Join(workspace, "app.yaml"), []byte{}, 0644))
+
+	for _, path := range []string{"app.yaml", "configs", "missing.yaml"} {
+		step := newFileStep(t, workspace, "Delete file", "  PATH: "+path)
+		assert.NoError(t, DeleteFile(step), path)
+		_, err := os.Stat(filepath.Join(workspace, path))
+		assert.True(t, os.IsNotExist(err))
+	}
+
+	step := newFileStep(t, workspace, "Delete file", "  PATH: .")
+	assert.EqualError(t, DeleteFile(step), "Step 'Delete file' can not delete the workspace itself")
+	assert.DirExists(t, workspace)
+	step = newFileStep(t, workspace, "Delete file", "  PATH: ../other")
+	assert.Error(t, DeleteFile(step))
+}
+
+func TestEncodeFileContent(t *testing.T) {
+	content, err := encodeFileContent("list.json", []interface{}{map[string]interface{}{"a": 1}})
+	assert.NoError(t, err)
+	assert.Equal(t, "[\n  {\n    \"a\": 1\n  }\n]\n", string(content))
+
+	_, err = encodeFileContent("bad.json", map[string]interface{}{"a": func() {}})
+	assert.Error(t, err)
+}
@@ -0,0 +1,283 @@
+package operations
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+	"unicode"
+
+	"github.com/golang/protobuf/jsonpb"
+	"github.com/jhump/protoreflect/desc"
+	"github.com/jhump/protoreflect/desc/protoparse"
+	"github.com/jhump/protoreflect/dynamic"
+	"github.com/jhump/protoreflect/dynamic/grpcdynamic"
+	"github.com/jhump/protoreflect/grpcreflect"
+	"github.com/julianGoh17/simple-e2e/framework/models"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/credentials"
+	"google.golang.org/grpc/metadata"
+	rpb "google.golang.org/grpc/reflection/grpc_reflection_v1alpha"
+	"google.golang.org/grpc/status"
+)
+
+const defaultGRPCTimeout = 30 * time.Second
+
+// SendGRPCRequest will call a unary gRPC method with a JSON request and check the status of the response. The types of the request and response
+// are found with the server's reflection service, or in '.proto' files when 'PROTO_FILES' is set. The response is saved as JSON in the output
+// 'body', its status as 'status' (such as 'OK' or 'NOT_FOUND'), 'code' and 'message', and its metadata as 'headers.<name>' and 'trailers.<name>'.
+// Environmental Variables:
+//   - ADDRESS: The address of the server, such as 'localhost:50051'
+//   - METHOD: The full name of the method, such as 'weather.v1.WeatherService/GetForecast'
+//   - BODY: The request, written in the test as JSON or as a YAML map (default '{}')
+//   - METADATA: A map of metadata to send with the request
+//   - PROTO_FILES: '.proto' files with the service, relative to the import paths, as a list or separated by commas
+//   - IMPORT_PATHS: The directories which '.proto' files and their imports are found in, relative to the test directory, as a list or separated by
+//     commas (default the test directory)
+//   - TIMEOUT: How long to wait for the response (default 30s)
+//   - TLS: Whether to connect with TLS, which is also used when any of the TLS options are set (default false)
+//   - INSECURE_SKIP_VERIFY: Whether to accept any TLS certificate from the server (default false)
+//   - CA_CERT: A PEM file of the certificate authorities to trust, relative to the test directory
+//   - CLIENT_CERT and CLIENT_KEY: PEM files of the client certificate and its key, relative to the test directory
+//   - EXPECTED_STATUS: The statuses the response can have, such as 'NOT_FOUND' or '5', as a list or separated by commas (default 'OK')
+func SendGRPCRequest(step *models.Step) error {
+	traceStepEntrance(step)
+	if err := step.CheckIfStepVariablesExists("ADDRESS", "METHOD"); err != nil {
+		return traceStepExit(step, err)
+	}
+	address, _ := step.GetValueFromVariablesAsString("ADDRESS")
+	methodName, _ := step.GetValueFromVariablesAsString("METHOD")
+	serviceName, shortName, err := splitGRPCMethod(methodName)
+	if err != nil {
+		return traceStepExit(step, err)
+	}
+	timeout, err := step.GetValueFromVariablesAsDurationOrDefault("TIMEOUT", defaultGRPCTimeout)
+	if err != nil {
+		return traceStepExit(step, err)
+	}
+	expected, err := step.GetValueFromVariablesAsStringArrayOrDefault("EXPECTED_STATUS", []string{codes.OK.String()})
+	if err != nil {
+		return traceStepExit(step, err)
+	}
+	options, err := getGRPCDialOptions(step)
+	if err != nil {
+		return traceStepExit(step, err)
+	}
+
+	ctx, cancel := context.WithTimeout(step.GetContext(), timeout)
+	defer cancel()
+	conn, err := grpc.DialContext(ctx, address, options...)
+	if err != nil {
+		return traceStepExit(step, fmt.Errorf("Could not connect to gRPC server '%s': %v", address, err))
+	}
+	defer conn.Close()
+
+	method, err := findGRPCMethod(ctx, step, conn, serviceName, shortName)
+	if err != nil {
+		return traceStepExit(step, err)
+	}
+	request, err := newGRPCRequest(step, method)
+	if err != nil {
+		return traceStepExit(step, err)
+	}
+	ctx, err = addGRPCMetadata(ctx, step)
+	if err != nil {
+		return traceStepExit(step, err)
+	}
+
+	var headers, trailers metadata.MD
+	start := time.Now()
+	response, err := grpcdynamic.NewStub(conn).InvokeRpc(ctx, method, request, grpc.Header(&headers), grpc.Trailer(&trailers))
+	result := status.Convert(err)
+	logger.Info().
+		Str("address", address).
+		Str("method", method.GetFullyQualifiedName()).
+		Str("status", result.Code().String()).
+		Dur("duration", time.Since(start)).
+		Msg("Received gRPC response")
+
+	step.SetOutput("status", getGRPCStatusName(result.Code()))
+	step.SetOutput("code", strconv.Itoa(int(result.Code())))
+	step.SetOutput("message", result.Message())
+	setGRPCMetadataOutputs(step, "headers.", headers)
+	setGRPCMetadataOutputs(step, "trailers.", trailers)
+	if err == nil {
+		body, err := formatGRPCResponse(response)
+		if err != nil {
+			return traceStepExit(step, err)
+		}
+		step.SetOutput("body", body)
+	}
+
+	if !matchesGRPCStatus(result.Code(), expected) {
+		return traceStepExit(step, fmt.Errorf("Response from gRPC method '%s' had status %s but expected %s: %s", methodName,
+			getGRPCStatusName(result.Code()), strings.Join(expected, " or "), result.Message()))
+	}
+	return traceStepExit(step, nil)
+}
+
+// splitGRPCMethod returns the service and method of a full method name, which can be written as 'package.Service/Method' or 'package.Service.Method'
+func splitGRPCMethod(name string) (string, string, error) {
+	trimmed := strings.TrimPrefix(strings.TrimSpace(name), "/")
+	separator := strings.LastIndex(trimmed, "/")
+	if separator < 0 {
+		separator = strings.LastIndex(trimmed, ".")
+	}
+	if separator <= 0 || separator == len(trimmed)-1 {
+		return "", "", fmt.Errorf("Method '%s' must be a full method name such as 'package.Service/Method'", name)
+	}
+	return trimmed[:separator], trimmed[separator+1:], nil
+}
+
+// getGRPCDialOptions returns the credentials of the connection, which only uses TLS when 'TLS' or any of the TLS options are set
+func getGRPCDialOptions(step *models.Step) ([]grpc.DialOption, error) {
+	useTLS, err := step.GetValueFromVariablesAsBooleanOrDefault("TLS", false)
+	if err != nil {
+		return nil, err
+	}
+	for _, option := range []string{"INSECURE_SKIP_VERIFY", "CA_CERT", "CLIENT_CERT", "CLIENT_KEY"} {
+		if _, ok := step.Variables[option]; ok {
+			useTLS = true
+		}
+	}
+	if !useTLS {
+		return []grpc.DialOption{grpc.WithInsecure()}, nil
+	}
+	tlsConfig, err := getTLSConfig(step)
+	if err != nil {
+		return nil, err
+	}
+	return []grpc.DialOption{grpc.WithTransportCredentials(credentials.NewTLS(tlsConfig))}, nil
+}
+
+// findGRPCMethod returns the descriptor of the method from the '.proto' files of the step, or from the server's reflection service when the step
+// does not have any
+func findGRPCMethod(ctx context.Context, step *models.Step, conn *grpc.ClientConn, serviceName, methodName string) (*desc.MethodDescriptor, error) {
+	files, err := step.GetValueFromVariablesAsStringArrayOrDefault("PROTO_FILES", []string{})
+	if err != nil {
+		return nil, err
+	}
+	var service *desc.ServiceDescriptor
+	if len(files) == 0 {
+		client := grpcreflect.NewClient(ctx, rpb.NewServerReflectionClient(conn))
+		defer client.Reset()
+		if service, err = client.ResolveService(serviceName); err != nil {
+			return nil, fmt.Errorf("Could not find service '%s' with server reflection: %v", serviceName, err)
+		}
+	} else if service, err = findProtoService(step, files, serviceName); err != nil {
+		return nil, err
+	}
+
+	method := service.FindMethodByName(methodName)
+	if method == nil {
+		return nil, fmt.Errorf("Service '%s' does not have a method '%s'", serviceName, methodName)
+	}
+	if method.IsClientStreaming() || method.IsServerStreaming() {
+		return nil, fmt.Errorf("Method '%s' of service '%s' must be unary as streaming methods are not supported", methodName, serviceName)
+	}
+	return method, nil
+}
+
+// findProtoService parses the '.proto' files and returns the service with the name from them
+func findProtoService(step *models.Step, files []string, serviceName string) (*desc.ServiceDescriptor, error) {
+	for index := range files {
+		files[index] = strings.TrimSpace(files[index])
+	}
+	importPaths, err := step.GetValueFromVariablesAsStringArrayOrDefault("IMPORT_PATHS", []string{"."})
+	if err != nil {
+		return nil, err
+	}
+	for index, path := range importPaths {
+		importPaths[index] = getTestDirPath(strings.TrimSpace(path))
+	}
+
+	parser := protoparse.Parser{ImportPaths: importPaths}
+	descriptors, err := parser.ParseFiles(files...)
+	if err != nil {
+		return nil, fmt.Errorf("Could not parse proto files [%s]: %v", strings.Join(files, ", "), err)
+	}
+	for _, descriptor := range descriptors {
+		if service := descriptor.FindService(serviceName); service != nil {
+			return service, nil
+		}
+	}
+	return nil, fmt.Errorf("Could not find service '%s' in proto files [%s]", serviceName, strings.Join(files, ", "))
+}
+
+// newGRPCRequest returns the request message of the method with the fields in 'BODY'
+func newGRPCRequest(step *models.Step, method *desc.MethodDescriptor) (*dynamic.Message, error) {
+	body := []byte("{}")
+	if value, err := step.GetValueFromVariables("BODY"); err == nil {
+		if text, isString := value.(string); isString {
+			body = []byte(text)
+		} else if body, err = json.Marshal(value); err != nil {
+			return nil, fmt.Errorf("Could not convert variable 'BODY' to JSON: %v", err)
+		}
+	}
+	request := dynamic.NewMessage(method.GetInputType())
+	if err := request.UnmarshalJSON(body); err != nil {
+		return nil, fmt.Errorf("Could not convert 'BODY' to message '%s': %v", method.GetInputType().GetFullyQualifiedName(), err)
+	}
+	return request, nil
+}
+
+// addGRPCMetadata returns the context with the metadata in 'METADATA' to send with the request
+func addGRPCMetadata(ctx context.Context, step *models.Step) (context.Context, error) {
+	values, err := step.GetValueFromVariablesAsMapOrDefault("METADATA", map[string]string{})
+	if err != nil {
+		return nil, err
+	}
+	if len(values) == 0 {
+		return ctx, nil
+	}
+	return metadata.NewOutgoingContext(ctx, metadata.New(values)), nil
+}
+
+// formatGRPCResponse returns the response as JSON with every field, including the fields which have their default value
+func formatGRPCResponse(response interface{}) (string, error) {
+	message, ok := response.(*dynamic.Message)
+	if !ok {
+		return "", fmt.Errorf("Could not read gRPC response of type '%T'", response)
+	}
+	body, err := message.MarshalJSONPB(&jsonpb.Marshaler{EmitDefaults: true})
+	if err != nil {
+		return "", fmt.Errorf("Could not convert gRPC response to JSON: %v", err)
+	}
+	return string(body), nil
+}
+
+// setGRPCMetadataOutputs saves each value of the metadata as an output with the prefix, joining values of the same key with commas
+func setGRPCMetadataOutputs(step *models.Step, prefix string, values metadata.MD) {
+	for key, value := range values {
+		step.SetOutput(prefix+key, strings.Join(value, ", "))
+	}
+}
+
+// getGRPCStatusName returns the name of the status code as it is written in the gRPC specification, such as 'NOT_FOUND'
+func getGRPCStatusName(code codes.Code) string {
+	var name strings.Builder
+	previous := ' '
+	for _, character := range code.String() {
+		if unicode.IsUpper(character) && unicode.IsLower(previous) {
+			name.WriteRune('_')
+		}
+		name.WriteRune(unicode.ToUpper(character))
+		previous = character
+	}
+	return name.String()
+}
+
+// matchesGRPCStatus returns whether the status code matches any of the statuses, which are either a number such as '5' or a name such as
+// 'NOT_FOUND' or 'NotFound'
+func matchesGRPCStatus(code codes.Code, statuses []string) bool {
+	for _, expected := range statuses {
+		expected = strings.TrimSpace(expected)
+		if expected == strconv.Itoa(int(code)) || strings.EqualFold(expected, code.String()) || strings.EqualFold(expected, getGRPCStatusName(code)) {
+			return true
+		}
+	}
+	return false
+}
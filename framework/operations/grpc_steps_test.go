@@ -0,0 +1,267 @@
+package operations
+
+import (
+	"bytes"
+	"compress/gzip"
+	"context"
+	"fmt"
+	"io/ioutil"
+	"net"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/golang/protobuf/proto"
+	"github.com/jhump/protoreflect/desc"
+	"github.com/jhump/protoreflect/desc/protoparse"
+	"github.com/jhump/protoreflect/dynamic"
+	"github.com/julianGoh17/simple-e2e/framework/util"
+	"github.com/stretchr/testify/assert"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/reflection"
+	"google.golang.org/grpc/status"
+)
+
+const weatherProto = `syntax = "proto3";
+
+package weather.v1;
+
+message ForecastRequest {
+  string city = 1;
+  int32 days = 2;
+}
+
+message Forecast {
+  string city = 1;
+  repeated double temperatures = 2;
+  bool sunny = 3;
+}
+
+service WeatherService {
+  rpc GetForecast(ForecastRequest) returns (Forecast);
+  rpc WatchForecast(ForecastRequest) returns (stream Forecast);
+}
+`
+
+// startWeatherServer starts a gRPC server of the weather service in the '.proto' file in the directory, with server reflection when reflect is
+// true. It returns the address of the server and a function which stops it.
+func startWeatherServer(t *testing.T, dir string, reflect bool) (string, func()) {
+	files, err := protoparse.Parser{ImportPaths: []string{dir}}.ParseFiles("weather.proto")
+	if !assert.NoError(t, err) {
+		t.FailNow()
+	}
+	service := files[0].FindService("weather.v1.WeatherService")
+	forecast := service.FindMethodByName("GetForecast")
+
+	server := grpc.NewServer()
+	server.RegisterService(&grpc.ServiceDesc{
+		ServiceName: service.GetFullyQualifiedName(),
+		HandlerType: (*interface{})(nil),
+		Methods: []grpc.MethodDesc{{
+			MethodName: "GetForecast",
+			Handler: func(_ interface{}, ctx context.Context, decode func(interface{}) error, _ grpc.UnaryServerInterceptor) (interface{}, error) {
+				return getForecast(ctx, forecast, decode)
+			},
+		}},
+		Metadata: encodeFileDescriptor(t, files[0]),
+	}, struct{}{})
+	if reflect {
+		reflection.Register(server)
+	}
+
+	listener, err := net.Listen("tcp", "127.0.0.1:0")
+	assert.NoError(t, err)
+	go server.Serve(listener)
+	return listener.Addr().String(), server.Stop
+}
+
+// getForecast responds with a forecast for the city of the request, or NOT_FOUND for 'Atlantis'. The 'authorization' metadata of the request is
+// sent back as the 'x-authorization' header.
+func getForecast(ctx context.Context, method *desc.MethodDescriptor, decode func(interface{}) error) (interface{}, error) {
+	request := dynamic.NewMessage(method.GetInputType())
+	if err := decode(request); err != nil {
+		return nil, err
+	}
+	city := request.GetFieldByName("city").(string)
+	if city == "Atlantis" {
+		return nil, status.Error(codes.NotFound, "no forecast for Atlantis")
+	}
+	if incoming, ok := metadata.FromIncomingContext(ctx); ok && len(incoming.Get("authorization")) > 0 {
+		grpc.SetHeader(ctx, metadata.Pairs("x-authorization", incoming.Get("authorization")[0]))
+	}
+	grpc.SetTrailer(ctx, metadata.Pairs("x-days", fmt.Sprint(request.GetFieldByName("days"))))
+
+	response := dynamic.NewMessage(method.GetOutputType())
+	response.SetFieldByName("city", city)
+	response.SetFieldByName("temperatures", []float64{21.5, 19})
+	return response, nil
+}
+
+// encodeFileDescriptor returns the file descriptor as gzipped bytes, which the reflection service reads from the metadata of a service
+func encodeFileDescriptor(t *testing.T, file *desc.FileDescriptor) []byte {
+	body, err := proto.Marshal(file.AsFileDescriptorProto())
+	assert.NoError(t, err)
+	var compressed bytes.Buffer
+	writer := gzip.NewWriter(&compressed)
+	_, err = writer.Write(body)
+	assert.NoError(t, err)
+	assert.NoError(t, writer.Close())
+	return compressed.Bytes()
+}
+
+// setGRPCTestDir will set 'TEST_DIR' to a new directory with 'weather.proto' in it and return it with a function which deletes it and restores
+// 'TEST_DIR'
+func setGRPCTestDir(t *testing.T) (string, func()) {
+	dir, err := ioutil.TempDir("", "simple-e2e-grpc")
+	assert.NoError(t, err)
+	assert.NoError(t, ioutil.WriteFile(filepath.Join(dir, "weather.proto"), []byte(weatherProto), 0644))
+	previous := os.Getenv(util.TestDirEnv)
+	os.Setenv(util.TestDirEnv, dir)
+	return dir, func() {
+		os.Setenv(util.TestDirEnv, previous)
+		os.RemoveAll(dir)
+	}
+}
+
+func TestSendGRPCRequest(t *testing.T) {
+	dir, restore := setGRPCTestDir(t)
+	defer restore()
+	reflectAddress, stopReflect := startWeatherServer(t, dir, true)
+	defer stopReflect()
+	address, stop := startWeatherServer(t, dir, false)
+	defer stop()
+
+	tables := []struct {
+		variables string
+		outputs   map[string]string
+		err       string
+	}{
+		{
+			fmt.Sprintf("  ADDRESS: %s\n  METHOD: weather.v1.WeatherService/GetForecast\n  BODY: {city: Toronto, days: 3}\n  METADATA: {authorization: Bearer abc}", reflectAddress),
+			map[string]string{
+				"body":                    `{"city":"Toronto","temperatures":[21.5,19],"sunny":false}`,
+				"status":                  "OK",
+				"code":                    "0",
+				"message":                 "",
+				"headers.x-authorization": "Bearer abc",
+				"trailers.x-days":         "3",
+			},
+			"",
+		},
+		{
+			fmt.Sprintf("  ADDRESS: %s\n  METHOD: /weather.v1.WeatherService.GetForecast\n  BODY: '{\"city\": \"Ottawa\"}'\n  PROTO_FILES: [weather.proto]", address),
+			map[string]string{"body": `{"city":"Ottawa","temperatures":[21.5,19],"sunny":false}`, "status": "OK", "trailers.x-days": "0"},
+			"",
+		},
+		{
+			fmt.Sprintf("  ADDRESS: %s\n  METHOD: weather.v1.WeatherService/GetForecast\n  PROTO_FILES: weather.proto\n  IMPORT_PATHS: 'protos, .'", address),
+			map[string]string{"body": `{"city":"","temperatures":[21.5,19],"sunny":false}`},
+			"",
+		},
+		{
+			fmt.Sprintf("  ADDRESS: %s\n  METHOD: weather.v1.WeatherService/GetForecast\n  BODY: {city: Atlantis}\n  EXPECTED_STATUS: [NOT_FOUND, Unavailable]", reflectAddress),
+			map[string]string{"status": "NOT_FOUND", "code": "5", "message": "no forecast for Atlantis"},
+			"",
+		},
+		{
+			fmt.Sprintf("  ADDRESS: %s\n  METHOD: weather.v1.WeatherService/GetForecast\n  BODY: {city: Atlantis}", reflectAddress),
+			map[string]string{"status": "NOT_FOUND", "code": "5"},
+			"Response from gRPC method 'weather.v1.WeatherService/GetForecast' had status NOT_FOUND but expected OK: no forecast for Atlantis",
+		},
+		{
+			fmt.Sprintf("  ADDRESS: %s\n  METHOD: weather.v1.WeatherService/GetForecast", address),
+			map[string]string{},
+			"Could not find service 'weather.v1.WeatherService' with server reflection: ",
+		},
+		{
+			fmt.Sprintf("  ADDRESS: %s\n  METHOD: weather.v1.WeatherService/GetForecast\n  BODY: {town: Toronto}", reflectAddress),
+			map[string]string{},
+			"Could not convert 'BODY' to message 'weather.v1.ForecastRequest': ",
+		},
+		{
+			fmt.Sprintf("  ADDRESS: %s\n  METHOD: weather.v1.WeatherService/GetHistory", reflectAddress),
+			map[string]string{},
+			"Service 'weather.v1.WeatherService' does not have a method 'GetHistory'",
+		},
+		{
+			fmt.Sprintf("  ADDRESS: %s\n  METHOD: weather.v1.WeatherService/WatchForecast\n  PROTO_FILES: [weather.proto]", address),
+			map[string]string{},
+			"Method 'WatchForecast' of service 'weather.v1.WeatherService' must be unary as streaming methods are not supported",
+		},
+		{
+			fmt.Sprintf("  ADDRESS: %s\n  METHOD: weather.v1.ClimateService/GetForecast\n  PROTO_FILES: [weather.proto]", address),
+			map[string]string{},
+			"Could not find service 'weather.v1.ClimateService' in proto files [weather.proto]",
+		},
+		{
+			fmt.Sprintf("  ADDRESS: %s\n  METHOD: weather.v1.WeatherService/GetForecast\n  PROTO_FILES: [climate.proto]", address),
+			map[string]string{},
+			"Could not parse proto files [climate.proto]: ",
+		},
+	}
+
+	for _, table := range tables {
+		step := newStep(t, "Send gRPC request", table.variables)
+		err := SendGRPCRequest(step)
+		if table.err != "" {
+			if assert.Error(t, err, table.variables) {
+				assert.Contains(t, err.Error(), table.err)
+			}
+			assert.False(t, step.HasSucceeded())
+		} else {
+			assert.NoError(t, err, table.variables)
+			assert.True(t, step.HasSucceeded())
+		}
+		for name, value := range table.outputs {
+			assert.Equal(t, value, step.GetOutputs()[name], "%s of %s", name, table.variables)
+		}
+	}
+}
+
+func TestSendGRPCRequestFails(t *testing.T) {
+	tables := []struct {
+		variables string
+		err       string
+	}{
+		{"  METHOD: weather.v1.WeatherService/GetForecast", "Could not find variable 'ADDRESS' in step.variables"},
+		{"  ADDRESS: localhost:1", "Could not find variable 'METHOD' in step.variables"},
+		{"  ADDRESS: localhost:1\n  METHOD: GetForecast", "Method 'GetForecast' must be a full method name such as 'package.Service/Method'"},
+		{"  ADDRESS: localhost:1\n  METHOD: weather.v1.WeatherService/", "Method 'weather.v1.WeatherService/' must be a full method name"},
+		{"  ADDRESS: localhost:1\n  METHOD: a.B/C\n  TIMEOUT: soon", "Could not convert 'soon' to type 'time.Duration'"},
+		{"  ADDRESS: localhost:1\n  METHOD: a.B/C\n  TLS: maybe", "Could not convert 'maybe' to type 'bool'"},
+		{"  ADDRESS: localhost:1\n  METHOD: a.B/C\n  CA_CERT: missing.pem", "Could not read CA certificate: "},
+		{"  ADDRESS: 127.0.0.1:1\n  METHOD: a.B/C\n  TIMEOUT: 1s", "Could not find service 'a.B' with server reflection: "},
+	}
+
+	for _, table := range tables {
+		step := newStep(t, "Send gRPC request", table.variables)
+		err := SendGRPCRequest(step)
+		if assert.Error(t, err, table.variables) {
+			assert.Contains(t, err.Error(), table.err)
+		}
+		assert.False(t, step.HasSucceeded())
+	}
+}
+
+func TestGRPCStatuses(t *testing.T) {
+	tables := []struct {
+		code     codes.Code
+		name     string
+		expected []string
+		matches  bool
+	}{
+		{codes.OK, "OK", []string{"OK"}, true},
+		{codes.NotFound, "NOT_FOUND", []string{"not_found"}, true},
+		{codes.NotFound, "NOT_FOUND", []string{"NotFound"}, true},
+		{codes.DeadlineExceeded, "DEADLINE_EXCEEDED", []string{"OK", " 4 "}, true},
+		{codes.Unavailable, "UNAVAILABLE", []string{"OK", "UNKNOWN"}, false},
+		{codes.ResourceExhausted, "RESOURCE_EXHAUSTED", []string{"8"}, true},
+	}
+
+	for _, table := range tables {
+		assert.Equal(t, table.name, getGRPCStatusName(table.code))
+		assert.Equal(t, table.matches, matchesGRPCStatus(table.code, table.expected), table.name)
+	}
+}
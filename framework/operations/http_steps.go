@@ -0,0 +1,282 @@
+package operations
+
+import (
+	"bytes"
+	"crypto/tls"
+	"crypto/x509"
+	"encoding/json"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"net/http"
+	"reflect"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/julianGoh17/simple-e2e/framework/models"
+)
+
+const defaultHTTPTimeout = 30 * time.Second
+
+// SendHTTPRequest will send an HTTP request and check the response. The status code, headers and body of the response are saved as the outputs
+// 'status', 'headers.<Header-Name>' and 'body'.
+// Environmental Variables:
+//   - URL: The URL to send the request to, which uses 'http://' if it does not have a scheme
+//   - METHOD: The method of the request (default GET)
+//   - HEADERS: A map of headers to send with the request
+//   - BODY: The body of the request. A YAML map or list is sent as JSON with the 'application/json' content type
+//   - TIMEOUT: How long to wait for the response (default 30s)
+//   - INSECURE_SKIP_VERIFY: Whether to accept any TLS certificate from the server (default false)
+//   - CA_CERT: A PEM file of the certificate authorities to trust, relative to the test directory
+//   - CLIENT_CERT and CLIENT_KEY: PEM files of the client certificate and its key, relative to the test directory
+//   - EXPECTED_STATUS: The status codes the response can have, such as '201' or '2xx', as a list or separated by commas
+//   - EXPECTED_HEADERS: A map of headers the response must have with the given values
+//   - EXPECTED_BODY: The body the response must have. JSON bodies are compared as JSON so formatting and key order do not matter
+//   - BODY_CONTAINS: Text, or a list of text, which the body must contain
+//   - BODY_MATCHES: A regular expression which the body must match
+//   - EXPECTED_JSON: A map of JSON paths, such as '$.items[0].name', to the values the JSON body must have at them
+func SendHTTPRequest(step *models.Step) error {
+	traceStepEntrance(step)
+
+	request, err := newHTTPRequest(step)
+	if err != nil {
+		return traceStepExit(step, err)
+	}
+	client, err := newHTTPClient(step)
+	if err != nil {
+		return traceStepExit(step, err)
+	}
+
+	start := time.Now()
+	response, err := client.Do(request)
+	if err != nil {
+		return traceStepExit(step, fmt.Errorf("Could not send %s request to '%s': %v", request.Method, request.URL, err))
+	}
+	defer response.Body.Close()
+	body, err := ioutil.ReadAll(response.Body)
+	if err != nil {
+		return traceStepExit(step, fmt.Errorf("Could not read response from '%s': %v", request.URL, err))
+	}
+	logger.Info().
+		Str("method", request.Method).
+		Str("url", request.URL.String()).
+		Int("status", response.StatusCode).
+		Dur("duration", time.Since(start)).
+		Msg("Received HTTP response")
+
+	step.SetOutput("status", strconv.Itoa(response.StatusCode))
+	step.SetOutput("body", string(body))
+	for name, values := range response.Header {
+		step.SetOutput("headers."+name, strings.Join(values, ", "))
+	}
+
+	return traceStepExit(step, checkHTTPResponse(step, request, response, body))
+}
+
+// newHTTPRequest returns the request described by the step variables, which is cancelled with the step
+func newHTTPRequest(step *models.Step) (*http.Request, error) {
+	rawURL, err := step.GetValueFromVariablesAsString("URL")
+	if err != nil {
+		return nil, err
+	}
+	if !strings.Contains(rawURL, "://") {
+		rawURL = "http://" + rawURL
+	}
+	method := strings.ToUpper(step.GetValueFromVariablesAsStringOrDefault("METHOD", http.MethodGet))
+	headers, err := step.GetValueFromVariablesAsMapOrDefault("HEADERS", map[string]string{})
+	if err != nil {
+		return nil, err
+	}
+
+	var body io.Reader
+	isJSON := false
+	if value, err := step.GetValueFromVariables("BODY"); err == nil {
+		text, isString := value.(string)
+		if !isString {
+			encoded, err := json.Marshal(value)
+			if err != nil {
+				return nil, fmt.Errorf("Could not convert variable 'BODY' to JSON: %v", err)
+			}
+			text, isJSON = string(encoded), true
+		}
+		body = bytes.NewBufferString(text)
+	}
+
+	request, err := http.NewRequest(method, rawURL, body)
+	if err != nil {
+		return nil, fmt.Errorf("Could not create %s request to '%s': %v", method, rawURL, err)
+	}
+	for name, value := range headers {
+		request.Header.Set(name, value)
+	}
+	if isJSON && request.Header.Get("Content-Type") == "" {
+		request.Header.Set("Content-Type", "application/json")
+	}
+	return request.WithContext(step.GetContext()), nil
+}
+
+// newHTTPClient returns a client with the timeout and TLS options of the step variables
+func newHTTPClient(step *models.Step) (*http.Client, error) {
+	timeout, err := step.GetValueFromVariablesAsDurationOrDefault("TIMEOUT", defaultHTTPTimeout)
+	if err != nil {
+		return nil, err
+	}
+	tlsConfig, err := getTLSConfig(step)
+	if err != nil {
+		return nil, err
+	}
+
+	return &http.Client{
+		Timeout: timeout,
+		Transport: &http.Transport{
+			Proxy:           http.ProxyFromEnvironment,
+			TLSClientConfig: tlsConfig,
+		},
+	}, nil
+}
+
+// getTLSConfig returns the TLS options of the step variables 'INSECURE_SKIP_VERIFY', 'CA_CERT', 'CLIENT_CERT' and 'CLIENT_KEY'
+func getTLSConfig(step *models.Step) (*tls.Config, error) {
+	insecure, err := step.GetValueFromVariablesAsBooleanOrDefault("INSECURE_SKIP_VERIFY", false)
+	if err != nil {
+		return nil, err
+	}
+
+	tlsConfig := &tls.Config{InsecureSkipVerify: insecure}
+	if caCert := step.GetValueFromVariablesAsStringOrDefault("CA_CERT", ""); caCert != "" {
+		pem, err := ioutil.ReadFile(getTestDirPath(caCert))
+		if err != nil {
+			return nil, fmt.Errorf("Could not read CA certificate: %v", err)
+		}
+		pool := x509.NewCertPool()
+		if !pool.AppendCertsFromPEM(pem) {
+			return nil, fmt.Errorf("Could not find any certificates in CA certificate '%s'", caCert)
+		}
+		tlsConfig.RootCAs = pool
+	}
+	clientCert := step.GetValueFromVariablesAsStringOrDefault("CLIENT_CERT", "")
+	clientKey := step.GetValueFromVariablesAsStringOrDefault("CLIENT_KEY", "")
+	if clientCert != "" || clientKey != "" {
+		certificate, err := tls.LoadX509KeyPair(getTestDirPath(clientCert), getTestDirPath(clientKey))
+		if err != nil {
+			return nil, fmt.Errorf("Could not read client certificate: %v", err)
+		}
+		tlsConfig.Certificates = []tls.Certificate{certificate}
+	}
+	return tlsConfig, nil
+}
+
+// checkHTTPResponse returns an error listing every assertion of the step variables which the response does not meet
+func checkHTTPResponse(step *models.Step, request *http.Request, response *http.Response, body []byte) error {
+	failures := []string{}
+
+	statuses, err := step.GetValueFromVariablesAsStringArrayOrDefault("EXPECTED_STATUS", []string{})
+	if err != nil {
+		return err
+	}
+	if len(statuses) > 0 && !matchesStatus(response.StatusCode, statuses) {
+		failures = append(failures, fmt.Sprintf("status was %d but expected %s", response.StatusCode, strings.Join(statuses, " or ")))
+	}
+
+	headers, err := step.GetValueFromVariablesAsMapOrDefault("EXPECTED_HEADERS", map[string]string{})
+	if err != nil {
+		return err
+	}
+	for name, expected := range headers {
+		if actual := response.Header.Get(name); actual != expected {
+			failures = append(failures, fmt.Sprintf("header '%s' was '%s' but expected '%s'", name, actual, expected))
+		}
+	}
+
+	if expected, err := step.GetValueFromVariablesAsString("EXPECTED_BODY"); err == nil && !bodyEquals(body, expected) {
+		failures = append(failures, fmt.Sprintf("body was '%s' but expected '%s'", body, expected))
+	}
+
+	contains, err := getTextList(step, "BODY_CONTAINS")
+	if err != nil {
+		return err
+	}
+	for _, text := range contains {
+		if !strings.Contains(string(body), text) {
+			failures = append(failures, fmt.Sprintf("body '%s' does not contain '%s'", body, text))
+		}
+	}
+
+	if _, ok := step.Variables["BODY_MATCHES"]; ok {
+		pattern, err := step.GetValueFromVariablesAsRegex("BODY_MATCHES")
+		if err != nil {
+			return err
+		}
+		if !pattern.Match(body) {
+			failures = append(failures, fmt.Sprintf("body '%s' does not match '%s'", body, pattern))
+		}
+	}
+
+	paths, err := step.GetValueFromVariablesAsMapOrDefault("EXPECTED_JSON", map[string]string{})
+	if err != nil {
+		return err
+	}
+	if len(paths) > 0 {
+		failures = append(failures, checkJSONPaths(body, paths)...)
+	}
+
+	if len(failures) > 0 {
+		return fmt.Errorf("Response from %s request to '%s' did not match: %s", request.Method, request.URL, strings.Join(failures, "; "))
+	}
+	return nil
+}
+
+// matchesStatus returns whether the status code matches any of the statuses, which are either a code such as '404' or a class such as '2xx'
+func matchesStatus(code int, statuses []string) bool {
+	actual := strconv.Itoa(code)
+	for _, status := range statuses {
+		status = strings.ToLower(strings.TrimSpace(status))
+		if status == actual || strings.HasSuffix(status, "xx") && len(status) == 3 && status[0] == actual[0] {
+			return true
+		}
+	}
+	return false
+}
+
+// bodyEquals returns whether the body equals the expected body, comparing them as JSON when they are both JSON
+func bodyEquals(body []byte, expected string) bool {
+	var actualJSON, expectedJSON interface{}
+	if json.Unmarshal(body, &actualJSON) == nil && json.Unmarshal([]byte(expected), &expectedJSON) == nil {
+		return reflect.DeepEqual(actualJSON, expectedJSON)
+	}
+	return string(body) == expected
+}
+
+// checkJSONPaths returns a failure for every JSON path whose value in the body is not the expected value
+func checkJSONPaths(body []byte, paths map[string]string) []string {
+	var document interface{}
+	if err := json.Unmarshal(body, &document); err != nil {
+		return []string{fmt.Sprintf("body '%s' is not JSON: %v", body, err)}
+	}
+	failures := []string{}
+	for path, expected := range paths {
+		actual, err := getJSONPath(document, path)
+		if err != nil {
+			failures = append(failures, err.Error())
+			continue
+		}
+		if !jsonValueEquals(actual, expected) {
+			failures = append(failures, fmt.Sprintf("JSON path '%s' was '%s' but expected '%s'", path, jsonToString(actual), expected))
+		}
+	}
+	return failures
+}
+
+// getTextList returns the variable as a list when it is a YAML list, or as a list with just the variable otherwise so text with commas is not
+// split
+func getTextList(step *models.Step, variableName string) ([]string, error) {
+	value, err := step.GetValueFromVariables(variableName)
+	if err != nil {
+		return []string{}, nil
+	}
+	if text, isString := value.(string); isString {
+		return []string{text}, nil
+	}
+	return step.GetValueFromVariablesAsStringArray(variableName)
+}
@@ -0,0 +1,168 @@
+package operations
+
+import (
+	"encoding/pem"
+	"fmt"
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func newWeatherServer() *httptest.Server {
+	return httptest.NewServer(http.HandlerFunc(func(writer http.ResponseWriter, request *http.Request) {
+		body, _ := ioutil.ReadAll(request.Body)
+		writer.Header().Set("Content-Type", "application/json")
+		writer.Header().Set("X-Method", request.Method)
+		writer.Header().Set("X-Token", request.Header.Get("Authorization"))
+		writer.Header().Set("X-Request-Content-Type", request.Header.Get("Content-Type"))
+		if request.URL.Path != "/v1/weather" {
+			writer.WriteHeader(http.StatusNotFound)
+		}
+		fmt.Fprintf(writer, `{"city": "Toronto", "temps": [21.5, 19], "request": %q}`, body)
+	}))
+}
+
+func TestSendHTTPRequest(t *testing.T) {
+	server := newWeatherServer()
+	defer server.Close()
+
+	tables := []struct {
+		variables string
+		err       string
+	}{
+		{fmt.Sprintf(`
+  URL: %s/v1/weather
+  EXPECTED_STATUS: 2xx
+  EXPECTED_HEADERS: {Content-Type: application/json, X-Method: GET}
+  EXPECTED_BODY: '{"request": "", "temps": [21.5, 19], "city": "Toronto"}'
+  BODY_CONTAINS: ['"city": "Toronto"', '21.5, 19']
+  BODY_MATCHES: 'Tor.nto'
+  EXPECTED_JSON: {$.city: Toronto, "temps[1]": 19}`, server.URL), ""},
+		{fmt.Sprintf(`
+  URL: %s/v1/weather
+  METHOD: post
+  HEADERS: {Authorization: Bearer abc}
+  BODY: {city: Toronto}
+  EXPECTED_STATUS: [200, 201]
+  EXPECTED_HEADERS: {X-Method: POST, X-Token: Bearer abc, X-Request-Content-Type: application/json}
+  EXPECTED_JSON: {$.request: '{"city":"Toronto"}'}`, server.URL), ""},
+		{fmt.Sprintf(`
+  URL: %s
+  EXPECTED_STATUS: 200`, server.Listener.Addr().String()+"/v1/weather"), ""},
+		{fmt.Sprintf(`
+  URL: %s/random
+  EXPECTED_STATUS: 2xx
+  EXPECTED_HEADERS: {X-Method: POST}
+  EXPECTED_BODY: '{}'
+  BODY_CONTAINS: Ottawa
+  BODY_MATCHES: '^Toronto'
+  EXPECTED_JSON: {$.city: Ottawa, $.random: 1}`, server.URL), fmt.Sprintf("Response from GET request to '%s/random' did not match: ", server.URL)},
+		{`
+  URL: "http://127.0.0.1:0/v1/weather"`, "Could not send GET request to 'http://127.0.0.1:0/v1/weather'"},
+		{`
+  METHOD: GET`, "Could not find variable 'URL' in step.variables"},
+		{`
+  URL: "http://%zz"`, "Could not create GET request to 'http://%zz'"},
+	}
+
+	for _, table := range tables {
+		step := newStep(t, "Send HTTP request", table.variables)
+		err := SendHTTPRequest(step)
+		if table.err != "" {
+			if assert.Error(t, err, table.variables) {
+				assert.Contains(t, err.Error(), table.err)
+			}
+			assert.False(t, step.HasSucceeded())
+			continue
+		}
+		assert.NoError(t, err, table.variables)
+		assert.True(t, step.HasSucceeded())
+		assert.Equal(t, "200", step.GetOutputs()["status"])
+		assert.Equal(t, "application/json", step.GetOutputs()["headers.Content-Type"])
+		assert.Contains(t, step.GetOutputs()["body"], `"city": "Toronto"`)
+	}
+}
+
+func TestSendHTTPRequestListsEveryFailure(t *testing.T) {
+	server := newWeatherServer()
+	defer server.Close()
+
+	step := newStep(t, "Send HTTP request", fmt.Sprintf(`
+  URL: %s/random
+  EXPECTED_STATUS: 2xx
+  EXPECTED_HEADERS: {X-Method: POST}
+  BODY_CONTAINS: Ottawa
+  EXPECTED_JSON: {$.city: Ottawa}`, server.URL))
+	err := SendHTTPRequest(step)
+	if assert.Error(t, err) {
+		assert.Contains(t, err.Error(), "status was 404 but expected 2xx")
+		assert.Contains(t, err.Error(), "header 'X-Method' was 'GET' but expected 'POST'")
+		assert.Contains(t, err.Error(), "does not contain 'Ottawa'")
+		assert.Contains(t, err.Error(), "JSON path '$.city' was 'Toronto' but expected 'Ottawa'")
+	}
+	assert.Equal(t, "404", step.GetOutputs()["status"])
+}
+
+func TestSendHTTPRequestWithTLS(t *testing.T) {
+	server := httptest.NewTLSServer(http.HandlerFunc(func(writer http.ResponseWriter, request *http.Request) {
+		fmt.Fprint(writer, "secure")
+	}))
+	defer server.Close()
+
+	dir, err := ioutil.TempDir("", "simple-e2e-http")
+	assert.NoError(t, err)
+	defer os.RemoveAll(dir)
+	caCert := filepath.Join(dir, "ca.pem")
+	certificate := pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE", Bytes: server.Certificate().Raw})
+	assert.NoError(t, ioutil.WriteFile(caCert, certificate, 0644))
+	invalidCert := filepath.Join(dir, "invalid.pem")
+	assert.NoError(t, ioutil.WriteFile(invalidCert, []byte("invalid"), 0644))
+
+	tables := []struct {
+		variables string
+		err       string
+	}{
+		{fmt.Sprintf("  URL: %s\n  EXPECTED_BODY: secure", server.URL), "certificate"},
+		{fmt.Sprintf("  URL: %s\n  EXPECTED_BODY: secure\n  INSECURE_SKIP_VERIFY: true", server.URL), ""},
+		{fmt.Sprintf("  URL: %s\n  EXPECTED_BODY: secure\n  CA_CERT: %s", server.URL, caCert), ""},
+		{fmt.Sprintf("  URL: %s\n  CA_CERT: %s", server.URL, invalidCert), "Could not find any certificates in CA certificate"},
+		{fmt.Sprintf("  URL: %s\n  CA_CERT: %s", server.URL, filepath.Join(dir, "random.pem")), "Could not read CA certificate"},
+		{fmt.Sprintf("  URL: %s\n  CLIENT_CERT: %s", server.URL, invalidCert), "Could not read client certificate"},
+		{fmt.Sprintf("  URL: %s\n  TIMEOUT: forever", server.URL), "forever"},
+	}
+
+	for _, table := range tables {
+		err := SendHTTPRequest(newStep(t, "Send HTTP request", table.variables))
+		if table.err != "" {
+			if assert.Error(t, err, table.variables) {
+				assert.Contains(t, err.Error(), table.err)
+			}
+			continue
+		}
+		assert.NoError(t, err, table.variables)
+	}
+}
+
+func TestMatchesStatus(t *testing.T) {
+	tables := []struct {
+		code     int
+		statuses []string
+		matches  bool
+	}{
+		{200, []string{"200"}, true},
+		{201, []string{"200", " 201 "}, true},
+		{204, []string{"2xx"}, true},
+		{204, []string{"2XX"}, true},
+		{404, []string{"2xx", "5xx"}, false},
+		{404, []string{"4x"}, false},
+	}
+
+	for _, table := range tables {
+		assert.Equal(t, table.matches, matchesStatus(table.code, table.statuses), table.statuses)
+	}
+}
@@ -0,0 +1,212 @@
+package operations
+
+import (
+	"fmt"
+	"io/ioutil"
+	"path/filepath"
+	"strings"
+
+	model "github.com/julianGoh17/simple-e2e/framework/models"
+	"gopkg.in/yaml.v2"
+)
+
+// library holds everything that a procedure can reuse from the test files that it includes
+type library struct {
+	stages          map[string]model.Stage
+	globalVariables map[string]string
+	secrets         map[string]model.Secret
+	stepTemplates   map[string]model.Step
+	macros          map[string]model.Macro
+}
+
+func newLibrary() *library {
+	return &library{
+		stages:          make(map[string]model.Stage),
+		globalVariables: make(map[string]string),
+		secrets:         make(map[string]model.Secret),
+		stepTemplates:   make(map[string]model.Step),
+		macros:          make(map[string]model.Macro),
+	}
+}
+
+// resolveIncludes will load all the test files included by the procedure and then replace any stages that 'use' an included stage and any steps
+// that use a 'template'. Global variables, secrets, step templates and macros from included files are merged into the procedure with the following precedence
+// (highest first): the procedure itself, the last included file, ..., the first included file.
+func resolveIncludes(procedure *model.Procedure) error {
+	logger.Trace().
+		Strs("include", procedure.Include).
+		Msg("Resolving included test files")
+	lib, err := loadLibrary(procedure.Include, []string{})
+	if err != nil {
+		logger.Error().
+			Err(err).
+			Msg("Failed to load included test files")
+		return err
+	}
+	if err := lib.applyTo(procedure); err != nil {
+		logger.Error().
+			Err(err).
+			Msg("Failed to use included test files")
+		return err
+	}
+	logger.Trace().
+		Msg("Successfully resolved included test files")
+	return nil
+}
+
+// loadLibrary will read each included file (which can include other files) and collect their stages, global variables, step templates and macros.
+// 'chain' is the list of files currently being included and is used to detect include cycles.
+func loadLibrary(includes, chain []string) (*library, error) {
+	lib := newLibrary()
+	for _, include := range includes {
+		includePath := getIncludePath(include)
+		for _, included := range chain {
+			if included == includePath {
+				return nil, fmt.Errorf("Include cycle detected: %s -> %s", strings.Join(chain, " -> "), includePath)
+			}
+		}
+
+		logger.Trace().
+			Str("include", includePath).
+			Msg("Loading included test file")
+		body, err := ioutil.ReadFile(includePath)
+		if err != nil {
+			return nil, fmt.Errorf("unable to read included file: %v", err)
+		}
+		included := &model.Procedure{}
+		if err := yaml.UnmarshalStrict(body, included); err != nil {
+			return nil, fmt.Errorf("unable to unmarshal included file '%s': %v", includePath, err)
+		}
+
+		includedLib, err := loadLibrary(included.Include, append(chain, includePath))
+		if err != nil {
+			return nil, err
+		}
+		if err := includedLib.applyTo(included); err != nil {
+			return nil, fmt.Errorf("unable to use included file '%s': %v", includePath, err)
+		}
+		if err := expandMacros(included); err != nil {
+			return nil, fmt.Errorf("unable to use included file '%s': %v", includePath, err)
+		}
+
+		// Stages of nested includes can also be used by the including procedure
+		for name, stage := range includedLib.stages {
+			lib.stages[name] = stage
+		}
+		for _, stage := range included.Stages {
+			lib.stages[stage.Name] = stage
+		}
+		for key, value := range included.GlobalVariables {
+			lib.globalVariables[key] = value
+		}
+		for name, secret := range included.Secrets {
+			lib.secrets[name] = secret
+		}
+		for name, template := range included.StepTemplates {
+			lib.stepTemplates[name] = template
+		}
+		for name, macro := range included.Macros {
+			lib.macros[name] = macro
+		}
+	}
+	return lib, nil
+}
+
+// applyTo merges the library into the procedure, replacing stages that 'use' a library stage and applying step templates. Macros are expanded
+// separately by expandMacros.
+func (lib *library) applyTo(procedure *model.Procedure) error {
+	if len(lib.globalVariables) > 0 {
+		globalVariables := make(map[string]string)
+		for key, value := range lib.globalVariables {
+			globalVariables[key] = value
+		}
+		for key, value := range procedure.GlobalVariables {
+			globalVariables[key] = value
+		}
+		procedure.GlobalVariables = globalVariables
+	}
+
+	if len(lib.secrets) > 0 {
+		secrets := make(map[string]model.Secret)
+		for name, secret := range lib.secrets {
+			secrets[name] = secret
+		}
+		for name, secret := range procedure.Secrets {
+			secrets[name] = secret
+		}
+		procedure.Secrets = secrets
+	}
+
+	if len(lib.stepTemplates) > 0 {
+		stepTemplates := make(map[string]model.Step)
+		for name, template := range lib.stepTemplates {
+			stepTemplates[name] = template
+		}
+		for name, template := range procedure.StepTemplates {
+			stepTemplates[name] = template
+		}
+		procedure.StepTemplates = stepTemplates
+	}
+
+	if len(lib.macros) > 0 {
+		macros := make(map[string]model.Macro)
+		for name, macro := range lib.macros {
+			macros[name] = macro
+		}
+		for name, macro := range procedure.Macros {
+			macros[name] = macro
+		}
+		procedure.Macros = macros
+	}
+
+	for index, stage := range procedure.Stages {
+		if stage.Uses == "" {
+			continue
+		}
+		used, ok := lib.stages[stage.Uses]
+		if !ok {
+			return fmt.Errorf("Could not find stage '%s' in included test files", stage.Uses)
+		}
+		if len(stage.Steps) > 0 {
+			return fmt.Errorf("Stage which uses stage '%s' can not have its own steps", stage.Uses)
+		}
+		resolved := used.Clone()
+		if stage.Name != "" {
+			resolved.Name = stage.Name
+		}
+		resolved.AlwaysRuns = resolved.AlwaysRuns || stage.AlwaysRuns
+		resolved.Tags = append(resolved.Tags, stage.Tags...)
+		if stage.If != "" {
+			resolved.If = stage.If
+		}
+		procedure.Stages[index] = resolved
+	}
+
+	for stage := range procedure.Stages {
+		for step := range procedure.Stages[stage].Steps {
+			if err := applyStepTemplate(procedure, &procedure.Stages[stage].Steps[step]); err != nil {
+				return err
+			}
+		}
+	}
+	return nil
+}
+
+func applyStepTemplate(procedure *model.Procedure, step *model.Step) error {
+	if step.Template == "" {
+		return nil
+	}
+	template, ok := procedure.StepTemplates[step.Template]
+	if !ok {
+		return fmt.Errorf("Could not find step template '%s'", step.Template)
+	}
+	step.ApplyTemplate(template)
+	return nil
+}
+
+func getIncludePath(include string) string {
+	if filepath.Ext(include) == "" {
+		include = include + ".yaml"
+	}
+	return getTestDirPath(include)
+}
@@ -0,0 +1,178 @@
+package operations
+
+import (
+	"fmt"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/julianGoh17/simple-e2e/framework/internal"
+	"github.com/julianGoh17/simple-e2e/framework/util"
+	"github.com/stretchr/testify/assert"
+)
+
+const includeCycle = `
+name: example-test
+description: example description
+include:
+  - examples/library/cycle-a
+stages:
+  - name: example-stage
+    steps:
+      - description: example-step
+`
+
+const includeMissingFile = `
+name: example-test
+description: example description
+include:
+  - examples/library/non-existent
+stages:
+  - name: example-stage
+    steps:
+      - description: example-step
+`
+
+const includeMissingStage = `
+name: example-test
+description: example description
+include:
+  - examples/library/setup
+stages:
+  - uses: non-existent
+`
+
+const includeStageWithSteps = `
+name: example-test
+description: example description
+include:
+  - examples/library/setup
+stages:
+  - uses: setup
+    steps:
+      - description: example-step
+`
+
+const missingStepTemplate = `
+name: example-test
+description: example description
+stages:
+  - name: example-stage
+    steps:
+      - template: non-existent
+`
+
+const localStepTemplate = `
+name: example-test
+description: example description
+stepTemplates:
+  example:
+    description: example-step
+    variables:
+      NAME: template
+      RETRIES: 3
+stages:
+  - name: example-stage
+    steps:
+      - template: example
+        variables:
+          NAME: step
+`
+
+func TestSetProcedureResolvesIncludes(t *testing.T) {
+	internal.SetTestFilesRoot()
+	controller, err := NewController()
+	assert.NoError(t, err)
+
+	body, err := ioutil.ReadFile(fmt.Sprintf("%s/examples/include-test.yaml", os.Getenv(util.TestDirEnv)))
+	assert.NoError(t, err)
+	assert.NoError(t, controller.SetProcedure(body))
+
+	procedure := controller.procedure
+	assert.Equal(t, map[string]string{"GREETING": "Hello", "TEAM": "e2e"}, procedure.GlobalVariables)
+	assert.Equal(t, 1, len(procedure.StepTemplates))
+	assert.Equal(t, 3, len(procedure.Stages))
+
+	setup := procedure.Stages[0]
+	assert.Equal(t, "setup", setup.Name)
+	assert.False(t, setup.AlwaysRuns)
+	assert.Equal(t, 1, len(setup.Steps))
+	assert.Equal(t, "Say hello to", setup.Steps[0].Description)
+	assert.Equal(t, "Setup", setup.Steps[0].Variables["NAME"])
+	assert.Equal(t, controller.docker, setup.Steps[0].Docker)
+
+	test := procedure.Stages[1]
+	assert.Equal(t, "test", test.Name)
+	assert.Equal(t, 2, len(test.Steps))
+	assert.Equal(t, "Template", test.Steps[0].Variables["NAME"])
+	assert.Equal(t, "Test", test.Steps[1].Variables["NAME"])
+
+	cleanup := procedure.Stages[2]
+	assert.Equal(t, "cleanup", cleanup.Name)
+	assert.True(t, cleanup.AlwaysRuns)
+	assert.Equal(t, "Teardown", cleanup.Steps[0].Variables["NAME"])
+}
+
+func TestRunTestWithIncludes(t *testing.T) {
+	internal.SetTestFilesRoot()
+	controller, err := NewController()
+	assert.NoError(t, err)
+
+	assert.NoError(t, controller.RunTest(fmt.Sprintf("%s/examples/include-test.yaml", os.Getenv(util.TestDirEnv))))
+}
+
+func TestSetProcedureAppliesLocalStepTemplates(t *testing.T) {
+	controller, err := NewController()
+	assert.NoError(t, err)
+
+	assert.NoError(t, controller.SetProcedure([]byte(localStepTemplate)))
+	step := controller.procedure.Stages[0].Steps[0]
+	assert.Equal(t, "example-step", step.Description)
+	assert.Equal(t, map[string]string{"NAME": "step", "RETRIES": "3"}, step.Variables)
+
+	retries, err := step.GetValueFromVariables("RETRIES")
+	assert.NoError(t, err)
+	assert.Equal(t, 3, retries)
+}
+
+func TestSetProcedureFailsToResolveIncludes(t *testing.T) {
+	internal.SetTestFilesRoot()
+	testDir := filepath.Clean(os.Getenv(util.TestDirEnv))
+
+	testCases := []struct {
+		testFile string
+		err      error
+	}{
+		{
+			includeCycle,
+			fmt.Errorf("Include cycle detected: %s/examples/library/cycle-a.yaml -> %s/examples/library/cycle-b.yaml -> %s/examples/library/cycle-a.yaml",
+				testDir, testDir, testDir),
+		},
+		{
+			includeMissingFile,
+			fmt.Errorf("unable to read included file: open %s/examples/library/non-existent.yaml: no such file or directory", testDir),
+		},
+		{
+			includeMissingStage,
+			fmt.Errorf("Could not find stage 'non-existent' in included test files"),
+		},
+		{
+			includeStageWithSteps,
+			fmt.Errorf("Stage which uses stage 'setup' can not have its own steps"),
+		},
+		{
+			missingStepTemplate,
+			fmt.Errorf("Could not find step template 'non-existent'"),
+		},
+	}
+
+	for _, testCase := range testCases {
+		controller, err := NewController()
+		assert.NoError(t, err)
+
+		err = controller.SetProcedure([]byte(testCase.testFile))
+		assert.Error(t, err)
+		assert.Equal(t, testCase.err.Error(), err.Error())
+	}
+}
@@ -0,0 +1,65 @@
+package operations
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"time"
+)
+
+// ErrInterrupted is the error of a test run which was interrupted
+var ErrInterrupted = errors.New("Test run was interrupted")
+
+// Interrupt is shared by every Controller of a run so the run can be stopped part way through. Once interrupted, the step which is running is
+// cancelled and the run carries on as if it had failed, so only 'alwaysRuns' stages and steps (and those whose 'if' is true on failure) are run.
+// They have until the teardown timeout to finish.
+type Interrupt struct {
+	ctx             context.Context
+	cancel          context.CancelFunc
+	teardownCtx     context.Context
+	teardownCancel  context.CancelFunc
+	teardownTimeout time.Duration
+	once            sync.Once
+}
+
+// NewInterrupt is a constructor function which returns an Interrupt whose 'alwaysRuns' stages and steps have teardownTimeout to finish once it
+// is triggered
+func NewInterrupt(teardownTimeout time.Duration) *Interrupt {
+	ctx, cancel := context.WithCancel(context.Background())
+	teardownCtx, teardownCancel := context.WithCancel(context.Background())
+	return &Interrupt{
+		ctx:             ctx,
+		cancel:          cancel,
+		teardownCtx:     teardownCtx,
+		teardownCancel:  teardownCancel,
+		teardownTimeout: teardownTimeout,
+	}
+}
+
+// Trigger will cancel the steps which are running and start the teardown timeout. Triggering it again does nothing.
+func (interrupt *Interrupt) Trigger() {
+	interrupt.once.Do(func() {
+		logger.Warn().
+			Dur("teardownTimeout", interrupt.teardownTimeout).
+			Msg("Run has been interrupted, only running stages and steps which run on failure")
+		interrupt.cancel()
+		time.AfterFunc(interrupt.teardownTimeout, interrupt.teardownCancel)
+	})
+}
+
+// IsInterrupted returns whether the run has been interrupted
+func (interrupt *Interrupt) IsInterrupted() bool {
+	return interrupt != nil && interrupt.ctx.Err() != nil
+}
+
+// getContext returns the context for a step which is starting now. It is cancelled as soon as the run is interrupted, or once the teardown
+// timeout has passed if the run has already been interrupted.
+func (interrupt *Interrupt) getContext() context.Context {
+	if interrupt == nil {
+		return context.Background()
+	}
+	if interrupt.IsInterrupted() {
+		return interrupt.teardownCtx
+	}
+	return interrupt.ctx
+}
@@ -0,0 +1,187 @@
+package operations
+
+import (
+	"errors"
+	"testing"
+	"time"
+
+	model "github.com/julianGoh17/simple-e2e/framework/models"
+	"github.com/stretchr/testify/assert"
+)
+
+const interruptTest = `
+name: interrupt-test
+description: example description
+stages:
+  - name: setup
+    steps:
+      - description: "interrupted-step"
+      - description: "pass-step"
+      - description: "pass-step"
+        alwaysRuns: true
+  - name: test
+    steps:
+      - description: "pass-step"
+  - name: report
+    if: failure()
+    steps:
+      - description: "pass-step"
+  - name: teardown
+    alwaysRuns: true
+    steps:
+      - description: "teardown-step"
+`
+
+func TestInterrupt(t *testing.T) {
+	var interrupt *Interrupt
+	assert.False(t, interrupt.IsInterrupted())
+	assert.NoError(t, interrupt.getContext().Err())
+
+	interrupt = NewInterrupt(10 * time.Millisecond)
+	assert.False(t, interrupt.IsInterrupted())
+	running := interrupt.getContext()
+	assert.NoError(t, running.Err())
+
+	interrupt.Trigger()
+	interrupt.Trigger()
+	assert.True(t, interrupt.IsInterrupted())
+	assert.Error(t, running.Err())
+
+	teardown := interrupt.getContext()
+	assert.NoError(t, teardown.Err())
+	select {
+	case <-teardown.Done():
+	case <-time.After(time.Second):
+		assert.Fail(t, "Teardown context was not cancelled after the teardown timeout")
+	}
+}
+
+func TestCallStep(t *testing.T) {
+	passes := func(step *model.Step) error {
+		step.SetPassed()
+		return nil
+	}
+	blocks := func(step *model.Step) error {
+		<-step.GetContext().Done()
+		time.Sleep(time.Second)
+		return nil
+	}
+
+	step := &model.Step{Description: "step"}
+	assert.NoError(t, callStep(passes, step))
+
+	interrupt := NewInterrupt(time.Minute)
+	step.SetContext(interrupt.getContext())
+	assert.NoError(t, callStep(passes, step))
+
+	go func() {
+		time.Sleep(10 * time.Millisecond)
+		interrupt.Trigger()
+	}()
+	start := time.Now()
+	err := callStep(blocks, step)
+	assert.True(t, time.Since(start) < time.Second)
+	if assert.Error(t, err) {
+		assert.Equal(t, "Step 'step' was interrupted: context canceled", err.Error())
+	}
+
+	err = callStep(passes, step)
+	if assert.Error(t, err) {
+		assert.Equal(t, "Step 'step' was interrupted: context canceled", err.Error())
+	}
+}
+
+func TestRunTestRunsTeardownWhenInterrupted(t *testing.T) {
+	controller, err := NewController()
+	assert.NoError(t, err)
+	interrupt := NewInterrupt(time.Minute)
+	controller.SetInterrupt(interrupt)
+	calls := make(map[string]int)
+	assert.NoError(t, controller.AddTestStep("interrupted-step", func(step *model.Step) error {
+		calls["interrupted"]++
+		interrupt.Trigger()
+		<-step.GetContext().Done()
+		return nil
+	}))
+	assert.NoError(t, controller.AddTestStep("pass-step", func(step *model.Step) error {
+		calls["pass"]++
+		step.SetPassed()
+		return nil
+	}))
+	assert.NoError(t, controller.AddTestStep("teardown-step", func(step *model.Step) error {
+		calls["teardown"]++
+		assert.NoError(t, step.GetContext().Err())
+		step.SetPassed()
+		return nil
+	}))
+
+	err = controller.runTest([]byte(interruptTest))
+	if assert.Error(t, err) {
+		assert.True(t, errors.Is(err, ErrInterrupted))
+		assert.Equal(t, "Test run was interrupted at stage: setup", err.Error())
+	}
+	assert.Equal(t, map[string]int{"interrupted": 1, "pass": 2, "teardown": 1}, calls)
+	stages := controller.GetResults()[0].Stages
+	assert.Equal(t, model.Failed, stages[0].Status)
+	assert.Equal(t, model.Skipped, stages[1].Status)
+	assert.Equal(t, model.Passed, stages[2].Status)
+	assert.Equal(t, model.Passed, stages[3].Status)
+
+	err = controller.runTest([]byte(interruptTest))
+	assert.Equal(t, ErrInterrupted, err)
+	assert.Equal(t, map[string]int{"interrupted": 1, "pass": 2, "teardown": 1}, calls)
+}
+
+func TestPollStepStopsWhenInterrupted(t *testing.T) {
+	controller, err := NewController()
+	assert.NoError(t, err)
+	interrupt := NewInterrupt(time.Minute)
+	controller.SetInterrupt(interrupt)
+	assert.NoError(t, controller.AddTestStep("fail-step", func(step *model.Step) error {
+		interrupt.Trigger()
+		return errors.New("not ready")
+	}))
+
+	err = controller.runTest([]byte(`
+name: poll-test
+stages:
+  - name: poll
+    steps:
+      - description: "fail-step"
+        until: success()
+        interval: 1m
+        within: 10m
+`))
+	assert.True(t, errors.Is(err, ErrInterrupted))
+	step := controller.GetResults()[0].Stages[0].Steps[0]
+	assert.Equal(t, 1, step.Attempts)
+	assert.Contains(t, step.Error, "Step 'fail-step' was interrupted before meeting condition 'success()' after 1 attempts")
+}
+
+func TestRunTestFailsStageInterruptedBetweenSteps(t *testing.T) {
+	controller, err := NewController()
+	assert.NoError(t, err)
+	interrupt := NewInterrupt(time.Minute)
+	controller.SetInterrupt(interrupt)
+	assert.NoError(t, controller.AddTestStep("interrupted-step", func(step *model.Step) error {
+		interrupt.Trigger()
+		step.SetPassed()
+		return nil
+	}))
+	assert.NoError(t, controller.AddTestStep("pass-step", func(step *model.Step) error {
+		step.SetPassed()
+		return nil
+	}))
+	assert.NoError(t, controller.AddTestStep("teardown-step", func(step *model.Step) error {
+		step.SetPassed()
+		return nil
+	}))
+
+	assert.True(t, errors.Is(controller.runTest([]byte(interruptTest)), ErrInterrupted))
+	stages := controller.GetResults()[0].Stages
+	assert.Equal(t, model.Failed, stages[0].Status)
+	assert.Equal(t, []model.ResultStatus{model.Passed, model.Skipped, model.Passed}, []model.ResultStatus{
+		stages[0].Steps[0].Status, stages[0].Steps[1].Status, stages[0].Steps[2].Status,
+	})
+	assert.Equal(t, model.Passed, stages[3].Status)
+}
@@ -0,0 +1,242 @@
+package operations
+
+import (
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+	"reflect"
+	"strings"
+
+	"github.com/julianGoh17/simple-e2e/framework/models"
+	"gopkg.in/yaml.v2"
+)
+
+// AssertJSONPathEquals will check the values at JSON paths of a JSON or YAML document
+// Environmental Variables:
+//   - JSON: The document to check, written in the test or taken from a step output such as '${steps.api.outputs.body}'
+//   - FILE: A file with the document to check, relative to the test directory, which is used when JSON is not set
+//   - PATH: The JSON path of the value to check, such as '$.items[0].name'
+//   - EXPECTED: The value expected at PATH
+//   - EXPECTED_JSON: A map of JSON paths to the values expected at them, to check many paths at once
+func AssertJSONPathEquals(step *models.Step) error {
+	traceStepEntrance(step)
+	document, err := getDocument(step, "JSON", "FILE")
+	if err != nil {
+		return traceStepExit(step, err)
+	}
+
+	expected := make(map[string]interface{})
+	if path, err := step.GetValueFromVariablesAsString("PATH"); err == nil {
+		value, err := step.GetValueFromVariables("EXPECTED")
+		if err != nil {
+			return traceStepExit(step, err)
+		}
+		if expected[path], err = normalizeDocument(value); err != nil {
+			return traceStepExit(step, err)
+		}
+	}
+	paths, err := step.GetValueFromVariablesAsMapOrDefault("EXPECTED_JSON", map[string]string{})
+	if err != nil {
+		return traceStepExit(step, err)
+	}
+	for path, value := range paths {
+		expected[path] = value
+	}
+	if len(expected) == 0 {
+		return traceStepExit(step, fmt.Errorf("Step '%s' must have 'PATH' and 'EXPECTED' or 'EXPECTED_JSON'", step.Description))
+	}
+
+	failures := []string{}
+	for _, path := range getSortedKeys(expected) {
+		actual, err := getJSONPath(document, path)
+		if err != nil {
+			failures = append(failures, err.Error())
+			continue
+		}
+		tokens, _ := parseJSONPath(path)
+		failures = append(failures, diffJSON(getExpectedJSON(actual, expected[path]), actual, tokens, false, nil)...)
+	}
+	return traceStepExit(step, newJSONAssertionError("JSON does not have the expected values", failures))
+}
+
+// AssertJSONMatchesSchema will check a JSON or YAML document against a JSON Schema (draft 7). References can only point within the schema and
+// formats are not checked.
+// Environmental Variables:
+//   - JSON: The document to check, written in the test or taken from a step output such as '${steps.api.outputs.body}'
+//   - FILE: A file with the document to check, relative to the test directory, which is used when JSON is not set
+//   - SCHEMA: The JSON Schema, written in the test as JSON or YAML
+//   - SCHEMA_FILE: A file with the JSON Schema, relative to the test directory, which is used when SCHEMA is not set
+func AssertJSONMatchesSchema(step *models.Step) error {
+	traceStepEntrance(step)
+	document, err := getDocument(step, "JSON", "FILE")
+	if err != nil {
+		return traceStepExit(step, err)
+	}
+	schema, err := getDocument(step, "SCHEMA", "SCHEMA_FILE")
+	if err != nil {
+		return traceStepExit(step, err)
+	}
+	return traceStepExit(step, newJSONAssertionError("JSON does not match the schema", validateJSONSchema(schema, document)))
+}
+
+// AssertJSONSubset will check that a JSON or YAML document has every key and value of the expected document. Keys which are only in the checked
+// document are allowed, while arrays must have the same number of items.
+// Environmental Variables:
+//   - JSON: The document to check, written in the test or taken from a step output such as '${steps.api.outputs.body}'
+//   - FILE: A file with the document to check, relative to the test directory, which is used when JSON is not set
+//   - EXPECTED: The expected document, written in the test as JSON or YAML
+//   - EXPECTED_FILE: A file with the expected document, relative to the test directory, which is used when EXPECTED is not set
+//   - IGNORE_PATHS: JSON paths which are not checked, such as '$.items[*].id', as a list or separated by commas
+func AssertJSONSubset(step *models.Step) error {
+	traceStepEntrance(step)
+	document, err := getDocument(step, "JSON", "FILE")
+	if err != nil {
+		return traceStepExit(step, err)
+	}
+	expected, err := getDocument(step, "EXPECTED", "EXPECTED_FILE")
+	if err != nil {
+		return traceStepExit(step, err)
+	}
+	ignorePaths, err := step.GetValueFromVariablesAsStringArrayOrDefault("IGNORE_PATHS", []string{})
+	if err != nil {
+		return traceStepExit(step, err)
+	}
+	ignore := [][]jsonPathToken{}
+	for _, path := range ignorePaths {
+		tokens, err := parseJSONPath(path)
+		if err != nil {
+			return traceStepExit(step, err)
+		}
+		ignore = append(ignore, tokens)
+	}
+	failures := diffJSON(expected, document, []jsonPathToken{}, true, ignore)
+	return traceStepExit(step, newJSONAssertionError("JSON does not contain the expected JSON", failures))
+}
+
+// getDocument returns the document written in the value variable, or read from the file in the file variable when the value variable is not set.
+// Documents can be JSON or YAML.
+func getDocument(step *models.Step, valueVariable, fileVariable string) (interface{}, error) {
+	if value, err := step.GetValueFromVariables(valueVariable); err == nil {
+		if text, isString := value.(string); isString {
+			return parseDocument(valueVariable, []byte(text))
+		}
+		return normalizeDocument(value)
+	}
+	if path, err := step.GetValueFromVariablesAsString(fileVariable); err == nil {
+		body, err := ioutil.ReadFile(getTestDirPath(path))
+		if err != nil {
+			return nil, fmt.Errorf("Could not read '%s': %v", path, err)
+		}
+		return parseDocument(path, body)
+	}
+	return nil, fmt.Errorf("Step '%s' must have '%s' or '%s'", step.Description, valueVariable, fileVariable)
+}
+
+// parseDocument decodes the body as JSON, or as YAML if it is not JSON
+func parseDocument(name string, body []byte) (interface{}, error) {
+	var document interface{}
+	jsonErr := json.Unmarshal(body, &document)
+	if jsonErr == nil {
+		return document, nil
+	}
+	if err := yaml.Unmarshal(body, &document); err != nil {
+		return nil, fmt.Errorf("Could not read '%s' as JSON or YAML: %v", name, jsonErr)
+	}
+	return normalizeDocument(document)
+}
+
+// normalizeDocument returns the decoded YAML value as if it had been decoded from JSON, so maps have string keys and numbers are float64
+func normalizeDocument(value interface{}) (interface{}, error) {
+	body, err := json.Marshal(models.NormalizeVariable(value))
+	if err != nil {
+		return nil, fmt.Errorf("Could not convert '%v' to JSON: %v", value, err)
+	}
+	var document interface{}
+	if err := json.Unmarshal(body, &document); err != nil {
+		return nil, fmt.Errorf("Could not convert '%v' to JSON: %v", value, err)
+	}
+	return document, nil
+}
+
+// diffJSON returns a line for every difference between the expected and actual values, starting with the JSON path of the difference. When
+// subset is true, keys which are only in the actual value are not differences. Paths which match any of the ignore paths are not compared.
+func diffJSON(expected, actual interface{}, path []jsonPathToken, subset bool, ignore [][]jsonPathToken) []string {
+	if isIgnoredJSONPath(path, ignore) {
+		return []string{}
+	}
+	mismatch := []string{fmt.Sprintf("%s: expected %s but was %s", formatJSONPath(path), formatJSONValue(expected), formatJSONValue(actual))}
+
+	switch typed := expected.(type) {
+	case map[string]interface{}:
+		actualMap, ok := actual.(map[string]interface{})
+		if !ok {
+			return mismatch
+		}
+		failures := []string{}
+		for _, key := range getSortedKeys(typed) {
+			child := appendJSONPath(path, jsonPathToken{key: key})
+			value, exists := actualMap[key]
+			if !exists {
+				if !isIgnoredJSONPath(child, ignore) {
+					failures = append(failures, fmt.Sprintf("%s: is missing, expected %s", formatJSONPath(child), formatJSONValue(typed[key])))
+				}
+				continue
+			}
+			failures = append(failures, diffJSON(typed[key], value, child, subset, ignore)...)
+		}
+		if !subset {
+			for _, key := range getSortedKeys(actualMap) {
+				child := appendJSONPath(path, jsonPathToken{key: key})
+				if _, exists := typed[key]; !exists && !isIgnoredJSONPath(child, ignore) {
+					failures = append(failures, fmt.Sprintf("%s: was not expected, was %s", formatJSONPath(child), formatJSONValue(actualMap[key])))
+				}
+			}
+		}
+		return failures
+	case []interface{}:
+		actualList, ok := actual.([]interface{})
+		if !ok {
+			return mismatch
+		}
+		failures := []string{}
+		if len(typed) != len(actualList) {
+			failures = append(failures, fmt.Sprintf("%s: expected %d items but had %d", formatJSONPath(path), len(typed), len(actualList)))
+		}
+		for index := 0; index < len(typed) && index < len(actualList); index++ {
+			child := appendJSONPath(path, jsonPathToken{index: index, isIndex: true})
+			failures = append(failures, diffJSON(typed[index], actualList[index], child, subset, ignore)...)
+		}
+		return failures
+	default:
+		if !reflect.DeepEqual(expected, actual) {
+			return mismatch
+		}
+		return []string{}
+	}
+}
+
+func isIgnoredJSONPath(path []jsonPathToken, ignore [][]jsonPathToken) bool {
+	for _, pattern := range ignore {
+		if matchesJSONPath(path, pattern) {
+			return true
+		}
+	}
+	return false
+}
+
+// formatJSONValue returns the value as compact JSON, so strings are quoted and can be told apart from numbers and booleans
+func formatJSONValue(value interface{}) string {
+	bytes, err := json.Marshal(value)
+	if err != nil {
+		return fmt.Sprint(value)
+	}
+	return string(bytes)
+}
+
+// newJSONAssertionError returns an error with the message and a line for every failure, or nil if there are no failures
+func newJSONAssertionError(message string, failures []string) error {
+	if len(failures) == 0 {
+		return nil
+	}
+	return fmt.Errorf("%s:\n  %s", message, strings.Join(failures, "\n  "))
+}
@@ -0,0 +1,142 @@
+package operations
+
+import (
+	"fmt"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+const weatherJSON = `{"city": "Toronto", "temps": [21.5, 19], "station": {"id": 10, "name": "Pearson"}, "updated": "2020-10-19"}`
+
+func writeJSONFiles(t *testing.T) (string, func()) {
+	dir, err := ioutil.TempDir("", "simple-e2e-json")
+	assert.NoError(t, err)
+	assert.NoError(t, ioutil.WriteFile(filepath.Join(dir, "weather.json"), []byte(weatherJSON), 0644))
+	assert.NoError(t, ioutil.WriteFile(filepath.Join(dir, "weather.yaml"), []byte("city: Toronto\ntemps: [21.5, 19]\n"), 0644))
+	assert.NoError(t, ioutil.WriteFile(filepath.Join(dir, "schema.yaml"), []byte("type: object\nrequired: [city]\n"), 0644))
+	assert.NoError(t, ioutil.WriteFile(filepath.Join(dir, "invalid.json"), []byte("{: ["), 0644))
+	return dir, func() {
+		os.RemoveAll(dir)
+	}
+}
+
+func TestAssertJSONPathEquals(t *testing.T) {
+	dir, cleanup := writeJSONFiles(t)
+	defer cleanup()
+
+	tables := []struct {
+		variables string
+		err       string
+	}{
+		{fmt.Sprintf("  JSON: '%s'\n  PATH: $.city\n  EXPECTED: Toronto", weatherJSON), ""},
+		{fmt.Sprintf("  JSON: '%s'\n  PATH: $.temps[0]\n  EXPECTED: 21.5", weatherJSON), ""},
+		{fmt.Sprintf("  JSON: '%s'\n  PATH: station\n  EXPECTED: {name: Pearson, id: 10}", weatherJSON), ""},
+		{fmt.Sprintf("  JSON: '%s'\n  EXPECTED_JSON: {$.temps: '[21.5, 19]', station.id: 10, updated: '2020-10-19'}", weatherJSON), ""},
+		{fmt.Sprintf("  FILE: %s\n  PATH: $.temps[1]\n  EXPECTED: 19", filepath.Join(dir, "weather.json")), ""},
+		{fmt.Sprintf("  FILE: %s\n  PATH: $.temps[1]\n  EXPECTED: 19", filepath.Join(dir, "weather.yaml")), ""},
+		{"  JSON: {city: Toronto}\n  PATH: city\n  EXPECTED: Toronto", ""},
+		{fmt.Sprintf("  JSON: '%s'\n  PATH: station\n  EXPECTED: {name: Downsview, id: 10, active: true}", weatherJSON),
+			"JSON does not have the expected values:\n  $.station.active: is missing, expected true\n  $.station.name: expected \"Downsview\" but was \"Pearson\""},
+		{fmt.Sprintf("  JSON: '%s'\n  EXPECTED_JSON: {$.city: Ottawa, $.random: 1}", weatherJSON),
+			"JSON does not have the expected values:\n  $.city: expected \"Ottawa\" but was \"Toronto\"\n  JSON path '$.random' does not exist as there is no key 'random'"},
+		{fmt.Sprintf("  JSON: '%s'\n  PATH: $.temps\n  EXPECTED: [21.5]", weatherJSON),
+			"JSON does not have the expected values:\n  $.temps: expected 1 items but had 2"},
+		{fmt.Sprintf("  JSON: '%s'\n  PATH: $.city", weatherJSON), "Could not find variable 'EXPECTED' in step.variables"},
+		{fmt.Sprintf("  JSON: '%s'", weatherJSON), "Step 'Assert JSON path equals' must have 'PATH' and 'EXPECTED' or 'EXPECTED_JSON'"},
+		{"  PATH: $.city", "Step 'Assert JSON path equals' must have 'JSON' or 'FILE'"},
+		{fmt.Sprintf("  FILE: %s\n  PATH: $", filepath.Join(dir, "random.json")), "Could not read"},
+		{fmt.Sprintf("  FILE: %s\n  PATH: $", filepath.Join(dir, "invalid.json")), "as JSON or YAML"},
+	}
+
+	for _, table := range tables {
+		step := newStep(t, "Assert JSON path equals", table.variables)
+		err := AssertJSONPathEquals(step)
+		if table.err != "" {
+			if assert.Error(t, err, table.variables) {
+				assert.Contains(t, err.Error(), table.err)
+			}
+			assert.False(t, step.HasSucceeded())
+			continue
+		}
+		assert.NoError(t, err, table.variables)
+		assert.True(t, step.HasSucceeded())
+	}
+}
+
+func TestAssertJSONMatchesSchema(t *testing.T) {
+	dir, cleanup := writeJSONFiles(t)
+	defer cleanup()
+
+	tables := []struct {
+		variables string
+		err       string
+	}{
+		{fmt.Sprintf("  JSON: '%s'\n  SCHEMA: '{\"type\": \"object\", \"required\": [\"city\"]}'", weatherJSON), ""},
+		{fmt.Sprintf("  FILE: %s\n  SCHEMA_FILE: %s", filepath.Join(dir, "weather.yaml"), filepath.Join(dir, "schema.yaml")), ""},
+		{fmt.Sprintf("  JSON: '%s'\n  SCHEMA:\n    type: object\n    properties:\n      temps: {items: {maximum: 20}}\n      city: {type: integer}", weatherJSON),
+			"JSON does not match the schema:\n  $.city: expected type integer but was string\n  $.temps[0]: 21.5 is more than the maximum of 20"},
+		{fmt.Sprintf("  JSON: '%s'", weatherJSON), "Step 'Assert JSON matches schema' must have 'SCHEMA' or 'SCHEMA_FILE'"},
+		{"  SCHEMA: {}", "Step 'Assert JSON matches schema' must have 'JSON' or 'FILE'"},
+	}
+
+	for _, table := range tables {
+		step := newStep(t, "Assert JSON matches schema", table.variables)
+		err := AssertJSONMatchesSchema(step)
+		if table.err != "" {
+			if assert.Error(t, err, table.variables) {
+				assert.Equal(t, table.err, err.Error())
+			}
+			continue
+		}
+		assert.NoError(t, err, table.variables)
+		assert.True(t, step.HasSucceeded())
+	}
+}
+
+func TestAssertJSONSubset(t *testing.T) {
+	dir, cleanup := writeJSONFiles(t)
+	defer cleanup()
+
+	tables := []struct {
+		variables string
+		err       string
+	}{
+		{fmt.Sprintf("  JSON: '%s'\n  EXPECTED: {city: Toronto, station: {id: 10}}", weatherJSON), ""},
+		{fmt.Sprintf("  JSON: '%s'\n  EXPECTED_FILE: %s", weatherJSON, filepath.Join(dir, "weather.yaml")), ""},
+		{fmt.Sprintf("  JSON: '%s'\n  EXPECTED: {city: Ottawa, station: {id: 11}, updated: today}\n  IGNORE_PATHS: [$.updated, '$.station.*']", weatherJSON),
+			"JSON does not contain the expected JSON:\n  $.city: expected \"Ottawa\" but was \"Toronto\""},
+		{fmt.Sprintf("  JSON: '%s'\n  EXPECTED: {temps: [21.5, 20, 18], city: [Toronto], random: true}", weatherJSON),
+			"JSON does not contain the expected JSON:\n  $.city: expected [\"Toronto\"] but was \"Toronto\"\n  $.random: is missing, expected true\n  $.temps: expected 3 items but had 2\n  $.temps[1]: expected 20 but was 19"},
+		{fmt.Sprintf("  JSON: '%s'\n  EXPECTED: {}\n  IGNORE_PATHS: '$.items['", weatherJSON), "JSON path '$.items[' has a '[' without a ']'"},
+		{fmt.Sprintf("  JSON: '%s'", weatherJSON), "Step 'Assert JSON subset' must have 'EXPECTED' or 'EXPECTED_FILE'"},
+	}
+
+	for _, table := range tables {
+		step := newStep(t, "Assert JSON subset", table.variables)
+		err := AssertJSONSubset(step)
+		if table.err != "" {
+			if assert.Error(t, err, table.variables) {
+				assert.Equal(t, table.err, err.Error())
+			}
+			continue
+		}
+		assert.NoError(t, err, table.variables)
+		assert.True(t, step.HasSucceeded())
+	}
+}
+
+func TestDiffJSON(t *testing.T) {
+	expected := map[string]interface{}{"a": float64(1), "b": []interface{}{"x"}}
+	actual := map[string]interface{}{"a": float64(1), "b": []interface{}{"x"}, "c": true}
+
+	assert.Equal(t, []string{}, diffJSON(expected, actual, []jsonPathToken{}, true, nil))
+	assert.Equal(t, []string{"$.c: was not expected, was true"}, diffJSON(expected, actual, []jsonPathToken{}, false, nil))
+	ignore, _ := parseJSONPath("$.c")
+	assert.Equal(t, []string{}, diffJSON(expected, actual, []jsonPathToken{}, false, [][]jsonPathToken{ignore}))
+	assert.Equal(t, []string{"$: expected {\"a\":1,\"b\":[\"x\"]} but was []"}, diffJSON(expected, []interface{}{}, []jsonPathToken{}, false, nil))
+	assert.Equal(t, []string{"$: expected [] but was {}"}, diffJSON([]interface{}{}, map[string]interface{}{}, []jsonPathToken{}, false, nil))
+}
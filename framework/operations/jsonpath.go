@@ -0,0 +1,173 @@
+package operations
+
+import (
+	"encoding/json"
+	"fmt"
+	"reflect"
+	"regexp"
+	"strconv"
+	"strings"
+)
+
+var jsonPathKeyPattern = regexp.MustCompile(`^[A-Za-z_][A-Za-z0-9_]*$`)
+
+// jsonPathToken is a single key or array index of a JSON path, or a wildcard which matches every key or index
+type jsonPathToken struct {
+	key        string
+	index      int
+	isIndex    bool
+	isWildcard bool
+}
+
+// getJSONPath returns the value at the path in the decoded JSON document. Paths can start with '$' and use '.name', '[index]' and '['name']' to
+// select values, for example '$.items[0].name'. Negative indexes count back from the end of an array. Wildcards can be parsed but not selected.
+func getJSONPath(document interface{}, path string) (interface{}, error) {
+	tokens, err := parseJSONPath(path)
+	if err != nil {
+		return nil, err
+	}
+
+	current := document
+	for _, token := range tokens {
+		if token.isWildcard {
+			return nil, fmt.Errorf("JSON path '%s' can not use '*' to select a single value", path)
+		}
+		switch value := current.(type) {
+		case map[string]interface{}:
+			if token.isIndex {
+				return nil, fmt.Errorf("JSON path '%s' does not exist as index %d is used on an object", path, token.index)
+			}
+			next, ok := value[token.key]
+			if !ok {
+				return nil, fmt.Errorf("JSON path '%s' does not exist as there is no key '%s'", path, token.key)
+			}
+			current = next
+		case []interface{}:
+			if !token.isIndex {
+				return nil, fmt.Errorf("JSON path '%s' does not exist as key '%s' is used on an array", path, token.key)
+			}
+			index := token.index
+			if index < 0 {
+				index += len(value)
+			}
+			if index < 0 || index >= len(value) {
+				return nil, fmt.Errorf("JSON path '%s' does not exist as index %d is out of range of an array of length %d", path, token.index, len(value))
+			}
+			current = value[index]
+		default:
+			return nil, fmt.Errorf("JSON path '%s' does not exist as '%s' is not an object or array", path, jsonToString(current))
+		}
+	}
+	return current, nil
+}
+
+// parseJSONPath splits the JSON path into the keys and indexes which it selects
+func parseJSONPath(path string) ([]jsonPathToken, error) {
+	tokens := []jsonPathToken{}
+	rest := strings.TrimPrefix(strings.TrimSpace(path), "$")
+	if rest != "" && rest[0] != '.' && rest[0] != '[' {
+		rest = "." + rest
+	}
+
+	for rest != "" {
+		switch rest[0] {
+		case '.':
+			end := strings.IndexAny(rest[1:], ".[")
+			if end == -1 {
+				end = len(rest) - 1
+			}
+			key := rest[1 : end+1]
+			switch key {
+			case "":
+				return nil, fmt.Errorf("JSON path '%s' has an empty key", path)
+			case "*":
+				tokens = append(tokens, jsonPathToken{isWildcard: true})
+			default:
+				tokens = append(tokens, jsonPathToken{key: key})
+			}
+			rest = rest[end+1:]
+		case '[':
+			end := strings.Index(rest, "]")
+			if end == -1 {
+				return nil, fmt.Errorf("JSON path '%s' has a '[' without a ']'", path)
+			}
+			inside := strings.TrimSpace(rest[1:end])
+			if inside == "*" {
+				tokens = append(tokens, jsonPathToken{isWildcard: true})
+			} else if len(inside) >= 2 && (inside[0] == '\'' || inside[0] == '"') && inside[len(inside)-1] == inside[0] {
+				tokens = append(tokens, jsonPathToken{key: inside[1 : len(inside)-1]})
+			} else if index, err := strconv.Atoi(inside); err == nil {
+				tokens = append(tokens, jsonPathToken{index: index, isIndex: true})
+			} else {
+				return nil, fmt.Errorf("JSON path '%s' has '[%s]' which is not an index or a quoted key", path, inside)
+			}
+			rest = rest[end+1:]
+		default:
+			return nil, fmt.Errorf("JSON path '%s' is not valid at '%s'", path, rest)
+		}
+	}
+	return tokens, nil
+}
+
+// formatJSONPath returns the JSON path of the keys and indexes, using '.name' for keys which are identifiers and '['name']' for other keys
+func formatJSONPath(tokens []jsonPathToken) string {
+	var path strings.Builder
+	path.WriteString("$")
+	for _, token := range tokens {
+		switch {
+		case token.isWildcard:
+			path.WriteString("[*]")
+		case token.isIndex:
+			fmt.Fprintf(&path, "[%d]", token.index)
+		case jsonPathKeyPattern.MatchString(token.key):
+			fmt.Fprintf(&path, ".%s", token.key)
+		default:
+			fmt.Fprintf(&path, "['%s']", token.key)
+		}
+	}
+	return path.String()
+}
+
+// matchesJSONPath returns whether the keys and indexes are selected by the pattern, whose wildcards match any single key or index
+func matchesJSONPath(tokens, pattern []jsonPathToken) bool {
+	if len(tokens) != len(pattern) {
+		return false
+	}
+	for index, token := range pattern {
+		if !token.isWildcard && token != tokens[index] {
+			return false
+		}
+	}
+	return true
+}
+
+// jsonValueEquals returns whether the decoded JSON value equals the expected value, which is decoded as JSON as described in getExpectedJSON
+func jsonValueEquals(actual interface{}, expected string) bool {
+	return reflect.DeepEqual(actual, getExpectedJSON(actual, expected))
+}
+
+// getExpectedJSON returns the expected value to compare with the decoded JSON value. Expected values which are text are decoded as JSON unless
+// the actual value is a string, so '1', 'true' and '{"a": 1}' match the number, boolean and object they describe.
+func getExpectedJSON(actual, expected interface{}) interface{} {
+	text, isString := expected.(string)
+	if _, actualIsString := actual.(string); !isString || actualIsString {
+		return expected
+	}
+	var decoded interface{}
+	if err := json.Unmarshal([]byte(text), &decoded); err != nil {
+		return expected
+	}
+	return decoded
+}
+
+// jsonToString returns strings as they are and everything else as JSON
+func jsonToString(value interface{}) string {
+	if text, isString := value.(string); isString {
+		return text
+	}
+	bytes, err := json.Marshal(value)
+	if err != nil {
+		return fmt.Sprint(value)
+	}
+	return string(bytes)
+}
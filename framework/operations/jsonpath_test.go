@@ -0,0 +1,111 @@
+package operations
+
+import (
+	"encoding/json"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+const jsonDocument = `{"name": "weather", "items": [{"city": "Toronto", "temp": 21.5}, {"city": "Ottawa", "temp": 19}], "odd.key": true, "empty": null}`
+
+func TestGetJSONPath(t *testing.T) {
+	var document interface{}
+	assert.NoError(t, json.Unmarshal([]byte(jsonDocument), &document))
+
+	tables := []struct {
+		path     string
+		expected string
+		err      string
+	}{
+		{"$", jsonDocument, ""},
+		{"$.name", "weather", ""},
+		{"name", "weather", ""},
+		{"$.items[0].city", "Toronto", ""},
+		{"items[1].temp", "19", ""},
+		{"$.items[-1].city", "Ottawa", ""},
+		{"$['odd.key']", "true", ""},
+		{`$["items"][0]`, `{"city": "Toronto", "temp": 21.5}`, ""},
+		{"$.empty", "null", ""},
+		{"$.random", "", "JSON path '$.random' does not exist as there is no key 'random'"},
+		{"$.items[2]", "", "JSON path '$.items[2]' does not exist as index 2 is out of range of an array of length 2"},
+		{"$.items.city", "", "JSON path '$.items.city' does not exist as key 'city' is used on an array"},
+		{"$[0]", "", "JSON path '$[0]' does not exist as index 0 is used on an object"},
+		{"$.name.first", "", "JSON path '$.name.first' does not exist as 'weather' is not an object or array"},
+		{"$.items[", "", "JSON path '$.items[' has a '[' without a ']'"},
+		{"$.items[*]", "", "JSON path '$.items[*]' can not use '*' to select a single value"},
+		{"$.items[first]", "", "JSON path '$.items[first]' has '[first]' which is not an index or a quoted key"},
+		{"$..name", "", "JSON path '$..name' has an empty key"},
+	}
+
+	for _, table := range tables {
+		actual, err := getJSONPath(document, table.path)
+		if table.err != "" {
+			if assert.Error(t, err, table.path) {
+				assert.Equal(t, table.err, err.Error())
+			}
+			continue
+		}
+		assert.NoError(t, err, table.path)
+		assert.True(t, jsonValueEquals(actual, table.expected), table.path)
+	}
+}
+
+func TestFormatAndMatchJSONPath(t *testing.T) {
+	tables := []struct {
+		path      string
+		formatted string
+	}{
+		{"$", "$"},
+		{"items[0].city", "$.items[0].city"},
+		{"$['odd.key'][1]", "$['odd.key'][1]"},
+		{"$.items.*.id", "$.items[*].id"},
+	}
+
+	for _, table := range tables {
+		tokens, err := parseJSONPath(table.path)
+		assert.NoError(t, err)
+		assert.Equal(t, table.formatted, formatJSONPath(tokens))
+	}
+
+	pattern, _ := parseJSONPath("$.items[*].id")
+	first, _ := parseJSONPath("$.items[0].id")
+	named, _ := parseJSONPath("$.items.first.id")
+	other, _ := parseJSONPath("$.items[0].name")
+	assert.True(t, matchesJSONPath(first, pattern))
+	assert.True(t, matchesJSONPath(named, pattern))
+	assert.False(t, matchesJSONPath(other, pattern))
+	assert.False(t, matchesJSONPath(first[:2], pattern))
+}
+
+func TestJSONValueEquals(t *testing.T) {
+	tables := []struct {
+		actual   interface{}
+		expected string
+		equals   bool
+	}{
+		{"text", "text", true},
+		{"1", "1", true},
+		{float64(1), "1", true},
+		{float64(1), "1.0", true},
+		{float64(1), "2", false},
+		{true, "true", true},
+		{nil, "null", true},
+		{map[string]interface{}{"a": float64(1)}, `{"a": 1}`, true},
+		{[]interface{}{"a"}, `["a"]`, true},
+		{[]interface{}{"a"}, "a", false},
+	}
+
+	for _, table := range tables {
+		assert.Equal(t, table.equals, jsonValueEquals(table.actual, table.expected), table.expected)
+	}
+	assert.Equal(t, "text", jsonToString("text"))
+	assert.Equal(t, `{"a":1}`, jsonToString(map[string]interface{}{"a": 1}))
+}
+
+func TestGetExpectedJSON(t *testing.T) {
+	assert.Equal(t, "1", getExpectedJSON("text", "1"))
+	assert.Equal(t, float64(1), getExpectedJSON(float64(1), "1"))
+	assert.Equal(t, "not json", getExpectedJSON(float64(1), "not json"))
+	assert.Equal(t, true, getExpectedJSON(float64(1), true))
+}
@@ -0,0 +1,374 @@
+package operations
+
+import (
+	"fmt"
+	"math"
+	"reflect"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+	"unicode/utf8"
+)
+
+// jsonSchemaValidator checks decoded JSON documents against a JSON Schema (draft 7) without fetching anything, so '$ref' can only point within
+// the schema and 'format' is not checked
+type jsonSchemaValidator struct {
+	root interface{}
+	// resolving holds the references which are being checked against the value at each path, so a reference which leads back to itself
+	// without moving into the value is reported instead of being followed forever
+	resolving map[string]bool
+}
+
+// validateJSONSchema returns a failure for every part of the document which does not match the schema, each starting with the JSON path of the
+// part which failed
+func validateJSONSchema(schema, document interface{}) []string {
+	validator := &jsonSchemaValidator{root: schema, resolving: make(map[string]bool)}
+	return validator.validate(schema, document, []jsonPathToken{})
+}
+
+func (validator *jsonSchemaValidator) validate(schema, value interface{}, path []jsonPathToken) []string {
+	switch typed := schema.(type) {
+	case bool:
+		if !typed {
+			return []string{fmt.Sprintf("%s: is not allowed by the schema", formatJSONPath(path))}
+		}
+		return []string{}
+	case map[string]interface{}:
+		if ref, ok := typed["$ref"].(string); ok {
+			key := formatJSONPath(path) + " " + ref
+			if validator.resolving[key] {
+				return []string{fmt.Sprintf("%s: circular $ref '%s'", formatJSONPath(path), ref)}
+			}
+			resolved, err := validator.resolveRef(ref)
+			if err != nil {
+				return []string{fmt.Sprintf("%s: %v", formatJSONPath(path), err)}
+			}
+			validator.resolving[key] = true
+			defer delete(validator.resolving, key)
+			return validator.validate(resolved, value, path)
+		}
+		failures := []string{}
+		fail := func(format string, args ...interface{}) {
+			failures = append(failures, fmt.Sprintf("%s: %s", formatJSONPath(path), fmt.Sprintf(format, args...)))
+		}
+		// nested failures are collected separately as 'fail' appends to failures while the keywords are checked
+		nested := []string{}
+		validator.validateGeneric(typed, value, fail)
+		switch typedValue := value.(type) {
+		case float64:
+			validateNumber(typed, typedValue, fail)
+		case string:
+			validateString(typed, typedValue, fail)
+		case []interface{}:
+			nested = append(nested, validator.validateArray(typed, typedValue, path, fail)...)
+		case map[string]interface{}:
+			nested = append(nested, validator.validateObject(typed, typedValue, path, fail)...)
+		}
+		nested = append(nested, validator.validateCombinations(typed, value, path, fail)...)
+		return append(failures, nested...)
+	default:
+		return []string{fmt.Sprintf("%s: schema must be an object or a boolean", formatJSONPath(path))}
+	}
+}
+
+// validateGeneric checks the keywords which apply to every type of value
+func (validator *jsonSchemaValidator) validateGeneric(schema map[string]interface{}, value interface{}, fail func(string, ...interface{})) {
+	if types, ok := schema["type"]; ok {
+		allowed := []string{}
+		switch typed := types.(type) {
+		case string:
+			allowed = append(allowed, typed)
+		case []interface{}:
+			for _, item := range typed {
+				allowed = append(allowed, fmt.Sprint(item))
+			}
+		}
+		if !matchesJSONType(value, allowed) {
+			fail("expected type %s but was %s", strings.Join(allowed, " or "), getJSONType(value))
+		}
+	}
+	if enum, ok := schema["enum"].([]interface{}); ok {
+		found := false
+		for _, item := range enum {
+			if reflect.DeepEqual(item, value) {
+				found = true
+				break
+			}
+		}
+		if !found {
+			fail("%s is not one of %s", formatJSONValue(value), formatJSONValue(enum))
+		}
+	}
+	if constant, ok := schema["const"]; ok && !reflect.DeepEqual(constant, value) {
+		fail("expected %s but was %s", formatJSONValue(constant), formatJSONValue(value))
+	}
+}
+
+func validateNumber(schema map[string]interface{}, value float64, fail func(string, ...interface{})) {
+	if minimum, ok := schema["minimum"].(float64); ok && value < minimum {
+		fail("%v is less than the minimum of %v", value, minimum)
+	}
+	if maximum, ok := schema["maximum"].(float64); ok && value > maximum {
+		fail("%v is more than the maximum of %v", value, maximum)
+	}
+	if minimum, ok := schema["exclusiveMinimum"].(float64); ok && value <= minimum {
+		fail("%v must be more than %v", value, minimum)
+	}
+	if maximum, ok := schema["exclusiveMaximum"].(float64); ok && value >= maximum {
+		fail("%v must be less than %v", value, maximum)
+	}
+	if multiple, ok := schema["multipleOf"].(float64); ok && multiple > 0 {
+		if quotient := value / multiple; math.Abs(quotient-math.Round(quotient)) > 1e-9 {
+			fail("%v is not a multiple of %v", value, multiple)
+		}
+	}
+}
+
+func validateString(schema map[string]interface{}, value string, fail func(string, ...interface{})) {
+	length := float64(utf8.RuneCountInString(value))
+	if minimum, ok := schema["minLength"].(float64); ok && length < minimum {
+		fail("'%s' is shorter than the minimum length of %v", value, minimum)
+	}
+	if maximum, ok := schema["maxLength"].(float64); ok && length > maximum {
+		fail("'%s' is longer than the maximum length of %v", value, maximum)
+	}
+	if pattern, ok := schema["pattern"].(string); ok {
+		expression, err := regexp.Compile(pattern)
+		if err != nil {
+			fail("pattern '%s' is not a valid regular expression: %v", pattern, err)
+		} else if !expression.MatchString(value) {
+			fail("'%s' does not match pattern '%s'", value, pattern)
+		}
+	}
+}
+
+func (validator *jsonSchemaValidator) validateArray(schema map[string]interface{}, value []interface{}, path []jsonPathToken, fail func(string, ...interface{})) []string {
+	failures := []string{}
+	length := float64(len(value))
+	if minimum, ok := schema["minItems"].(float64); ok && length < minimum {
+		fail("has %d items but must have at least %v", len(value), minimum)
+	}
+	if maximum, ok := schema["maxItems"].(float64); ok && length > maximum {
+		fail("has %d items but must have at most %v", len(value), maximum)
+	}
+	if unique, ok := schema["uniqueItems"].(bool); ok && unique {
+		for first := range value {
+			for second := first + 1; second < len(value); second++ {
+				if reflect.DeepEqual(value[first], value[second]) {
+					fail("items %d and %d are the same but must be unique", first, second)
+				}
+			}
+		}
+	}
+
+	switch items := schema["items"].(type) {
+	case []interface{}:
+		for index, item := range value {
+			itemPath := appendJSONPath(path, jsonPathToken{index: index, isIndex: true})
+			if index < len(items) {
+				failures = append(failures, validator.validate(items[index], item, itemPath)...)
+			} else if additional, ok := schema["additionalItems"]; ok {
+				failures = append(failures, validator.validate(additional, item, itemPath)...)
+			}
+		}
+	case nil:
+	default:
+		for index, item := range value {
+			failures = append(failures, validator.validate(items, item, appendJSONPath(path, jsonPathToken{index: index, isIndex: true}))...)
+		}
+	}
+
+	if contains, ok := schema["contains"]; ok {
+		found := false
+		for index, item := range value {
+			if len(validator.validate(contains, item, appendJSONPath(path, jsonPathToken{index: index, isIndex: true}))) == 0 {
+				found = true
+				break
+			}
+		}
+		if !found {
+			fail("does not contain an item matching the schema in 'contains'")
+		}
+	}
+	return failures
+}
+
+func (validator *jsonSchemaValidator) validateObject(schema map[string]interface{}, value map[string]interface{}, path []jsonPathToken, fail func(string, ...interface{})) []string {
+	failures := []string{}
+	count := float64(len(value))
+	if minimum, ok := schema["minProperties"].(float64); ok && count < minimum {
+		fail("has %d properties but must have at least %v", len(value), minimum)
+	}
+	if maximum, ok := schema["maxProperties"].(float64); ok && count > maximum {
+		fail("has %d properties but must have at most %v", len(value), maximum)
+	}
+	if required, ok := schema["required"].([]interface{}); ok {
+		for _, name := range required {
+			if _, exists := value[fmt.Sprint(name)]; !exists {
+				fail("is missing required property '%s'", name)
+			}
+		}
+	}
+
+	properties, _ := schema["properties"].(map[string]interface{})
+	patternProperties, _ := schema["patternProperties"].(map[string]interface{})
+	additional, hasAdditional := schema["additionalProperties"]
+	propertyNames, hasPropertyNames := schema["propertyNames"]
+	dependencies, _ := schema["dependencies"].(map[string]interface{})
+	for _, name := range getSortedKeys(value) {
+		propertyPath := appendJSONPath(path, jsonPathToken{key: name})
+		matched := false
+		if property, ok := properties[name]; ok {
+			matched = true
+			failures = append(failures, validator.validate(property, value[name], propertyPath)...)
+		}
+		for _, pattern := range getSortedKeys(patternProperties) {
+			if expression, err := regexp.Compile(pattern); err == nil && expression.MatchString(name) {
+				matched = true
+				failures = append(failures, validator.validate(patternProperties[pattern], value[name], propertyPath)...)
+			}
+		}
+		if !matched && hasAdditional {
+			if allowed, isBool := additional.(bool); isBool && !allowed {
+				fail("has property '%s' which is not allowed", name)
+			} else {
+				failures = append(failures, validator.validate(additional, value[name], propertyPath)...)
+			}
+		}
+		if hasPropertyNames && len(validator.validate(propertyNames, name, propertyPath)) > 0 {
+			fail("has property '%s' whose name does not match 'propertyNames'", name)
+		}
+		switch dependency := dependencies[name].(type) {
+		case []interface{}:
+			for _, required := range dependency {
+				if _, exists := value[fmt.Sprint(required)]; !exists {
+					fail("has property '%s' so must have property '%s'", name, required)
+				}
+			}
+		case nil:
+		default:
+			failures = append(failures, validator.validate(dependency, value, path)...)
+		}
+	}
+	return failures
+}
+
+// validateCombinations checks 'allOf', 'anyOf', 'oneOf', 'not' and 'if', 'then' and 'else'
+func (validator *jsonSchemaValidator) validateCombinations(schema map[string]interface{}, value interface{}, path []jsonPathToken, fail func(string, ...interface{})) []string {
+	failures := []string{}
+	if schemas, ok := schema["allOf"].([]interface{}); ok {
+		for _, subschema := range schemas {
+			failures = append(failures, validator.validate(subschema, value, path)...)
+		}
+	}
+	if schemas, ok := schema["anyOf"].([]interface{}); ok && validator.countMatches(schemas, value, path) == 0 {
+		fail("does not match any of the schemas in 'anyOf'")
+	}
+	if schemas, ok := schema["oneOf"].([]interface{}); ok {
+		if matches := validator.countMatches(schemas, value, path); matches != 1 {
+			fail("matches %d of the schemas in 'oneOf' but must match exactly one", matches)
+		}
+	}
+	if not, ok := schema["not"]; ok && len(validator.validate(not, value, path)) == 0 {
+		fail("must not match the schema in 'not'")
+	}
+	if condition, ok := schema["if"]; ok {
+		if len(validator.validate(condition, value, path)) == 0 {
+			if then, ok := schema["then"]; ok {
+				failures = append(failures, validator.validate(then, value, path)...)
+			}
+		} else if otherwise, ok := schema["else"]; ok {
+			failures = append(failures, validator.validate(otherwise, value, path)...)
+		}
+	}
+	return failures
+}
+
+func (validator *jsonSchemaValidator) countMatches(schemas []interface{}, value interface{}, path []jsonPathToken) int {
+	matches := 0
+	for _, subschema := range schemas {
+		if len(validator.validate(subschema, value, path)) == 0 {
+			matches++
+		}
+	}
+	return matches
+}
+
+// resolveRef returns the part of the schema which the reference points to, such as '#/definitions/address'
+func (validator *jsonSchemaValidator) resolveRef(ref string) (interface{}, error) {
+	if !strings.HasPrefix(ref, "#") {
+		return nil, fmt.Errorf("reference '%s' must point within the schema", ref)
+	}
+	current := validator.root
+	for _, part := range strings.Split(strings.TrimPrefix(strings.TrimPrefix(ref, "#"), "/"), "/") {
+		if part == "" {
+			continue
+		}
+		part = strings.NewReplacer("~1", "/", "~0", "~").Replace(part)
+		switch typed := current.(type) {
+		case map[string]interface{}:
+			next, ok := typed[part]
+			if !ok {
+				return nil, fmt.Errorf("reference '%s' does not exist in the schema", ref)
+			}
+			current = next
+		case []interface{}:
+			index, err := strconv.Atoi(part)
+			if err != nil || index < 0 || index >= len(typed) {
+				return nil, fmt.Errorf("reference '%s' does not exist in the schema", ref)
+			}
+			current = typed[index]
+		default:
+			return nil, fmt.Errorf("reference '%s' does not exist in the schema", ref)
+		}
+	}
+	return current, nil
+}
+
+// getJSONType returns the JSON Schema type of the decoded JSON value
+func getJSONType(value interface{}) string {
+	switch typed := value.(type) {
+	case nil:
+		return "null"
+	case bool:
+		return "boolean"
+	case float64:
+		if typed == math.Trunc(typed) {
+			return "integer"
+		}
+		return "number"
+	case string:
+		return "string"
+	case []interface{}:
+		return "array"
+	default:
+		return "object"
+	}
+}
+
+func matchesJSONType(value interface{}, types []string) bool {
+	actual := getJSONType(value)
+	for _, allowed := range types {
+		if allowed == actual || allowed == "number" && actual == "integer" {
+			return true
+		}
+	}
+	return false
+}
+
+// appendJSONPath returns a new path so that sibling paths do not share the same backing array
+func appendJSONPath(path []jsonPathToken, token jsonPathToken) []jsonPathToken {
+	appended := make([]jsonPathToken, len(path), len(path)+1)
+	copy(appended, path)
+	return append(appended, token)
+}
+
+func getSortedKeys(values map[string]interface{}) []string {
+	keys := make([]string, 0, len(values))
+	for key := range values {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+	return keys
+}
@@ -0,0 +1,117 @@
+package operations
+
+import (
+	"encoding/json"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+const weatherSchema = `{
+  "type": "object",
+  "required": ["city", "temps"],
+  "additionalProperties": false,
+  "properties": {
+    "city": {"type": "string", "minLength": 2, "maxLength": 20, "pattern": "^[A-Z]"},
+    "temps": {"type": "array", "items": {"type": "number", "minimum": -50, "maximum": 50}, "minItems": 1, "maxItems": 3, "uniqueItems": true},
+    "unit": {"enum": ["C", "F"]},
+    "station": {"$ref": "#/definitions/station"},
+    "version": {"const": 1}
+  },
+  "definitions": {
+    "station": {"type": "object", "required": ["id"], "properties": {"id": {"type": "integer", "exclusiveMinimum": 0, "multipleOf": 5}}}
+  }
+}`
+
+func TestValidateJSONSchema(t *testing.T) {
+	tables := []struct {
+		schema   string
+		document string
+		failures []string
+	}{
+		{weatherSchema, `{"city": "Toronto", "temps": [21.5, 19], "unit": "C", "station": {"id": 10}, "version": 1}`, []string{}},
+		{weatherSchema, `{"city": "toronto-and-the-surrounding-area", "temps": [], "unit": "K", "station": {"id": 7.5}, "version": 2, "extra": true}`, []string{
+			"$: has property 'extra' which is not allowed",
+			"$.city: 'toronto-and-the-surrounding-area' is longer than the maximum length of 20",
+			"$.city: 'toronto-and-the-surrounding-area' does not match pattern '^[A-Z]'",
+			"$.station.id: expected type integer but was number",
+			"$.station.id: 7.5 is not a multiple of 5",
+			"$.temps: has 0 items but must have at least 1",
+			"$.unit: \"K\" is not one of [\"C\",\"F\"]",
+			"$.version: expected 1 but was 2",
+		}},
+		{weatherSchema, `{"temps": [19, 19, 60, "hot"]}`, []string{
+			"$: is missing required property 'city'",
+			"$.temps: has 4 items but must have at most 3",
+			"$.temps: items 0 and 1 are the same but must be unique",
+			"$.temps[2]: 60 is more than the maximum of 50",
+			"$.temps[3]: expected type number but was string",
+		}},
+		{weatherSchema, `[]`, []string{"$: expected type object but was array"}},
+		{`{"type": ["string", "null"]}`, `null`, []string{}},
+		{`{"minimum": 1, "exclusiveMaximum": 5}`, `5`, []string{"$: 5 must be less than 5"}},
+		{`{"maximum": 1, "exclusiveMinimum": 0}`, `0`, []string{"$: 0 must be more than 0"}},
+		{`{"minimum": 1}`, `0`, []string{"$: 0 is less than the minimum of 1"}},
+		{`{"minLength": 3}`, `"ab"`, []string{"$: 'ab' is shorter than the minimum length of 3"}},
+		{`{"pattern": "("}`, `"ab"`, []string{"$: pattern '(' is not a valid regular expression: error parsing regexp: missing closing ): `(`"}},
+		{`{"items": [{"type": "string"}], "additionalItems": false}`, `["a", 1]`, []string{"$[1]: is not allowed by the schema"}},
+		{`{"contains": {"const": 2}}`, `[1, 3]`, []string{"$: does not contain an item matching the schema in 'contains'"}},
+		{`{"contains": {"const": 2}}`, `[1, 2]`, []string{}},
+		{`{"minProperties": 2, "maxProperties": 0}`, `{"a": 1}`, []string{"$: has 1 properties but must have at least 2", "$: has 1 properties but must have at most 0"}},
+		{`{"patternProperties": {"^x-": {"type": "string"}}, "additionalProperties": {"type": "number"}}`, `{"x-id": 1, "count": "2"}`, []string{
+			"$.count: expected type number but was string",
+			"$['x-id']: expected type string but was integer",
+		}},
+		{`{"propertyNames": {"maxLength": 3}}`, `{"long": 1}`, []string{"$: has property 'long' whose name does not match 'propertyNames'"}},
+		{`{"dependencies": {"card": ["address"], "vip": {"required": ["level"]}}}`, `{"card": 1, "vip": true}`, []string{
+			"$: has property 'card' so must have property 'address'",
+			"$: is missing required property 'level'",
+		}},
+		{`{"allOf": [{"type": "integer"}, {"minimum": 5}]}`, `3`, []string{"$: 3 is less than the minimum of 5"}},
+		{`{"anyOf": [{"type": "string"}, {"type": "boolean"}]}`, `3`, []string{"$: does not match any of the schemas in 'anyOf'"}},
+		{`{"oneOf": [{"type": "integer"}, {"type": "number"}]}`, `3`, []string{"$: matches 2 of the schemas in 'oneOf' but must match exactly one"}},
+		{`{"not": {"type": "integer"}}`, `3`, []string{"$: must not match the schema in 'not'"}},
+		{`{"if": {"type": "integer"}, "then": {"minimum": 5}, "else": {"type": "string"}}`, `3`, []string{"$: 3 is less than the minimum of 5"}},
+		{`{"if": {"type": "integer"}, "then": {"minimum": 5}, "else": {"type": "string"}}`, `true`, []string{"$: expected type string but was boolean"}},
+		{`{"$ref": "#/definitions/random"}`, `1`, []string{"$: reference '#/definitions/random' does not exist in the schema"}},
+		{`{"$ref": "http://example.com/schema"}`, `1`, []string{"$: reference 'http://example.com/schema' must point within the schema"}},
+		{`{"items": [{"$ref": "#/items/1"}, {"type": "string"}]}`, `[1]`, []string{"$[0]: expected type string but was integer"}},
+		{`{"$ref": "#/items/5", "items": []}`, `1`, []string{"$: reference '#/items/5' does not exist in the schema"}},
+		{`{"$ref": "#"}`, `1`, []string{"$: circular $ref '#'"}},
+		{`{"definitions": {"a": {"$ref": "#/definitions/b"}, "b": {"allOf": [{"$ref": "#/definitions/a"}]}}, "$ref": "#/definitions/a"}`, `1`,
+			[]string{"$: circular $ref '#/definitions/a'"}},
+		{`{"type": "object", "properties": {"child": {"$ref": "#"}}, "required": ["name"]}`, `{"name": "a", "child": {"name": "b", "child": {}}}`,
+			[]string{"$.child.child: is missing required property 'name'"}},
+		{`true`, `1`, []string{}},
+		{`false`, `1`, []string{"$: is not allowed by the schema"}},
+		{`1`, `1`, []string{"$: schema must be an object or a boolean"}},
+	}
+
+	for _, table := range tables {
+		var schema, document interface{}
+		assert.NoError(t, json.Unmarshal([]byte(table.schema), &schema))
+		assert.NoError(t, json.Unmarshal([]byte(table.document), &document))
+		assert.Equal(t, table.failures, validateJSONSchema(schema, document), table.document)
+	}
+}
+
+func TestGetJSONType(t *testing.T) {
+	tables := []struct {
+		document string
+		jsonType string
+	}{
+		{`null`, "null"},
+		{`true`, "boolean"},
+		{`1`, "integer"},
+		{`1.5`, "number"},
+		{`"a"`, "string"},
+		{`[]`, "array"},
+		{`{}`, "object"},
+	}
+
+	for _, table := range tables {
+		var document interface{}
+		assert.NoError(t, json.Unmarshal([]byte(table.document), &document))
+		assert.Equal(t, table.jsonType, getJSONType(document))
+	}
+}
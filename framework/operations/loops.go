@@ -0,0 +1,244 @@
+package operations
+
+import (
+	"fmt"
+	"strconv"
+	"time"
+
+	model "github.com/julianGoh17/simple-e2e/framework/models"
+)
+
+const (
+	// defaultItemVariable is the name of the step variable which holds the current 'forEach' item when the step does not set 'as'
+	defaultItemVariable = "ITEM"
+	// iterationVariable can be used as '${ITERATION}' in a step which uses 'repeat' or 'forEach' to get the number of the current run
+	iterationVariable = "ITERATION"
+	// defaultPollInterval is how long a step which uses 'until' waits between attempts when it does not set 'interval'
+	defaultPollInterval = time.Second
+)
+
+// stepIteration is a single run of a step which uses 'repeat', 'forEach' or 'until'
+type stepIteration struct {
+	number  int
+	item    string
+	hasItem bool
+	looping bool
+}
+
+// variables returns the loop variables which can be used in the step as '${ITERATION}' and '${ITEM}' (or the name given by 'as')
+func (iteration *stepIteration) variables(step *model.Step) map[string]string {
+	variables := map[string]string{}
+	if iteration.looping {
+		variables[iterationVariable] = strconv.Itoa(iteration.number)
+	}
+	if iteration.hasItem {
+		variables[getItemVariable(step)] = iteration.item
+	}
+	return variables
+}
+
+// runStepIterations will run the step once, or once for every 'repeat' and 'forEach' item if it uses them. The first failed iteration stops the
+// remaining iterations.
+func (controller *Controller) runStepIterations(run *testRun, templateStep model.Step, result *model.StageResult) error {
+	items := []string{""}
+	hasItems := templateStep.HasForEach()
+	if hasItems {
+		prepared := run.prepareStep(templateStep, nil)
+		items = prepared.GetForEachItems()
+		if len(items) == 0 {
+			logger.Info().
+				Str("step", templateStep.Description).
+				Msg("Skipping step as it has no items in 'forEach'")
+			result.AddStep(model.StepResult{Description: templateStep.Description, Status: model.Skipped})
+			return nil
+		}
+	}
+	repeat := templateStep.Repeat
+	if repeat < 1 || templateStep.Until != "" {
+		repeat = 1
+	}
+
+	ctx := controller.interrupt.getContext()
+	number := 0
+	for _, item := range items {
+		for count := 0; count < repeat; count++ {
+			if number > 0 && ctx.Err() != nil {
+				return fmt.Errorf("Step '%s' was interrupted after %d iterations", templateStep.Description, number)
+			}
+			number++
+			iteration := &stepIteration{
+				number:  number,
+				item:    item,
+				hasItem: hasItems,
+				looping: hasItems || templateStep.Repeat > 0 && templateStep.Until == "",
+			}
+			if templateStep.Until != "" {
+				if err := controller.pollStep(run, templateStep, iteration, result); err != nil {
+					return err
+				}
+				continue
+			}
+			_, stepResult, err := controller.runStepIteration(run, templateStep, iteration)
+			result.AddStep(stepResult)
+			if err != nil {
+				return err
+			}
+		}
+	}
+	return nil
+}
+
+// pollStep will run the step until its 'until' expression is true, giving up after 'repeat' attempts or once 'within' has passed. Only the result
+// of the last attempt is added to the stage.
+func (controller *Controller) pollStep(run *testRun, templateStep model.Step, iteration *stepIteration, result *model.StageResult) error {
+	within, interval, err := getPollDurations(&templateStep)
+	if err != nil {
+		return err
+	}
+
+	ctx := controller.interrupt.getContext()
+	start := time.Now()
+	for attempt := 1; ; attempt++ {
+		step, stepResult, stepErr := controller.runStepIteration(run, templateStep, iteration)
+		stepResult.Attempts = attempt
+		met, err := evaluateCondition(templateStep.Until, stepErr != nil, false, run.lookup)
+		if err != nil {
+			return err
+		}
+		if met {
+			logger.Info().
+				Str("step", templateStep.Description).
+				Str("until", templateStep.Until).
+				Int("attempts", attempt).
+				Msg("Step has met its 'until' condition")
+			stepResult.Status = model.Passed
+			stepResult.ContinuedOnError = false
+			stepResult.Error = ""
+			run.recordStep(&step, model.Passed)
+			result.AddStep(stepResult)
+			return nil
+		}
+
+		outOfAttempts := templateStep.Repeat > 0 && attempt >= templateStep.Repeat
+		outOfTime := within > 0 && time.Since(start)+interval > within
+		if outOfAttempts || outOfTime || ctx.Err() != nil {
+			err := fmt.Errorf("Step '%s' did not meet condition '%s' after %d attempts", templateStep.Description, templateStep.Until, attempt)
+			if ctx.Err() != nil {
+				err = fmt.Errorf("Step '%s' was interrupted before meeting condition '%s' after %d attempts", templateStep.Description, templateStep.Until, attempt)
+			}
+			if stepErr != nil {
+				err = fmt.Errorf("%v: %v", err, stepErr)
+			}
+			stepResult.Status = model.Failed
+			stepResult.ContinuedOnError = templateStep.ContinueOnError
+			stepResult.Error = err.Error()
+			run.recordStep(&step, model.Failed)
+			result.AddStep(stepResult)
+			return err
+		}
+
+		logger.Debug().
+			Str("step", templateStep.Description).
+			Str("until", templateStep.Until).
+			Int("attempt", attempt).
+			Dur("interval", interval).
+			Msg("Step has not met its 'until' condition, trying again")
+		select {
+		case <-time.After(interval):
+		case <-ctx.Done():
+		}
+	}
+}
+
+// runStepIteration will run a single iteration of the step and record its status and outputs in the test run
+func (controller *Controller) runStepIteration(run *testRun, templateStep model.Step, iteration *stepIteration) (model.Step, model.StepResult, error) {
+	step := run.prepareStep(templateStep, iteration.variables(&templateStep))
+	if iteration.hasItem {
+		step.SetVariable(getItemVariable(&step), iteration.item)
+	}
+	if iteration.looping {
+		logger.Info().
+			Str("step", step.Description).
+			Int("iteration", iteration.number).
+			Str("item", iteration.item).
+			Msg("Running iteration of step")
+	}
+
+	var result model.StepResult
+	function, err := controller.stepManager.GetTestMethod(step.Description)
+	if err != nil {
+		logger.Error().
+			Err(err).
+			Str("step", step.Description).
+			Bool("hasFailed", true).
+			Msg("Could not find step in stage manager")
+		result = model.StepResult{Description: step.Description, Status: model.Failed, ContinuedOnError: step.ContinueOnError, Error: err.Error()}
+	} else {
+		step.SetContext(controller.interrupt.getContext())
+		result, err = runStep(function, &step)
+	}
+	if iteration.looping {
+		result.Iteration = iteration.number
+		result.Item = iteration.item
+	}
+	run.recordStep(&step, result.Status)
+	return step, result, err
+}
+
+// validateLoops will check the 'repeat', 'forEach' and 'until' options of every step in the procedure before the test is run
+func validateLoops(procedure *model.Procedure) error {
+	for _, stage := range procedure.Stages {
+		for _, step := range stage.Steps {
+			if step.Repeat < 0 {
+				return fmt.Errorf("Step '%s' can not repeat a negative number of times", step.Description)
+			}
+			if step.As != "" && !step.HasForEach() {
+				return fmt.Errorf("Step '%s' has 'as' without 'forEach'", step.Description)
+			}
+			if step.Until == "" {
+				if step.Within != "" || step.Interval != "" {
+					return fmt.Errorf("Step '%s' has 'within' or 'interval' without 'until'", step.Description)
+				}
+				continue
+			}
+			if _, err := parseCondition(step.Until); err != nil {
+				return fmt.Errorf("Invalid 'until' expression in step '%s': %v", step.Description, err)
+			}
+			if step.Within == "" && step.Repeat == 0 {
+				return fmt.Errorf("Step '%s' must have 'within' or 'repeat' to limit how long it waits until '%s'", step.Description, step.Until)
+			}
+			if _, _, err := getPollDurations(&step); err != nil {
+				return err
+			}
+		}
+	}
+	return nil
+}
+
+func getPollDurations(step *model.Step) (time.Duration, time.Duration, error) {
+	converter := &model.TypeConverter{}
+	within := time.Duration(0)
+	interval := defaultPollInterval
+	if step.Within != "" {
+		duration, err := converter.GetDuration(step.Within)
+		if err != nil {
+			return 0, 0, fmt.Errorf("Step '%s' has an invalid 'within': %v", step.Description, err)
+		}
+		within = duration
+	}
+	if step.Interval != "" {
+		duration, err := converter.GetDuration(step.Interval)
+		if err != nil {
+			return 0, 0, fmt.Errorf("Step '%s' has an invalid 'interval': %v", step.Description, err)
+		}
+		interval = duration
+	}
+	return within, interval, nil
+}
+
+func getItemVariable(step *model.Step) string {
+	if step.As != "" {
+		return step.As
+	}
+	return defaultItemVariable
+}
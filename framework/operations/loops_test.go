@@ -0,0 +1,197 @@
+package operations
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"testing"
+
+	"github.com/julianGoh17/simple-e2e/framework/internal"
+	model "github.com/julianGoh17/simple-e2e/framework/models"
+	"github.com/julianGoh17/simple-e2e/framework/util"
+	"github.com/stretchr/testify/assert"
+)
+
+const loopingSteps = `
+name: example-test
+description: example description
+globalVariables:
+  ENDPOINTS: "/health, /metrics"
+stages:
+  - name: example-stage
+    steps:
+      - description: "record-step"
+        repeat: 2
+        variables:
+          VALUE: "repeat ${ITERATION}"
+      - description: "record-step"
+        forEach: ["first", "second"]
+        as: NAME
+        variables:
+          VALUE: "${NAME} ${ITERATION}"
+      - description: "record-step"
+        forEach: "${ENDPOINTS}"
+        repeat: 2
+        variables:
+          VALUE: "${ITEM}"
+      - description: "record-step"
+        forEach: []
+`
+
+const failingLoop = `
+name: example-test
+description: example description
+stages:
+  - name: example-stage
+    steps:
+      - description: "fail-on-second"
+        forEach: [first, second, third]
+`
+
+const pollingSteps = `
+name: example-test
+description: example description
+stages:
+  - name: example-stage
+    steps:
+      - id: ready
+        description: "count-step"
+        until: steps.ready.outputs.COUNT == '3'
+        within: 1s
+        interval: 1ms
+      - description: "fail-step"
+        until: success()
+        repeat: 2
+        interval: 1ms
+`
+
+func TestRunStepIterations(t *testing.T) {
+	controller, err := NewController()
+	assert.NoError(t, err)
+
+	values := []string{}
+	assert.NoError(t, controller.AddTestStep("record-step", func(step *model.Step) error {
+		value, err := step.GetValueFromVariablesAsString("VALUE")
+		values = append(values, value)
+		step.SetErrored(err)
+		return err
+	}))
+
+	assert.NoError(t, controller.runTest([]byte(loopingSteps)))
+	assert.Equal(t, []string{"repeat 1", "repeat 2", "first 1", "second 2", "/health", "/health", "/metrics", "/metrics"}, values)
+
+	steps := controller.GetResults()[0].Stages[0].Steps
+	assert.Equal(t, 9, len(steps))
+	assert.Equal(t, 2, steps[1].Iteration)
+	assert.Equal(t, "", steps[1].Item)
+	assert.Equal(t, 1, steps[2].Iteration)
+	assert.Equal(t, "first", steps[2].Item)
+	assert.Equal(t, 4, steps[7].Iteration)
+	assert.Equal(t, "/metrics", steps[7].Item)
+	assert.Equal(t, model.Skipped, steps[8].Status)
+}
+
+func TestRunStepIterationsStopsAtFailure(t *testing.T) {
+	controller, err := NewController()
+	assert.NoError(t, err)
+
+	assert.NoError(t, controller.AddTestStep("fail-on-second", func(step *model.Step) error {
+		item, _ := step.GetValueFromVariablesAsString("ITEM")
+		if item == "second" {
+			return errors.New("This will error")
+		}
+		step.SetPassed()
+		return nil
+	}))
+
+	assert.Error(t, controller.runTest([]byte(failingLoop)))
+	steps := controller.GetResults()[0].Stages[0].Steps
+	assert.Equal(t, 2, len(steps))
+	assert.Equal(t, model.Passed, steps[0].Status)
+	assert.Equal(t, model.Failed, steps[1].Status)
+	assert.Equal(t, "second", steps[1].Item)
+}
+
+func TestPollStep(t *testing.T) {
+	controller, err := NewController()
+	assert.NoError(t, err)
+
+	count := 0
+	assert.NoError(t, controller.AddTestStep("count-step", func(step *model.Step) error {
+		count++
+		step.SetOutput("COUNT", fmt.Sprint(count))
+		if count%2 == 0 {
+			return errors.New("Not ready yet")
+		}
+		step.SetPassed()
+		return nil
+	}))
+	assert.NoError(t, controller.AddTestStep("fail-step", testFuncFailStep))
+
+	err = controller.runTest([]byte(pollingSteps))
+	assert.Error(t, err)
+
+	steps := controller.GetResults()[0].Stages[0].Steps
+	assert.Equal(t, 2, len(steps))
+	assert.Equal(t, model.Passed, steps[0].Status)
+	assert.Equal(t, 3, steps[0].Attempts)
+	assert.Equal(t, model.Failed, steps[1].Status)
+	assert.Equal(t, 2, steps[1].Attempts)
+	assert.Equal(t, "Step 'fail-step' did not meet condition 'success()' after 2 attempts: Step 'fail-step' has failed", steps[1].Error)
+}
+
+func TestPollStepGivesUpAfterWithin(t *testing.T) {
+	controller, err := NewController()
+	assert.NoError(t, err)
+	assert.NoError(t, controller.AddTestStep("fail-step", testFuncFailStep))
+
+	err = controller.runTest([]byte(`
+name: example-test
+description: example description
+stages:
+  - name: example-stage
+    steps:
+      - description: "fail-step"
+        until: success()
+        within: 50ms
+        interval: 10ms
+`))
+	assert.Error(t, err)
+	steps := controller.GetResults()[0].Stages[0].Steps
+	assert.Equal(t, 1, len(steps))
+	assert.True(t, steps[0].Attempts > 1 && steps[0].Attempts <= 5)
+}
+
+func TestValidateLoops(t *testing.T) {
+	tables := []struct {
+		step model.Step
+		err  string
+	}{
+		{model.Step{Description: "step", Repeat: -1}, "Step 'step' can not repeat a negative number of times"},
+		{model.Step{Description: "step", As: "NAME"}, "Step 'step' has 'as' without 'forEach'"},
+		{model.Step{Description: "step", Within: "1s"}, "Step 'step' has 'within' or 'interval' without 'until'"},
+		{model.Step{Description: "step", Until: "READY =="}, "Invalid 'until' expression in step 'step': Unexpected end of expression 'READY =='"},
+		{model.Step{Description: "step", Until: "READY"}, "Step 'step' must have 'within' or 'repeat' to limit how long it waits until 'READY'"},
+		{model.Step{Description: "step", Until: "READY", Within: "soon"}, "Step 'step' has an invalid 'within': Could not convert 'soon' to type 'time.Duration'"},
+		{model.Step{Description: "step", Until: "READY", Repeat: 3, Interval: "often"}, "Step 'step' has an invalid 'interval': Could not convert 'often' to type 'time.Duration'"},
+		{model.Step{Description: "step", Until: "READY", Repeat: 3, Interval: "1s"}, ""},
+	}
+
+	for _, table := range tables {
+		err := validateLoops(&model.Procedure{Stages: []model.Stage{{Name: "stage", Steps: []model.Step{table.step}}}})
+		if table.err == "" {
+			assert.NoError(t, err)
+		} else if assert.Error(t, err) {
+			assert.Equal(t, table.err, err.Error())
+		}
+	}
+}
+
+func TestRunTestWithExampleLoops(t *testing.T) {
+	internal.SetTestFilesRoot()
+	controller, err := NewController()
+	assert.NoError(t, err)
+
+	assert.NoError(t, controller.RunTest(fmt.Sprintf("%s/examples/loops-test.yaml", os.Getenv(util.TestDirEnv))))
+	assert.Equal(t, 5, len(controller.GetResults()[0].Stages[0].Steps))
+}
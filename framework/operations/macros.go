@@ -0,0 +1,94 @@
+package operations
+
+import (
+	"fmt"
+	"strings"
+
+	model "github.com/julianGoh17/simple-e2e/framework/models"
+)
+
+// expandMacros will replace every step in the procedure which calls a macro with the macro's steps so that each step is logged and reported
+// individually
+func expandMacros(procedure *model.Procedure) error {
+	for index := range procedure.Stages {
+		steps, err := expandSteps(procedure, procedure.Stages[index].Steps, []string{})
+		if err != nil {
+			logger.Error().
+				Err(err).
+				Str("stage", procedure.Stages[index].Name).
+				Msg("Failed to expand macros in stage")
+			return err
+		}
+		procedure.Stages[index].Steps = steps
+	}
+	return nil
+}
+
+// expandSteps will return the steps with every macro call expanded. 'chain' is the list of macros currently being expanded and is used to detect
+// macros which call themselves.
+func expandSteps(procedure *model.Procedure, steps []model.Step, chain []string) ([]model.Step, error) {
+	expanded := []model.Step{}
+	for _, step := range steps {
+		if step.Call == "" {
+			expanded = append(expanded, step)
+			continue
+		}
+		macroSteps, err := expandCall(procedure, step, chain)
+		if err != nil {
+			return nil, err
+		}
+		expanded = append(expanded, macroSteps...)
+	}
+	return expanded, nil
+}
+
+func expandCall(procedure *model.Procedure, step model.Step, chain []string) ([]model.Step, error) {
+	logger.Trace().
+		Str("macro", step.Call).
+		Interface("arguments", step.With).
+		Msg("Expanding macro")
+	for _, called := range chain {
+		if called == step.Call {
+			return nil, fmt.Errorf("Macro cycle detected: %s -> %s", strings.Join(chain, " -> "), step.Call)
+		}
+	}
+	macro, ok := procedure.Macros[step.Call]
+	if !ok {
+		return nil, fmt.Errorf("Could not find macro '%s'", step.Call)
+	}
+	if step.Description != "" || step.Template != "" || step.ID != "" || len(step.Variables) > 0 {
+		return nil, fmt.Errorf("Step which calls macro '%s' can not have a description, template, id or variables", step.Call)
+	}
+
+	arguments := step.GetArguments()
+	parameters := make(map[string]bool, len(macro.Parameters))
+	for _, parameter := range macro.Parameters {
+		if _, ok := arguments[parameter]; !ok {
+			return nil, fmt.Errorf("Macro '%s' is missing argument '%s'", step.Call, parameter)
+		}
+		parameters[parameter] = true
+	}
+	for argument := range arguments {
+		if !parameters[argument] {
+			return nil, fmt.Errorf("Macro '%s' does not have parameter '%s'", step.Call, argument)
+		}
+	}
+
+	lookup := func(name string) (interface{}, bool) {
+		value, ok := arguments[name]
+		return value, ok
+	}
+	steps := []model.Step{}
+	for _, macroStep := range macro.Steps {
+		expanded := macroStep.Clone()
+		if err := applyStepTemplate(procedure, &expanded); err != nil {
+			return nil, err
+		}
+		expanded.Interpolate(lookup)
+		expanded.If = combineConditions(step.If, expanded.If)
+		expanded.AlwaysRuns = expanded.AlwaysRuns || step.AlwaysRuns
+		expanded.ContinueOnError = expanded.ContinueOnError || step.ContinueOnError
+		steps = append(steps, expanded)
+	}
+	return expandSteps(procedure, steps, append(chain, step.Call))
+}
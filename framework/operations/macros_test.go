@@ -0,0 +1,223 @@
+package operations
+
+import (
+	"fmt"
+	"io/ioutil"
+	"os"
+	"testing"
+
+	"github.com/julianGoh17/simple-e2e/framework/internal"
+	"github.com/julianGoh17/simple-e2e/framework/util"
+	"github.com/stretchr/testify/assert"
+)
+
+const nestedMacros = `
+name: example-test
+description: example description
+stepTemplates:
+  example:
+    description: example-step
+    variables:
+      IMAGE: "${image}"
+macros:
+  startService:
+    parameters: [name, image, ports]
+    steps:
+      - description: "Create container"
+        variables:
+          CONTAINER_NAME: "${name}"
+          IMAGE: "${image}"
+          PORTS: "${ports}"
+      - call: pullImage
+        with:
+          image: "${image}"
+  pullImage:
+    parameters: [image]
+    steps:
+      - template: example
+stages:
+  - name: example-stage
+    steps:
+      - call: startService
+        with:
+          name: db
+          image: postgres
+          ports: [5432, 5433]
+`
+
+const macroCycle = `
+name: example-test
+description: example description
+macros:
+  first:
+    steps:
+      - call: second
+  second:
+    steps:
+      - call: first
+stages:
+  - name: example-stage
+    steps:
+      - call: first
+`
+
+const macroMissingArgument = `
+name: example-test
+description: example description
+macros:
+  example:
+    parameters: [name]
+    steps:
+      - description: example-step
+stages:
+  - name: example-stage
+    steps:
+      - call: example
+`
+
+const macroUnknownArgument = `
+name: example-test
+description: example description
+macros:
+  example:
+    steps:
+      - description: example-step
+stages:
+  - name: example-stage
+    steps:
+      - call: example
+        with:
+          name: random
+`
+
+const macroCallWithVariables = `
+name: example-test
+description: example description
+macros:
+  example:
+    steps:
+      - description: example-step
+stages:
+  - name: example-stage
+    steps:
+      - call: example
+        variables:
+          NAME: random
+`
+
+const conditionalMacro = `
+name: example-test
+description: example description
+macros:
+  example:
+    steps:
+      - description: first-step
+      - description: second-step
+        if: failure()
+stages:
+  - name: example-stage
+    steps:
+      - call: example
+        if: DEBUG == 'true'
+        continueOnError: true
+`
+
+const missingMacro = `
+name: example-test
+description: example description
+stages:
+  - name: example-stage
+    steps:
+      - call: non-existent
+`
+
+func TestSetProcedureExpandsMacros(t *testing.T) {
+	internal.SetTestFilesRoot()
+	controller, err := NewController()
+	assert.NoError(t, err)
+
+	body, err := ioutil.ReadFile(fmt.Sprintf("%s/examples/macro-test.yaml", os.Getenv(util.TestDirEnv)))
+	assert.NoError(t, err)
+	assert.NoError(t, controller.SetProcedure(body))
+
+	steps := controller.procedure.Stages[0].Steps
+	assert.Equal(t, 5, len(steps))
+	expectedNames := []string{"Julian", "Coachella (after Julian)", "Eugene", "Boy", "${unknown} (after Boy)"}
+	for index, step := range steps {
+		assert.Equal(t, "Say hello to", step.Description)
+		assert.Equal(t, expectedNames[index], step.Variables["NAME"])
+		assert.Equal(t, controller.docker, step.Docker)
+	}
+
+	assert.NoError(t, controller.runTest(body))
+}
+
+func TestSetProcedureExpandsNestedMacros(t *testing.T) {
+	controller, err := NewController()
+	assert.NoError(t, err)
+	assert.NoError(t, controller.SetProcedure([]byte(nestedMacros)))
+
+	steps := controller.procedure.Stages[0].Steps
+	assert.Equal(t, 2, len(steps))
+
+	assert.Equal(t, "Create container", steps[0].Description)
+	assert.Equal(t, map[string]string{"CONTAINER_NAME": "db", "IMAGE": "postgres", "PORTS": "5432,5433"}, steps[0].Variables)
+	ports, err := steps[0].GetValueFromVariablesAsIntegerArray("PORTS")
+	assert.NoError(t, err)
+	assert.Equal(t, []int{5432, 5433}, ports)
+
+	assert.Equal(t, "example-step", steps[1].Description)
+	assert.Equal(t, map[string]string{"IMAGE": "postgres"}, steps[1].Variables)
+}
+
+func TestSetProcedureAddsConditionOfMacroCall(t *testing.T) {
+	controller, err := NewController()
+	assert.NoError(t, err)
+	assert.NoError(t, controller.SetProcedure([]byte(conditionalMacro)))
+
+	steps := controller.procedure.Stages[0].Steps
+	assert.Equal(t, 2, len(steps))
+	assert.Equal(t, "DEBUG == 'true'", steps[0].If)
+	assert.Equal(t, "(DEBUG == 'true') && (failure())", steps[1].If)
+	for _, step := range steps {
+		assert.True(t, step.ContinueOnError)
+		assert.False(t, step.AlwaysRuns)
+	}
+}
+
+func TestSetProcedureFailsToExpandMacros(t *testing.T) {
+	testCases := []struct {
+		testFile string
+		err      error
+	}{
+		{
+			macroCycle,
+			fmt.Errorf("Macro cycle detected: first -> second -> first"),
+		},
+		{
+			macroMissingArgument,
+			fmt.Errorf("Macro 'example' is missing argument 'name'"),
+		},
+		{
+			macroUnknownArgument,
+			fmt.Errorf("Macro 'example' does not have parameter 'name'"),
+		},
+		{
+			macroCallWithVariables,
+			fmt.Errorf("Step which calls macro 'example' can not have a description, template, id or variables"),
+		},
+		{
+			missingMacro,
+			fmt.Errorf("Could not find macro 'non-existent'"),
+		},
+	}
+
+	for _, testCase := range testCases {
+		controller, err := NewController()
+		assert.NoError(t, err)
+
+		err = controller.SetProcedure([]byte(testCase.testFile))
+		assert.Error(t, err)
+		assert.Equal(t, testCase.err.Error(), err.Error())
+	}
+}
@@ -0,0 +1,247 @@
+package operations
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/url"
+	"strconv"
+	"strings"
+
+	"github.com/julianGoh17/simple-e2e/framework/mock"
+	"github.com/julianGoh17/simple-e2e/framework/models"
+)
+
+// CreateMockEndpoint will start a mock HTTP server, or reuse the one already running on the port, and add routes to it. Mock servers are stopped
+// once the test has finished. The URL of the server is saved as the output 'url' and its port as 'port'.
+// Environmental Variables:
+//   - URL: The address of the server such as 'localhost:8080/v1/weather'. A path in the URL adds a route which responds with a 200 to it.
+//   - PORT: The port of the server, which is used instead of URL. A port of 0 uses any free port.
+//   - ROUTES: A list of routes, each with a 'path' which can use '*', and optionally a 'method', 'status', 'body', 'headers' and 'delay'
+func CreateMockEndpoint(step *models.Step) error {
+	traceStepEntrance(step)
+	port, path, err := getMockAddress(step)
+	if err != nil {
+		return traceStepExit(step, err)
+	}
+	routes, err := getMockRoutes(step)
+	if err != nil {
+		return traceStepExit(step, err)
+	}
+
+	server, err := step.Mocks.Start(port)
+	if err != nil {
+		return traceStepExit(step, err)
+	}
+	if path != "" {
+		server.AddRoute(mock.Route{Path: path})
+	}
+	for _, route := range routes {
+		if route.Path == "" {
+			return traceStepExit(step, fmt.Errorf("Route of mock server on port %d must have a 'path'", server.GetPort()))
+		}
+		server.AddRoute(route)
+	}
+	step.SetOutput("url", server.GetURL())
+	step.SetOutput("port", strconv.Itoa(server.GetPort()))
+	return traceStepExit(step, nil)
+}
+
+// LoadMockData will set the response of a route of a running mock server, adding the route if it does not exist
+// Environmental Variables:
+//   - URL: The address and path of the route such as 'localhost:8080/v1/weather'
+//   - PORT and PATH: The port of the server and the path of the route, which are used instead of URL
+//   - PAYLOAD: The body of the response. A YAML map or list is sent as JSON with the 'application/json' content type
+//   - METHOD: The method of the requests which the route responds to (default every method)
+//   - STATUS: The status code of the response (default 200)
+//   - HEADERS: A map of headers to send with the response
+//   - DELAY: How long to wait before responding, such as '500ms'
+func LoadMockData(step *models.Step) error {
+	traceStepEntrance(step)
+	port, path, err := getMockAddress(step)
+	if err != nil {
+		return traceStepExit(step, err)
+	}
+	if path == "" {
+		return traceStepExit(step, fmt.Errorf("Step '%s' must have a path in 'URL' or 'PATH'", step.Description))
+	}
+	server, err := step.Mocks.Get(port)
+	if err != nil {
+		return traceStepExit(step, err)
+	}
+
+	route := mock.Route{Path: path, Method: step.GetValueFromVariablesAsStringOrDefault("METHOD", "")}
+	if route.Status, err = step.GetValueFromVariablesAsIntegerOrDefault("STATUS", 0); err != nil {
+		return traceStepExit(step, err)
+	}
+	if route.Headers, err = step.GetValueFromVariablesAsMapOrDefault("HEADERS", map[string]string{}); err != nil {
+		return traceStepExit(step, err)
+	}
+	if route.Delay, err = step.GetValueFromVariablesAsDurationOrDefault("DELAY", 0); err != nil {
+		return traceStepExit(step, err)
+	}
+	if payload, err := step.GetValueFromVariables("PAYLOAD"); err == nil {
+		if err := setMockBody(&route, payload); err != nil {
+			return traceStepExit(step, err)
+		}
+	}
+	server.AddRoute(route)
+	return traceStepExit(step, nil)
+}
+
+// AssertMockReceivedRequests will check how many requests a mock server has received. The number of matching requests is saved as the output
+// 'count' and the body of the last one as 'body'.
+// Environmental Variables:
+//   - URL: The address and path of the requests such as 'localhost:8080/v1/weather'
+//   - PORT and PATH: The port of the server and the path of the requests, which are used instead of URL. The path can use '*'.
+//   - METHOD: The method of the requests (default every method)
+//   - COUNT: The number of requests which the server must have received (default at least one)
+func AssertMockReceivedRequests(step *models.Step) error {
+	traceStepEntrance(step)
+	port, path, err := getMockAddress(step)
+	if err != nil {
+		return traceStepExit(step, err)
+	}
+	if path == "" {
+		path = "*"
+	}
+	server, err := step.Mocks.Get(port)
+	if err != nil {
+		return traceStepExit(step, err)
+	}
+	count, err := step.GetValueFromVariablesAsIntegerOrDefault("COUNT", -1)
+	if err != nil {
+		return traceStepExit(step, err)
+	}
+
+	method := step.GetValueFromVariablesAsStringOrDefault("METHOD", "")
+	requests := server.GetRequests(method, path)
+	step.SetOutput("count", strconv.Itoa(len(requests)))
+	if len(requests) > 0 {
+		step.SetOutput("body", requests[len(requests)-1].Body)
+	}
+
+	if count == len(requests) || count < 0 && len(requests) > 0 {
+		return traceStepExit(step, nil)
+	}
+	expected := "at least 1"
+	if count >= 0 {
+		expected = strconv.Itoa(count)
+	}
+	received := []string{}
+	for _, request := range server.GetRequests("", "*") {
+		received = append(received, fmt.Sprintf("%s %s", request.Method, request.Path))
+	}
+	if method == "" {
+		method = "any"
+	}
+	return traceStepExit(step, fmt.Errorf("Mock server on port %d received %d %s requests to '%s' but expected %s. It received: [%s]",
+		port, len(requests), method, path, expected, strings.Join(received, ", ")))
+}
+
+// StopMockEndpoint will stop a running mock server before the test has finished
+// Environmental Variables:
+//   - URL: The address of the server such as 'localhost:8080'
+//   - PORT: The port of the server, which is used instead of URL
+func StopMockEndpoint(step *models.Step) error {
+	traceStepEntrance(step)
+	port, _, err := getMockAddress(step)
+	if err != nil {
+		return traceStepExit(step, err)
+	}
+	return traceStepExit(step, step.Mocks.Stop(port))
+}
+
+// mockRoute is a route of a mock server as it is written in the 'ROUTES' variable
+type mockRoute struct {
+	Method  string            `yaml:"method,omitempty"`
+	Path    string            `yaml:"path"`
+	Status  int               `yaml:"status,omitempty"`
+	Body    interface{}       `yaml:"body,omitempty"`
+	Headers map[string]string `yaml:"headers,omitempty"`
+	Delay   string            `yaml:"delay,omitempty"`
+}
+
+// getMockRoutes returns the routes in the 'ROUTES' variable, whose delays can be any duration the step variables can have such as '500ms'
+// or '1.5 seconds'
+func getMockRoutes(step *models.Step) ([]mock.Route, error) {
+	routes := []mock.Route{}
+	if _, ok := step.Variables["ROUTES"]; !ok {
+		return routes, nil
+	}
+	written := []mockRoute{}
+	if err := step.GetValueFromVariablesInto("ROUTES", &written); err != nil {
+		return nil, err
+	}
+
+	converter := models.TypeConverter{}
+	for _, value := range written {
+		route := mock.Route{Method: value.Method, Path: value.Path, Status: value.Status, Headers: value.Headers}
+		if err := setMockBody(&route, value.Body); err != nil {
+			return nil, err
+		}
+		if value.Delay != "" {
+			delay, err := converter.GetDuration(value.Delay)
+			if err != nil {
+				return nil, fmt.Errorf("Delay of route '%s' must be a duration such as '500ms': %v", route.Path, err)
+			}
+			route.Delay = delay
+		}
+		routes = append(routes, route)
+	}
+	return routes, nil
+}
+
+// setMockBody sets the body of the route to the value written in a test file. Text is used as it is while YAML maps and lists are sent as
+// JSON with the 'application/json' content type, unless the route already has a content type.
+func setMockBody(route *mock.Route, value interface{}) error {
+	switch typed := models.NormalizeVariable(value).(type) {
+	case nil:
+		route.Body = ""
+	case string:
+		route.Body = typed
+	case map[string]interface{}, []interface{}:
+		bytes, err := json.Marshal(typed)
+		if err != nil {
+			return fmt.Errorf("Could not convert body of route '%s' to JSON: %v", route.Path, err)
+		}
+		route.Body = string(bytes)
+		if route.Headers == nil {
+			route.Headers = make(map[string]string)
+		}
+		if _, ok := route.Headers["Content-Type"]; !ok {
+			route.Headers["Content-Type"] = "application/json"
+		}
+	default:
+		route.Body = fmt.Sprint(typed)
+	}
+	return nil
+}
+
+// getMockAddress returns the port of the mock server and the path from 'PORT' and 'PATH', or from 'URL' when 'PORT' is not set
+func getMockAddress(step *models.Step) (int, string, error) {
+	path := step.GetValueFromVariablesAsStringOrDefault("PATH", "")
+	if _, ok := step.Variables["PORT"]; ok {
+		port, err := step.GetValueFromVariablesAsInteger("PORT")
+		return port, path, err
+	}
+
+	rawURL, err := step.GetValueFromVariablesAsString("URL")
+	if err != nil {
+		return 0, "", fmt.Errorf("Step '%s' must have 'URL' or 'PORT'", step.Description)
+	}
+	if !strings.Contains(rawURL, "://") {
+		rawURL = "http://" + rawURL
+	}
+	parsed, err := url.Parse(rawURL)
+	if err != nil {
+		return 0, "", fmt.Errorf("Could not read URL '%s' of mock server: %v", rawURL, err)
+	}
+	port, err := strconv.Atoi(parsed.Port())
+	if err != nil {
+		return 0, "", fmt.Errorf("URL '%s' of mock server must have a port", rawURL)
+	}
+	if path == "" {
+		path = parsed.Path
+	}
+	return port, path, nil
+}
@@ -0,0 +1,243 @@
+package operations
+
+import (
+	"fmt"
+	"io/ioutil"
+	"net/http"
+	"testing"
+	"time"
+
+	"github.com/julianGoh17/simple-e2e/framework/mock"
+	"github.com/julianGoh17/simple-e2e/framework/models"
+	"github.com/stretchr/testify/assert"
+)
+
+func newMockStep(t *testing.T, mocks *mock.Manager, description, variables string) *models.Step {
+	step := newStep(t, description, variables)
+	step.Mocks = mocks
+	return step
+}
+
+func getMockResponse(t *testing.T, url string) (int, string, string) {
+	response, err := http.Get(url)
+	if !assert.NoError(t, err) {
+		return 0, "", ""
+	}
+	defer response.Body.Close()
+	body, err := ioutil.ReadAll(response.Body)
+	assert.NoError(t, err)
+	return response.StatusCode, string(body), response.Header.Get("Content-Type")
+}
+
+func TestCreateMockEndpoint(t *testing.T) {
+	mocks := mock.NewManager()
+	defer mocks.StopAll()
+
+	step := newMockStep(t, mocks, "Create mock API endpoint", `
+  PORT: 0
+  ROUTES:
+    - path: /v1/weather
+      body: {city: Toronto}
+    - path: /v1/users/*
+      method: DELETE
+      status: 204`)
+	assert.NoError(t, CreateMockEndpoint(step))
+	assert.True(t, step.HasSucceeded())
+	url := step.GetOutputs()["url"]
+	assert.Equal(t, fmt.Sprintf("http://localhost:%s", step.GetOutputs()["port"]), url)
+
+	status, body, contentType := getMockResponse(t, url+"/v1/weather")
+	assert.Equal(t, 200, status)
+	assert.Equal(t, `{"city":"Toronto"}`, body)
+	assert.Equal(t, "application/json", contentType)
+
+	request, err := http.NewRequest(http.MethodDelete, url+"/v1/users/1", nil)
+	assert.NoError(t, err)
+	response, err := http.DefaultClient.Do(request)
+	if assert.NoError(t, err) {
+		response.Body.Close()
+		assert.Equal(t, 204, response.StatusCode)
+	}
+
+	port := step.GetOutputs()["port"]
+	step = newMockStep(t, mocks, "Create mock API endpoint", fmt.Sprintf("  URL: localhost:%s/v1/health", port))
+	assert.NoError(t, CreateMockEndpoint(step))
+	assert.Equal(t, url, step.GetOutputs()["url"])
+	status, _, _ = getMockResponse(t, url+"/v1/health")
+	assert.Equal(t, 200, status)
+}
+
+func TestCreateMockEndpointFails(t *testing.T) {
+	mocks := mock.NewManager()
+	defer mocks.StopAll()
+
+	tables := []struct {
+		variables string
+		err       string
+	}{
+		{"  RETRIES: 1", "Step 'Create mock API endpoint' must have 'URL' or 'PORT'"},
+		{"  URL: localhost/v1/weather", "URL 'http://localhost/v1/weather' of mock server must have a port"},
+		{"  URL: 'http://%zz'", "Could not read URL 'http://%zz' of mock server"},
+		{"  PORT: 0\n  ROUTES:\n    - status: 200", "Route of mock server on port"},
+		{"  PORT: 0\n  ROUTES:\n    - path: /\n      delay: soon", "Delay of route '/' must be a duration such as '500ms'"},
+	}
+
+	for _, table := range tables {
+		step := newMockStep(t, mocks, "Create mock API endpoint", table.variables)
+		err := CreateMockEndpoint(step)
+		if assert.Error(t, err, table.variables) {
+			assert.Contains(t, err.Error(), table.err)
+		}
+		assert.False(t, step.HasSucceeded())
+	}
+}
+
+func TestLoadMockData(t *testing.T) {
+	mocks := mock.NewManager()
+	defer mocks.StopAll()
+	server, err := mocks.Start(0)
+	assert.NoError(t, err)
+	address := fmt.Sprintf("localhost:%d", server.GetPort())
+
+	tables := []struct {
+		variables   string
+		status      int
+		body        string
+		contentType string
+		err         string
+	}{
+		{fmt.Sprintf("  URL: %s/v1/weather\n  PAYLOAD: '{\"some\":\"data\"}'", address), 200, `{"some":"data"}`, "text/plain; charset=utf-8", ""},
+		{fmt.Sprintf("  URL: %s/v1/weather\n  PAYLOAD: {city: Toronto}\n  STATUS: 201", address), 201, `{"city":"Toronto"}`, "application/json", ""},
+		{fmt.Sprintf("  PORT: %d\n  PATH: /v1/weather\n  PAYLOAD: hot\n  HEADERS: {Content-Type: text/html}", server.GetPort()), 200, "hot", "text/html", ""},
+		{fmt.Sprintf("  URL: %s", address), 0, "", "", "Step 'Load fake data to mock API endpoint' must have a path in 'URL' or 'PATH'"},
+		{"  URL: localhost:1/v1/weather", 0, "", "", "Could not find mock server on port 1"},
+		{fmt.Sprintf("  URL: %s/v1/weather\n  STATUS: ok", address), 0, "", "", "Could not convert 'ok' to type 'int'"},
+	}
+
+	for _, table := range tables {
+		step := newMockStep(t, mocks, "Load fake data to mock API endpoint", table.variables)
+		err := LoadMockData(step)
+		if table.err != "" {
+			if assert.Error(t, err, table.variables) {
+				assert.Contains(t, err.Error(), table.err)
+			}
+			assert.False(t, step.HasSucceeded())
+			continue
+		}
+		assert.NoError(t, err, table.variables)
+		status, body, contentType := getMockResponse(t, server.GetURL()+"/v1/weather")
+		assert.Equal(t, table.status, status)
+		assert.Equal(t, table.body, body)
+		assert.Equal(t, table.contentType, contentType)
+	}
+}
+
+func TestAssertMockReceivedRequests(t *testing.T) {
+	mocks := mock.NewManager()
+	defer mocks.StopAll()
+	server, err := mocks.Start(0)
+	assert.NoError(t, err)
+	server.AddRoute(mock.Route{Path: "*"})
+	getMockResponse(t, server.GetURL()+"/v1/weather")
+	response, err := http.Post(server.GetURL()+"/v1/weather", "text/plain", nil)
+	if assert.NoError(t, err) {
+		response.Body.Close()
+	}
+	getMockResponse(t, server.GetURL()+"/v1/users/1")
+
+	tables := []struct {
+		variables string
+		count     string
+		err       string
+	}{
+		{"  PATH: /v1/weather\n  COUNT: 2", "2", ""},
+		{"  PATH: /v1/weather\n  METHOD: POST\n  COUNT: 1", "1", ""},
+		{"  PATH: /v1/users/*", "1", ""},
+		{"  COUNT: 3", "3", ""},
+		{"  PATH: /v1/weather\n  COUNT: 0", "2", "received 2 any requests to '/v1/weather' but expected 0. It received: [GET /v1/weather, POST /v1/weather, GET /v1/users/1]"},
+		{"  PATH: /v2/weather\n  METHOD: GET", "0", "received 0 GET requests to '/v2/weather' but expected at least 1"},
+	}
+
+	for _, table := range tables {
+		step := newMockStep(t, mocks, "Assert mock received requests", fmt.Sprintf("  PORT: %d\n%s", server.GetPort(), table.variables))
+		err := AssertMockReceivedRequests(step)
+		assert.Equal(t, table.count, step.GetOutputs()["count"])
+		if table.err != "" {
+			if assert.Error(t, err, table.variables) {
+				assert.Contains(t, err.Error(), table.err)
+			}
+			assert.False(t, step.HasSucceeded())
+			continue
+		}
+		assert.NoError(t, err, table.variables)
+		assert.True(t, step.HasSucceeded())
+	}
+}
+
+func TestStopMockEndpoint(t *testing.T) {
+	mocks := mock.NewManager()
+	server, err := mocks.Start(0)
+	assert.NoError(t, err)
+
+	step := newMockStep(t, mocks, "Stop mock API endpoint", fmt.Sprintf("  URL: localhost:%d", server.GetPort()))
+	assert.NoError(t, StopMockEndpoint(step))
+	assert.True(t, step.HasSucceeded())
+	_, err = http.Get(server.GetURL())
+	assert.Error(t, err)
+
+	step = newMockStep(t, mocks, "Stop mock API endpoint", fmt.Sprintf("  URL: localhost:%d", server.GetPort()))
+	assert.Error(t, StopMockEndpoint(step))
+	assert.False(t, step.HasSucceeded())
+}
+
+func TestGetMockRoutes(t *testing.T) {
+	tables := []struct {
+		variables string
+		routes    []mock.Route
+		err       string
+	}{
+		{"  PORT: 0", []mock.Route{}, ""},
+		{"  ROUTES:\n    - path: /v1/weather", []mock.Route{{Path: "/v1/weather"}}, ""},
+		{"  ROUTES:\n    - path: /v1/weather\n      method: POST\n      status: 201\n      body: created\n      delay: 10ms",
+			[]mock.Route{{Path: "/v1/weather", Method: "POST", Status: 201, Body: "created", Delay: 10 * time.Millisecond}}, ""},
+		{"  ROUTES:\n    - path: /v1/weather\n      delay: 1.5 seconds", []mock.Route{{Path: "/v1/weather", Delay: 1500 * time.Millisecond}}, ""},
+		{"  ROUTES:\n    - path: /v1/weather\n      body: {city: Toronto}",
+			[]mock.Route{{Path: "/v1/weather", Body: `{"city":"Toronto"}`, Headers: map[string]string{"Content-Type": "application/json"}}}, ""},
+		{"  ROUTES:\n    - path: /v1/weather\n      body: [1, 2]\n      headers: {Content-Type: text/plain}",
+			[]mock.Route{{Path: "/v1/weather", Body: "[1,2]", Headers: map[string]string{"Content-Type": "text/plain"}}}, ""},
+		{"  ROUTES:\n    - path: /v1/weather\n      delay: soon", nil, "Delay of route '/v1/weather' must be a duration such as '500ms'"},
+	}
+
+	for _, table := range tables {
+		routes, err := getMockRoutes(newStep(t, "Create mock API endpoint", table.variables))
+		if table.err != "" {
+			if assert.Error(t, err, table.variables) {
+				assert.Contains(t, err.Error(), table.err)
+			}
+			continue
+		}
+		assert.NoError(t, err, table.variables)
+		assert.Equal(t, table.routes, routes)
+	}
+}
+
+func TestSetMockBody(t *testing.T) {
+	tables := []struct {
+		value   interface{}
+		body    string
+		headers map[string]string
+	}{
+		{nil, "", nil},
+		{"text", "text", nil},
+		{42, "42", nil},
+		{map[interface{}]interface{}{"a": map[interface{}]interface{}{"b": 1}}, `{"a":{"b":1}}`, map[string]string{"Content-Type": "application/json"}},
+		{[]interface{}{"a", 1}, `["a",1]`, map[string]string{"Content-Type": "application/json"}},
+	}
+
+	for _, table := range tables {
+		route := mock.Route{Path: "/"}
+		assert.NoError(t, setMockBody(&route, table.value))
+		assert.Equal(t, table.body, route.Body)
+		assert.Equal(t, table.headers, route.Headers)
+	}
+}
@@ -0,0 +1,139 @@
+package operations
+
+import (
+	"fmt"
+	"io/ioutil"
+	"path/filepath"
+	"sort"
+	"strings"
+
+	model "github.com/julianGoh17/simple-e2e/framework/models"
+	"github.com/julianGoh17/simple-e2e/framework/util"
+	"gopkg.in/yaml.v2"
+)
+
+const (
+	varFlagSource   = "--var"
+	dotenvExportKey = "export "
+)
+
+// VariableOverride is the value of a variable given from outside of the test file. It takes precedence over the step variables, matrix values
+// and global variables of the test with the same name.
+type VariableOverride struct {
+	Value string
+	// Source is where the value came from, for example '--var' or '--var-file vars.yaml'
+	Source string
+}
+
+// LoadVariableOverrides reads the variable files in order and then the 'KEY=VALUE' variables, with later values taking precedence over earlier
+// ones. Variable files ending in '.yaml' or '.yml' are read as a YAML map and any other file is read as a '.env' file.
+func LoadVariableOverrides(varFiles, vars []string) (map[string]VariableOverride, error) {
+	overrides := make(map[string]VariableOverride)
+	for _, varFile := range varFiles {
+		variables, err := readVariableFile(varFile)
+		if err != nil {
+			return nil, err
+		}
+		for key, value := range variables {
+			overrides[key] = VariableOverride{Value: value, Source: fmt.Sprintf("--var-file %s", varFile)}
+		}
+	}
+	for _, variable := range vars {
+		key, value, err := splitVariable(variable)
+		if err != nil {
+			return nil, fmt.Errorf("Invalid variable '%s', expected KEY=VALUE", variable)
+		}
+		overrides[key] = VariableOverride{Value: value, Source: varFlagSource}
+	}
+	return overrides, nil
+}
+
+func readVariableFile(varFile string) (map[string]string, error) {
+	body, err := ioutil.ReadFile(varFile)
+	if err != nil {
+		return nil, fmt.Errorf("unable to read variable file: %v", err)
+	}
+
+	ext := filepath.Ext(varFile)
+	if ext == ".yaml" || ext == ".yml" {
+		variables := make(map[string]string)
+		if err := yaml.UnmarshalStrict(body, &variables); err != nil {
+			return nil, fmt.Errorf("unable to unmarshal variable file '%s': %v", varFile, err)
+		}
+		return variables, nil
+	}
+
+	variables := make(map[string]string)
+	for index, line := range strings.Split(string(body), "\n") {
+		line = strings.TrimSpace(line)
+		if line == "" || strings.HasPrefix(line, "#") {
+			continue
+		}
+		key, value, err := splitVariable(strings.TrimPrefix(line, dotenvExportKey))
+		if err != nil {
+			return nil, fmt.Errorf("Invalid line %d in variable file '%s', expected KEY=VALUE", index+1, varFile)
+		}
+		variables[key] = unquote(value)
+	}
+	return variables, nil
+}
+
+func splitVariable(variable string) (string, string, error) {
+	parts := strings.SplitN(variable, "=", 2)
+	if len(parts) != 2 || strings.TrimSpace(parts[0]) == "" {
+		return "", "", fmt.Errorf("Invalid variable '%s'", variable)
+	}
+	return strings.TrimSpace(parts[0]), strings.TrimSpace(parts[1]), nil
+}
+
+func unquote(value string) string {
+	if len(value) >= 2 && (value[0] == '"' || value[0] == '\'') && value[len(value)-1] == value[0] {
+		return value[1 : len(value)-1]
+	}
+	return value
+}
+
+// logVariableOverrides will log the masked value and source of each override and what it overrides in the procedure
+func logVariableOverrides(procedure *model.Procedure, overrides map[string]VariableOverride) {
+	keys := make([]string, 0, len(overrides))
+	for key := range overrides {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+
+	for _, key := range keys {
+		logger.Info().
+			Str("variable", key).
+			Str("value", util.MaskedValue).
+			Str("source", overrides[key].Source).
+			Strs("overrides", getOverriddenSources(procedure, key)).
+			Msg("Using variable from outside of the test file")
+	}
+}
+
+// getOverriddenSources returns the places in the procedure which set the variable and are overridden
+func getOverriddenSources(procedure *model.Procedure, key string) []string {
+	sources := []string{}
+	if _, ok := procedure.GlobalVariables[key]; ok {
+		sources = append(sources, "globalVariables")
+	}
+	if _, ok := procedure.Secrets[key]; ok {
+		sources = append(sources, "secrets")
+	}
+	if procedure.Matrix != nil {
+		for _, combination := range procedure.Matrix.Combinations() {
+			if _, ok := combination[key]; ok {
+				sources = append(sources, "matrix")
+				break
+			}
+		}
+	}
+	for _, stage := range procedure.Stages {
+		for _, step := range stage.Steps {
+			if _, ok := step.Variables[key]; ok {
+				return append(sources, "step variables")
+			}
+		}
+	}
+	return sources
+}
@@ -0,0 +1,142 @@
+package operations
+
+import (
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"testing"
+
+	model "github.com/julianGoh17/simple-e2e/framework/models"
+	"github.com/stretchr/testify/assert"
+)
+
+const yamlVariableFile = `
+HOST: staging.example.com
+PORT: 8443
+VERSION: 1.10
+DEBUG: true
+`
+
+const dotenvVariableFile = `
+# Staging environment
+HOST=staging.internal
+export TOKEN="abc=123"
+NAME='Julian'
+
+EMPTY=
+`
+
+const variableOverrides = `
+name: example-test
+description: example description
+globalVariables:
+  HOST: localhost
+  PORT: "8080"
+matrix:
+  parameters:
+    NAME: [first]
+stages:
+  - name: example-stage
+    steps:
+      - description: "record-step"
+        variables:
+          URL: "${HOST}:${PORT}"
+          NAME: "${NAME}"
+          TOKEN: "from step"
+`
+
+func writeVariableFile(t *testing.T, dir, name, body string) string {
+	path := filepath.Join(dir, name)
+	assert.NoError(t, ioutil.WriteFile(path, []byte(body), 0644))
+	return path
+}
+
+func TestLoadVariableOverrides(t *testing.T) {
+	dir, err := ioutil.TempDir("", "simple-e2e-vars")
+	assert.NoError(t, err)
+	defer os.RemoveAll(dir)
+	yamlFile := writeVariableFile(t, dir, "staging.yaml", yamlVariableFile)
+	dotenvFile := writeVariableFile(t, dir, "staging.env", dotenvVariableFile)
+
+	overrides, err := LoadVariableOverrides([]string{yamlFile, dotenvFile}, []string{"PORT=9090", "QUERY=a=b"})
+	assert.NoError(t, err)
+	assert.Equal(t, map[string]VariableOverride{
+		"HOST":    {Value: "staging.internal", Source: "--var-file " + dotenvFile},
+		"PORT":    {Value: "9090", Source: "--var"},
+		"VERSION": {Value: "1.10", Source: "--var-file " + yamlFile},
+		"DEBUG":   {Value: "true", Source: "--var-file " + yamlFile},
+		"TOKEN":   {Value: "abc=123", Source: "--var-file " + dotenvFile},
+		"NAME":    {Value: "Julian", Source: "--var-file " + dotenvFile},
+		"EMPTY":   {Value: "", Source: "--var-file " + dotenvFile},
+		"QUERY":   {Value: "a=b", Source: "--var"},
+	}, overrides)
+
+	overrides, err = LoadVariableOverrides(nil, nil)
+	assert.NoError(t, err)
+	assert.Equal(t, map[string]VariableOverride{}, overrides)
+}
+
+func TestLoadVariableOverridesFails(t *testing.T) {
+	dir, err := ioutil.TempDir("", "simple-e2e-vars")
+	assert.NoError(t, err)
+	defer os.RemoveAll(dir)
+	invalidYaml := writeVariableFile(t, dir, "invalid.yml", "HOST: [first, second]")
+	invalidDotenv := writeVariableFile(t, dir, ".env", "HOST=localhost\nPORT")
+
+	tables := []struct {
+		varFiles []string
+		vars     []string
+		err      string
+	}{
+		{[]string{}, []string{"HOST"}, "Invalid variable 'HOST', expected KEY=VALUE"},
+		{[]string{}, []string{"=localhost"}, "Invalid variable '=localhost', expected KEY=VALUE"},
+		{[]string{invalidDotenv}, []string{}, "Invalid line 2 in variable file '" + invalidDotenv + "', expected KEY=VALUE"},
+		{[]string{filepath.Join(dir, "random.env")}, []string{}, "unable to read variable file: open " + filepath.Join(dir, "random.env") + ": no such file or directory"},
+	}
+	for _, table := range tables {
+		_, err := LoadVariableOverrides(table.varFiles, table.vars)
+		if assert.Error(t, err) {
+			assert.Equal(t, table.err, err.Error())
+		}
+	}
+
+	_, err = LoadVariableOverrides([]string{invalidYaml}, []string{})
+	if assert.Error(t, err) {
+		assert.Contains(t, err.Error(), "unable to unmarshal variable file '"+invalidYaml+"'")
+	}
+}
+
+func TestRunTestWithVariableOverrides(t *testing.T) {
+	controller, err := NewController()
+	assert.NoError(t, err)
+
+	variables := []map[string]string{}
+	globals := []string{}
+	assert.NoError(t, controller.AddTestStep("record-step", func(step *model.Step) error {
+		variables = append(variables, step.Variables)
+		globals = append(globals, step.GetGlobalVariable("HOST"))
+		step.SetPassed()
+		return nil
+	}))
+
+	controller.SetVariableOverrides(map[string]VariableOverride{
+		"HOST":  {Value: "staging", Source: "--var"},
+		"NAME":  {Value: "override", Source: "--var"},
+		"TOKEN": {Value: "secret", Source: "--var-file .env"},
+	})
+	assert.NoError(t, controller.runTest([]byte(variableOverrides)))
+	assert.Equal(t, []map[string]string{{"URL": "staging:8080", "NAME": "override", "TOKEN": "secret"}}, variables)
+	assert.Equal(t, []string{"staging"}, globals)
+	assert.Equal(t, map[string]string{"NAME": "first"}, controller.GetResults()[0].Combination)
+}
+
+func TestGetOverriddenSources(t *testing.T) {
+	controller, err := NewController()
+	assert.NoError(t, err)
+	assert.NoError(t, controller.SetProcedure([]byte(variableOverrides)))
+
+	assert.Equal(t, []string{"globalVariables"}, getOverriddenSources(controller.procedure, "HOST"))
+	assert.Equal(t, []string{"matrix", "step variables"}, getOverriddenSources(controller.procedure, "NAME"))
+	assert.Equal(t, []string{"step variables"}, getOverriddenSources(controller.procedure, "TOKEN"))
+	assert.Equal(t, []string{}, getOverriddenSources(controller.procedure, "RANDOM"))
+}
@@ -0,0 +1,219 @@
+package operations
+
+import (
+	"fmt"
+	"io/ioutil"
+	"reflect"
+	"runtime"
+	"strings"
+
+	"github.com/julianGoh17/simple-e2e/framework/mock"
+	model "github.com/julianGoh17/simple-e2e/framework/models"
+	"github.com/julianGoh17/simple-e2e/framework/util"
+)
+
+// NewDryRunController is a constructor function which returns a Controller without a Docker handler. It can only be used to plan tests.
+func NewDryRunController() *Controller {
+	return &Controller{
+		stepManager: NewStepManager(),
+		mocks:       mock.NewManager(),
+	}
+}
+
+// PlanTest will read the test and return what each run of it will do, without running any steps or using Docker. If any stages are passed in
+// then only those stages are planned to run.
+func (controller *Controller) PlanTest(testPath string, stages ...string) ([]*model.TestPlan, error) {
+	logger.Info().
+		Str("testPath", testPath).
+		Str("stages", strings.Join(stages, ",")).
+		Msg("Planning test")
+
+	body, err := ioutil.ReadFile(testPath)
+	if err != nil {
+		return nil, fmt.Errorf("unable to read file: %v", err)
+	}
+
+	controller.testPath = testPath
+	return controller.planTest(body, stages...)
+}
+
+func (controller *Controller) planTest(test []byte, stages ...string) ([]*model.TestPlan, error) {
+	if err := controller.SetProcedure(test); err != nil {
+		return nil, err
+	}
+	logVariableOverrides(controller.procedure, controller.overrides)
+
+	set := make(map[string]bool)
+	for _, value := range stages {
+		set[value] = true
+	}
+
+	combinations := []map[string]string{{}}
+	if controller.procedure.Matrix != nil {
+		combinations = controller.procedure.Matrix.Combinations()
+		if len(combinations) == 0 {
+			return nil, fmt.Errorf("Matrix of test '%s' does not have any combinations", controller.procedure.Name)
+		}
+	}
+
+	if err := controller.checkFromStage(); err != nil {
+		return nil, err
+	}
+	secrets, err := planSecrets(controller.procedure, controller.overrides)
+	if err != nil {
+		return nil, err
+	}
+	plans := []*model.TestPlan{}
+	for _, combination := range combinations {
+		run := newTestRun(controller.procedure, secrets, combination, controller.overrides)
+		if controller.state != nil {
+			// the plan must not add the test to the state of the run being resumed, so it is only read
+			if run.state = controller.state.findTest(controller.testPath, combination); run.state != nil {
+				run.restore(run.state)
+			}
+		}
+		plan := &model.TestPlan{
+			Name:        controller.procedure.Name,
+			Combination: combination,
+			Stages:      []*model.StagePlan{},
+		}
+		for index := range controller.procedure.Stages {
+			stagePlan, err := controller.planStage(run, index, set)
+			if err != nil {
+				return nil, err
+			}
+			plan.Stages = append(plan.Stages, stagePlan)
+		}
+		plans = append(plans, plan)
+	}
+	return plans, nil
+}
+
+// planStage returns what the stage at the index will do. Stages are selected and skipped as completed the same way as when the test is run.
+func (controller *Controller) planStage(run *testRun, index int, set map[string]bool) (*model.StagePlan, error) {
+	stage := &controller.procedure.Stages[index]
+	plan := &model.StagePlan{
+		Name:  stage.Name,
+		Tags:  getStageTags(controller.procedure, stage),
+		If:    stage.If,
+		Runs:  model.NotSelected,
+		Steps: []*model.StepPlan{},
+	}
+	if controller.isStageSelected(stage, set) && controller.isStageCompleted(run, index) {
+		plan.Runs = model.Completed
+	} else if controller.isStageSelected(stage, set) {
+		runs, err := planRun(stage.If, stage.AlwaysRuns, run.lookup)
+		if err != nil {
+			return nil, err
+		}
+		plan.Runs = runs
+	}
+
+	for _, templateStep := range stage.Steps {
+		stepPlan, err := controller.planStep(run, templateStep)
+		if err != nil {
+			return nil, err
+		}
+		plan.Steps = append(plan.Steps, stepPlan)
+	}
+	return plan, nil
+}
+
+func (controller *Controller) planStep(run *testRun, templateStep model.Step) (*model.StepPlan, error) {
+	step := run.prepareStep(templateStep, nil)
+	runs, err := planRun(templateStep.If, templateStep.AlwaysRuns, run.lookup)
+	if err != nil {
+		return nil, err
+	}
+	plan := &model.StepPlan{
+		Description:     step.Description,
+		ID:              step.ID,
+		If:              templateStep.If,
+		Runs:            runs,
+		ContinueOnError: step.ContinueOnError,
+		Loop:            describeLoop(&step),
+		Variables:       step.Variables,
+	}
+	if function, err := controller.stepManager.GetTestMethod(step.Description); err != nil {
+		plan.Error = err.Error()
+	} else {
+		plan.Function = getFunctionName(function)
+	}
+	return plan, nil
+}
+
+// planRun works out when a stage or step will be run by evaluating its 'if' expression both when nothing before it has failed and when
+// something has
+func planRun(expression string, alwaysRuns bool, lookup func(name string) (interface{}, bool)) (model.PlannedRun, error) {
+	dependsOnSteps := false
+	planLookup := func(name string) (interface{}, bool) {
+		if strings.HasPrefix(name, "steps.") {
+			dependsOnSteps = true
+		}
+		return lookup(name)
+	}
+	onSuccess, err := evaluateCondition(expression, false, alwaysRuns, planLookup)
+	if err != nil {
+		return model.RunsNever, err
+	}
+	onFailure, err := evaluateCondition(expression, true, alwaysRuns, planLookup)
+	if err != nil {
+		return model.RunsNever, err
+	}
+
+	switch {
+	case dependsOnSteps:
+		return model.RunsDependingOnSteps, nil
+	case onSuccess && onFailure:
+		return model.RunsAlways, nil
+	case onSuccess:
+		return model.RunsOnSuccess, nil
+	case onFailure:
+		return model.RunsOnFailure, nil
+	default:
+		return model.RunsNever, nil
+	}
+}
+
+// planSecrets returns a masked value for every secret of the procedure, as secrets are not read in a dry run. Variables given from outside of
+// the test file which override a secret are still masked.
+func planSecrets(procedure *model.Procedure, overrides map[string]VariableOverride) (map[string]string, error) {
+	values := make(map[string]string, len(procedure.Secrets))
+	for name := range procedure.Secrets {
+		values[name] = util.MaskedValue
+		if override, ok := overrides[name]; ok {
+			if err := registerSecret(name, override.Value); err != nil {
+				return nil, err
+			}
+		}
+	}
+	return values, nil
+}
+
+// describeLoop returns how the step uses 'forEach', 'repeat' and 'until', or an empty string if it is only run once
+func describeLoop(step *model.Step) string {
+	parts := []string{}
+	if step.HasForEach() {
+		parts = append(parts, fmt.Sprintf("for each %s in [%s]", getItemVariable(step), strings.Join(step.GetForEachItems(), ", ")))
+	}
+	if step.Until != "" {
+		_, interval, _ := getPollDurations(step)
+		part := fmt.Sprintf("until '%s' every %s", step.Until, interval)
+		if step.Within != "" {
+			part += fmt.Sprintf(" within %s", step.Within)
+		}
+		if step.Repeat > 0 {
+			part += fmt.Sprintf(" for at most %d attempts", step.Repeat)
+		}
+		parts = append(parts, part)
+	} else if step.Repeat > 0 {
+		parts = append(parts, fmt.Sprintf("repeat %d times", step.Repeat))
+	}
+	return strings.Join(parts, ", ")
+}
+
+// getFunctionName returns the name of the function with its package, for example 'operations.SayHelloTo'
+func getFunctionName(function func(*model.Step) error) string {
+	name := runtime.FuncForPC(reflect.ValueOf(function).Pointer()).Name()
+	return name[strings.LastIndex(name, "/")+1:]
+}
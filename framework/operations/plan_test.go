@@ -0,0 +1,221 @@
+package operations
+
+import (
+	"fmt"
+	"os"
+	"testing"
+
+	"github.com/julianGoh17/simple-e2e/framework/internal"
+	model "github.com/julianGoh17/simple-e2e/framework/models"
+	"github.com/julianGoh17/simple-e2e/framework/util"
+	"github.com/stretchr/testify/assert"
+)
+
+const planTest = `
+name: plan-test
+description: example description
+tags: [example]
+globalVariables:
+  HOST: localhost
+secrets:
+  TOKEN:
+    env: SIMPLE_E2E_PLAN_TOKEN
+matrix:
+  parameters:
+    ENV: [staging, production]
+stages:
+  - name: deploy
+    tags: [smoke]
+    steps:
+      - description: "Say hello to"
+        id: greet
+        variables:
+          NAME: "${HOST}-${ENV}"
+          AUTH: "${TOKEN}"
+      - description: "random-step"
+        if: steps.greet.status == 'Passed'
+        continueOnError: true
+        forEach: [first, second]
+        as: NAME
+  - name: production-only
+    if: ENV == 'production'
+    steps:
+      - description: "Say hello to"
+        repeat: 2
+        variables:
+          NAME: "${ITERATION}"
+  - name: teardown
+    tags: [slow]
+    alwaysRuns: true
+    steps:
+      - description: "Say hello to"
+        until: success()
+        within: 10s
+`
+
+func TestPlanRun(t *testing.T) {
+	variables := map[string]string{"ENV": "staging"}
+	lookup := func(name string) (interface{}, bool) {
+		value, ok := variables[name]
+		return value, ok
+	}
+
+	tables := []struct {
+		expression string
+		alwaysRuns bool
+		expected   model.PlannedRun
+	}{
+		{"", false, model.RunsOnSuccess},
+		{"", true, model.RunsAlways},
+		{"ENV == 'staging'", false, model.RunsOnSuccess},
+		{"ENV == 'production'", true, model.RunsNever},
+		{"failure()", false, model.RunsOnFailure},
+		{"always()", false, model.RunsAlways},
+		{"always() && ENV == 'production'", false, model.RunsNever},
+		{"steps.build.status == 'Passed'", false, model.RunsDependingOnSteps},
+		{"false && steps.build.status == 'Passed'", false, model.RunsNever},
+	}
+	for _, table := range tables {
+		runs, err := planRun(table.expression, table.alwaysRuns, lookup)
+		assert.NoError(t, err)
+		assert.Equal(t, table.expected, runs, table.expression)
+	}
+
+	_, err := planRun("ENV ==", false, lookup)
+	assert.Error(t, err)
+}
+
+func TestPlanTest(t *testing.T) {
+	controller := NewDryRunController()
+	assert.NoError(t, controller.SetTagFilter("", "slow"))
+	controller.SetVariableOverrides(map[string]VariableOverride{
+		"TOKEN": {Value: "plan-override-token", Source: "--var"},
+	})
+
+	plans, err := controller.planTest([]byte(planTest))
+	assert.NoError(t, err)
+	assert.Equal(t, 2, len(plans))
+	assert.Equal(t, "plan-test", plans[0].Name)
+	assert.Equal(t, map[string]string{"ENV": "staging"}, plans[0].Combination)
+	assert.Equal(t, "****", util.MaskSecrets("plan-override-token"))
+
+	stages := plans[0].Stages
+	assert.Equal(t, 3, len(stages))
+	assert.Equal(t, []string{"example", "smoke"}, stages[0].Tags)
+	assert.Equal(t, model.RunsOnSuccess, stages[0].Runs)
+	assert.Equal(t, model.RunsNever, stages[1].Runs)
+	assert.Equal(t, model.RunsOnSuccess, plans[1].Stages[1].Runs)
+	assert.Equal(t, model.NotSelected, stages[2].Runs)
+
+	assert.Equal(t, &model.StepPlan{
+		Description: "Say hello to",
+		ID:          "greet",
+		Function:    "operations.SayHelloTo",
+		Runs:        model.RunsOnSuccess,
+		Variables:   map[string]string{"NAME": "localhost-staging", "AUTH": "plan-override-token"},
+	}, stages[0].Steps[0])
+	assert.Equal(t, &model.StepPlan{
+		Description:     "random-step",
+		Error:           "Step 'random-step' is not registered in step list",
+		If:              "steps.greet.status == 'Passed'",
+		Runs:            model.RunsDependingOnSteps,
+		ContinueOnError: true,
+		Loop:            "for each NAME in [first, second]",
+	}, stages[0].Steps[1])
+	assert.Equal(t, "repeat 2 times", stages[1].Steps[0].Loop)
+	assert.Equal(t, map[string]string{"NAME": "${ITERATION}"}, stages[1].Steps[0].Variables)
+	assert.Equal(t, "until 'success()' every 1s within 10s", stages[2].Steps[0].Loop)
+	assert.Equal(t, model.RunsOnSuccess, stages[2].Steps[0].Runs)
+}
+
+func TestPlanTestMasksSecrets(t *testing.T) {
+	controller := NewDryRunController()
+
+	plans, err := controller.planTest([]byte(planTest), "deploy")
+	assert.NoError(t, err)
+	assert.Equal(t, "****", plans[0].Stages[0].Steps[0].Variables["AUTH"])
+	assert.Equal(t, model.NotSelected, plans[0].Stages[1].Runs)
+}
+
+func TestPlanTestSkipsCompletedStages(t *testing.T) {
+	controller := NewDryRunController()
+	controller.SetFromStage("production-only")
+	plans, err := controller.planTest([]byte(planTest))
+	assert.NoError(t, err)
+	assert.Equal(t, model.Completed, plans[0].Stages[0].Runs)
+	assert.Equal(t, model.RunsNever, plans[0].Stages[1].Runs)
+	assert.Equal(t, model.RunsAlways, plans[0].Stages[2].Runs)
+
+	state := NewRunState()
+	state.getTest("", map[string]string{"ENV": "staging"}).CompletedStages = []string{"deploy"}
+	controller = NewDryRunController()
+	controller.SetRunState(state)
+	plans, err = controller.planTest([]byte(planTest))
+	assert.NoError(t, err)
+	assert.Equal(t, model.Completed, plans[0].Stages[0].Runs)
+	assert.Equal(t, model.RunsOnSuccess, plans[1].Stages[0].Runs)
+	assert.Equal(t, 1, len(state.Tests))
+
+	controller = NewDryRunController()
+	controller.SetFromStage("random")
+	_, err = controller.planTest([]byte(planTest))
+	if assert.Error(t, err) {
+		assert.Equal(t, "Could not find stage 'random' to run from in test 'plan-test'", err.Error())
+	}
+}
+
+func TestPlanTestFails(t *testing.T) {
+	controller := NewDryRunController()
+
+	_, err := controller.PlanTest("/non-existent-test.yaml")
+	if assert.Error(t, err) {
+		assert.Equal(t, "unable to read file: open /non-existent-test.yaml: no such file or directory", err.Error())
+	}
+
+	_, err = controller.planTest([]byte(`
+name: empty-matrix-test
+matrix:
+  parameters:
+    ENV: []
+stages:
+  - name: stage
+    steps:
+      - description: "Say hello to"
+`))
+	if assert.Error(t, err) {
+		assert.Equal(t, "Matrix of test 'empty-matrix-test' does not have any combinations", err.Error())
+	}
+
+	controller.SetVariableOverrides(map[string]VariableOverride{"TOKEN": {Value: "abc", Source: "--var"}})
+	_, err = controller.planTest([]byte(planTest))
+	if assert.Error(t, err) {
+		assert.Equal(t, "Secret 'TOKEN' is too short to keep out of the logs: Could not mask a value shorter than 4 characters", err.Error())
+	}
+}
+
+func TestRunnerPlansTests(t *testing.T) {
+	internal.SetTestFilesRoot()
+	testDir := os.Getenv(util.TestDirEnv)
+	paths, err := FindTestFiles(testDir, []string{"examples/matrix-test", "examples/simple-test"}, false)
+	assert.NoError(t, err)
+
+	configured := 0
+	plans, err := NewRunner(2, func(controller *Controller) error {
+		configured++
+		return nil
+	}).PlanTests(paths)
+	assert.NoError(t, err)
+	assert.Equal(t, 2, configured)
+	// The matrix example has 4 combinations
+	assert.Equal(t, 5, len(plans))
+	assert.Equal(t, "Simple Test", plans[4].Name)
+
+	_, err = NewRunner(1, nil).PlanTests(append(paths, fmt.Sprintf("%s/non-existent-test.yaml", testDir)))
+	if assert.Error(t, err) {
+		assert.Contains(t, err.Error(), fmt.Sprintf("Could not plan test '%s/non-existent-test.yaml': unable to read file", testDir))
+	}
+}
+
+func TestGetFunctionName(t *testing.T) {
+	assert.Equal(t, "operations.SayHelloTo", getFunctionName(SayHelloTo))
+}
@@ -0,0 +1,162 @@
+package operations
+
+import (
+	"context"
+	"fmt"
+	"net"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/julianGoh17/simple-e2e/framework/models"
+)
+
+const (
+	defaultPortTimeout  = time.Minute
+	defaultPortInterval = time.Second
+)
+
+// portCheck is a port which the port steps connect to and what they send to and expect from it when it uses UDP
+type portCheck struct {
+	protocol string
+	address  string
+	send     string
+	expect   string
+	interval time.Duration
+}
+
+// WaitForPort will try to connect to a port every interval until it succeeds or the timeout passes. A UDP port is only open once it responds
+// to what is sent to it. The address which was connected to is saved as the output 'address' and the number of attempts as 'attempts'.
+// Environmental Variables:
+//   - HOST: The host to connect to (default localhost)
+//   - CONTAINER: The name of a container created by the test, whose IP address is used instead of HOST so ports which are not published can be reached
+//   - PORT: The port to connect to
+//   - PROTOCOL: Either 'tcp' or 'udp' (default tcp)
+//   - SEND: The text sent to a UDP port
+//   - EXPECT: Text which the response of a UDP port must contain
+//   - TIMEOUT: How long to wait for the port (default 1m)
+//   - INTERVAL: How long to wait between attempts, which is also how long each attempt waits for a response (default 1s)
+func WaitForPort(step *models.Step) error {
+	traceStepEntrance(step)
+	check, timeout, err := getPortCheck(step)
+	if err != nil {
+		return traceStepExit(step, err)
+	}
+	attempts, err := pollPort(step, check, timeout, true)
+	step.SetOutput("attempts", strconv.Itoa(attempts))
+	return traceStepExit(step, err)
+}
+
+// AssertPortClosed will try to connect to a port every interval until it fails or the timeout passes, so a test can check a service has stopped
+// listening. A UDP port is closed when it does not respond to what is sent to it. The step has the same variables as 'Wait for port', where
+// TIMEOUT is 0 by default so the port is only checked once.
+func AssertPortClosed(step *models.Step) error {
+	traceStepEntrance(step)
+	check, timeout, err := getPortCheck(step)
+	if err != nil {
+		return traceStepExit(step, err)
+	}
+	if _, ok := step.Variables["TIMEOUT"]; !ok {
+		timeout = 0
+	}
+	attempts, err := pollPort(step, check, timeout, false)
+	step.SetOutput("attempts", strconv.Itoa(attempts))
+	return traceStepExit(step, err)
+}
+
+// getPortCheck returns the port described by the step variables and how long to wait for it, resolving the container to its IP address
+func getPortCheck(step *models.Step) (*portCheck, time.Duration, error) {
+	port, err := step.GetValueFromVariablesAsInteger("PORT")
+	if err != nil {
+		return nil, 0, err
+	}
+	protocol := strings.ToLower(step.GetValueFromVariablesAsStringOrDefault("PROTOCOL", "tcp"))
+	if protocol != "tcp" && protocol != "udp" {
+		return nil, 0, fmt.Errorf("Protocol '%s' of step '%s' must be 'tcp' or 'udp'", protocol, step.Description)
+	}
+	timeout, err := step.GetValueFromVariablesAsDurationOrDefault("TIMEOUT", defaultPortTimeout)
+	if err != nil {
+		return nil, 0, err
+	}
+	interval, err := step.GetValueFromVariablesAsDurationOrDefault("INTERVAL", defaultPortInterval)
+	if err != nil {
+		return nil, 0, err
+	}
+
+	host := step.GetValueFromVariablesAsStringOrDefault("HOST", "localhost")
+	if container, err := step.GetValueFromVariablesAsString("CONTAINER"); err == nil {
+		if step.Docker == nil {
+			return nil, 0, fmt.Errorf("Could not find IP address of container '%s' as Docker is not available", container)
+		}
+		if host, err = step.Docker.GetContainerIP(container); err != nil {
+			return nil, 0, err
+		}
+	}
+	address := net.JoinHostPort(host, strconv.Itoa(port))
+	step.SetOutput("address", address)
+	return &portCheck{
+		protocol: protocol,
+		address:  address,
+		send:     step.GetValueFromVariablesAsStringOrDefault("SEND", ""),
+		expect:   step.GetValueFromVariablesAsStringOrDefault("EXPECT", ""),
+		interval: interval,
+	}, timeout, nil
+}
+
+// pollPort will check the port every interval until it is open or closed, as wanted, or the timeout passes, returning the number of attempts
+func pollPort(step *models.Step, check *portCheck, timeout time.Duration, wantOpen bool) (int, error) {
+	ctx := step.GetContext()
+	deadline := time.Now().Add(timeout)
+	for attempt := 1; ; attempt++ {
+		err := check.connect(ctx)
+		logger.Debug().
+			Str("address", check.address).
+			Str("protocol", check.protocol).
+			Int("attempt", attempt).
+			Bool("open", err == nil).
+			Msg("Checked port")
+		if (err == nil) == wantOpen {
+			return attempt, nil
+		}
+
+		if time.Now().Add(check.interval).After(deadline) {
+			if wantOpen {
+				return attempt, fmt.Errorf("Port %s/%s did not open within %s after %d attempts: %v", check.address, check.protocol, timeout, attempt, err)
+			}
+			return attempt, fmt.Errorf("Port %s/%s was still open after %s and %d attempts", check.address, check.protocol, timeout, attempt)
+		}
+		select {
+		case <-time.After(check.interval):
+		case <-ctx.Done():
+			return attempt, fmt.Errorf("Step '%s' was interrupted while checking port %s/%s after %d attempts", step.Description, check.address,
+				check.protocol, attempt)
+		}
+	}
+}
+
+// connect returns nil if the port accepts a TCP connection, or responds over UDP with what is expected, within the interval
+func (check *portCheck) connect(ctx context.Context) error {
+	dialer := net.Dialer{Timeout: check.interval}
+	conn, err := dialer.DialContext(ctx, check.protocol, check.address)
+	if err != nil {
+		return err
+	}
+	defer conn.Close()
+	if check.protocol == "tcp" {
+		return nil
+	}
+
+	conn.SetDeadline(time.Now().Add(check.interval))
+	if _, err := conn.Write([]byte(check.send)); err != nil {
+		return err
+	}
+	response := make([]byte, 65535)
+	length, err := conn.Read(response)
+	if err != nil {
+		return fmt.Errorf("no response: %v", err)
+	}
+	if !strings.Contains(string(response[:length]), check.expect) {
+		return fmt.Errorf("response '%s' does not contain '%s'", response[:length], check.expect)
+	}
+	return nil
+}
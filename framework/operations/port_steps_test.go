@@ -0,0 +1,146 @@
+package operations
+
+import (
+	"context"
+	"fmt"
+	"net"
+	"strconv"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+// getFreePort returns a port which nothing is listening on
+func getFreePort(t *testing.T) int {
+	listener, err := net.Listen("tcp", "127.0.0.1:0")
+	assert.NoError(t, err)
+	defer listener.Close()
+	return listener.Addr().(*net.TCPAddr).Port
+}
+
+// newUDPEchoServer returns a UDP server which responds with 'echo: ' and what was sent to it
+func newUDPEchoServer(t *testing.T) net.PacketConn {
+	conn, err := net.ListenPacket("udp", "127.0.0.1:0")
+	assert.NoError(t, err)
+	go func() {
+		buffer := make([]byte, 1024)
+		for {
+			length, address, err := conn.ReadFrom(buffer)
+			if err != nil {
+				return
+			}
+			conn.WriteTo(append([]byte("echo: "), buffer[:length]...), address)
+		}
+	}()
+	return conn
+}
+
+func TestWaitForPort(t *testing.T) {
+	listener, err := net.Listen("tcp", "127.0.0.1:0")
+	assert.NoError(t, err)
+	defer listener.Close()
+	tcpPort := listener.Addr().(*net.TCPAddr).Port
+	udp := newUDPEchoServer(t)
+	defer udp.Close()
+	udpPort := udp.LocalAddr().(*net.UDPAddr).Port
+
+	tables := []struct {
+		variables string
+		address   string
+	}{
+		{fmt.Sprintf("  HOST: 127.0.0.1\n  PORT: %d", tcpPort), fmt.Sprintf("127.0.0.1:%d", tcpPort)},
+		{fmt.Sprintf("  PORT: %d", tcpPort), fmt.Sprintf("localhost:%d", tcpPort)},
+		{fmt.Sprintf("  HOST: 127.0.0.1\n  PORT: %d\n  PROTOCOL: UDP\n  SEND: ping\n  EXPECT: 'echo: ping'", udpPort), fmt.Sprintf("127.0.0.1:%d", udpPort)},
+	}
+
+	for _, table := range tables {
+		step := newStep(t, "Wait for port", table.variables)
+		assert.NoError(t, WaitForPort(step), table.variables)
+		assert.True(t, step.HasSucceeded())
+		assert.Equal(t, table.address, step.GetOutputs()["address"])
+		assert.Equal(t, "1", step.GetOutputs()["attempts"])
+	}
+}
+
+func TestWaitForPortWaitsUntilPortOpens(t *testing.T) {
+	port := getFreePort(t)
+	opened := make(chan net.Listener)
+	go func() {
+		time.Sleep(100 * time.Millisecond)
+		listener, err := net.Listen("tcp", fmt.Sprintf("127.0.0.1:%d", port))
+		assert.NoError(t, err)
+		opened <- listener
+	}()
+
+	step := newStep(t, "Wait for port", fmt.Sprintf("  HOST: 127.0.0.1\n  PORT: %d\n  INTERVAL: 20ms\n  TIMEOUT: 5s", port))
+	assert.NoError(t, WaitForPort(step))
+	(<-opened).Close()
+	attempts, err := strconv.Atoi(step.GetOutputs()["attempts"])
+	assert.NoError(t, err)
+	assert.True(t, attempts > 1)
+}
+
+func TestWaitForPortFails(t *testing.T) {
+	port := getFreePort(t)
+	udp := newUDPEchoServer(t)
+	defer udp.Close()
+	udpPort := udp.LocalAddr().(*net.UDPAddr).Port
+
+	tables := []struct {
+		variables string
+		err       string
+	}{
+		{fmt.Sprintf("  HOST: 127.0.0.1\n  PORT: %d\n  INTERVAL: 20ms\n  TIMEOUT: 100ms", port), fmt.Sprintf("Port 127.0.0.1:%d/tcp did not open within 100ms after", port)},
+		{fmt.Sprintf("  HOST: 127.0.0.1\n  PORT: %d\n  PROTOCOL: udp\n  INTERVAL: 20ms\n  TIMEOUT: 50ms", port), "no response"},
+		{fmt.Sprintf("  HOST: 127.0.0.1\n  PORT: %d\n  PROTOCOL: udp\n  SEND: ping\n  EXPECT: pong\n  INTERVAL: 20ms\n  TIMEOUT: 50ms", udpPort), "response 'echo: ping' does not contain 'pong'"},
+		{"  HOST: 127.0.0.1", "Could not find variable 'PORT' in step.variables"},
+		{"  PORT: 80\n  PROTOCOL: sctp", "Protocol 'sctp' of step 'Wait for port' must be 'tcp' or 'udp'"},
+		{"  PORT: 80\n  TIMEOUT: soon", "soon"},
+		{"  PORT: 80\n  INTERVAL: often", "often"},
+		{"  PORT: 80\n  CONTAINER: database", "Could not find IP address of container 'database' as Docker is not available"},
+	}
+
+	for _, table := range tables {
+		step := newStep(t, "Wait for port", table.variables)
+		err := WaitForPort(step)
+		if assert.Error(t, err, table.variables) {
+			assert.Contains(t, err.Error(), table.err)
+		}
+		assert.False(t, step.HasSucceeded())
+	}
+}
+
+func TestWaitForPortIsInterrupted(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	step := newStep(t, "Wait for port", fmt.Sprintf("  HOST: 127.0.0.1\n  PORT: %d\n  INTERVAL: 10s", getFreePort(t)))
+	step.SetContext(ctx)
+	err := WaitForPort(step)
+	if assert.Error(t, err) {
+		assert.Contains(t, err.Error(), "Step 'Wait for port' was interrupted while checking port")
+	}
+}
+
+func TestAssertPortClosed(t *testing.T) {
+	listener, err := net.Listen("tcp", "127.0.0.1:0")
+	assert.NoError(t, err)
+	port := listener.Addr().(*net.TCPAddr).Port
+
+	step := newStep(t, "Assert port closed", fmt.Sprintf("  HOST: 127.0.0.1\n  PORT: %d", port))
+	err = AssertPortClosed(step)
+	if assert.Error(t, err) {
+		assert.Equal(t, fmt.Sprintf("Port 127.0.0.1:%d/tcp was still open after 0s and 1 attempts", port), err.Error())
+	}
+
+	go func() {
+		time.Sleep(100 * time.Millisecond)
+		listener.Close()
+	}()
+	step = newStep(t, "Assert port closed", fmt.Sprintf("  HOST: 127.0.0.1\n  PORT: %d\n  INTERVAL: 20ms\n  TIMEOUT: 5s", port))
+	assert.NoError(t, AssertPortClosed(step))
+	assert.True(t, step.HasSucceeded())
+
+	step = newStep(t, "Assert port closed", fmt.Sprintf("  HOST: 127.0.0.1\n  PORT: %d\n  PROTOCOL: udp\n  INTERVAL: 20ms", getFreePort(t)))
+	assert.NoError(t, AssertPortClosed(step))
+}
@@ -0,0 +1,125 @@
+package operations
+
+import (
+	"fmt"
+	"path/filepath"
+	"strings"
+	"sync"
+
+	model "github.com/julianGoh17/simple-e2e/framework/models"
+)
+
+// Runner runs many test files, each with its own Controller so that tests which run at the same time do not share any state
+type Runner struct {
+	parallel  int
+	configure func(*Controller) error
+}
+
+// NewRunner is a constructor function which returns a Runner that runs at most 'parallel' tests at the same time. 'configure' is called with the
+// Controller of every test before it is run and can be nil.
+func NewRunner(parallel int, configure func(*Controller) error) *Runner {
+	if parallel < 1 {
+		parallel = 1
+	}
+	return &Runner{
+		parallel:  parallel,
+		configure: configure,
+	}
+}
+
+// RunTests will run each test file and return the results of all of them. If any stages are passed in then only those stages are run. When a
+// single test is run its error is returned as is, otherwise the error says how many of the tests failed.
+func (runner *Runner) RunTests(testPaths []string, stages ...string) ([]*model.TestResult, error) {
+	logger.Info().
+		Strs("tests", testPaths).
+		Int("parallel", runner.parallel).
+		Msg("Running tests")
+	controllers := make([]*Controller, len(testPaths))
+	for index, testPath := range testPaths {
+		controller, err := NewController()
+		if err != nil {
+			return nil, err
+		}
+		if runner.configure != nil {
+			if err := runner.configure(controller); err != nil {
+				return nil, err
+			}
+		}
+		if runner.parallel > 1 {
+			controller.SetDockerNamespace(getDockerNamespace(index, testPath))
+		}
+		controllers[index] = controller
+	}
+
+	errs := make([]error, len(testPaths))
+	semaphore := make(chan struct{}, runner.parallel)
+	var wg sync.WaitGroup
+	for index, testPath := range testPaths {
+		wg.Add(1)
+		semaphore <- struct{}{}
+		go func(index int, testPath string) {
+			defer wg.Done()
+			errs[index] = controllers[index].RunTest(testPath, stages...)
+			<-semaphore
+		}(index, testPath)
+	}
+	wg.Wait()
+
+	results := []*model.TestResult{}
+	failed := 0
+	for index, controller := range controllers {
+		testResults := controller.GetResults()
+		if len(testResults) == 0 && errs[index] != nil {
+			testResults = []*model.TestResult{{
+				Name:        testPaths[index],
+				Combination: map[string]string{},
+				Stages:      []*model.StageResult{},
+			}}
+			testResults[0].SetErrored(errs[index])
+		}
+		results = append(results, testResults...)
+		if errs[index] != nil {
+			failed++
+		}
+	}
+
+	if len(testPaths) == 1 {
+		return results, errs[0]
+	}
+	if failed > 0 {
+		return results, fmt.Errorf("%d of %d tests failed", failed, len(testPaths))
+	}
+	return results, nil
+}
+
+// PlanTests will plan each test file without running any steps or using Docker and return the plans of all of them. If any stages are passed
+// in then only those stages are planned to run.
+func (runner *Runner) PlanTests(testPaths []string, stages ...string) ([]*model.TestPlan, error) {
+	logger.Info().
+		Strs("tests", testPaths).
+		Msg("Planning tests")
+	plans := []*model.TestPlan{}
+	for _, testPath := range testPaths {
+		controller := NewDryRunController()
+		if runner.configure != nil {
+			if err := runner.configure(controller); err != nil {
+				return nil, err
+			}
+		}
+		testPlans, err := controller.PlanTest(testPath, stages...)
+		if err != nil {
+			if len(testPaths) == 1 {
+				return nil, err
+			}
+			return nil, fmt.Errorf("Could not plan test '%s': %v", testPath, err)
+		}
+		plans = append(plans, testPlans...)
+	}
+	return plans, nil
+}
+
+// getDockerNamespace returns a namespace for the containers of a test which is unique for each test in the run and is a valid container name
+func getDockerNamespace(index int, testPath string) string {
+	name := strings.TrimSuffix(filepath.Base(testPath), filepath.Ext(testPath))
+	return fmt.Sprintf("simple-e2e-%d-%s", index+1, cleanName(name))
+}
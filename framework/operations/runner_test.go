@@ -0,0 +1,76 @@
+package operations
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/julianGoh17/simple-e2e/framework/internal"
+	model "github.com/julianGoh17/simple-e2e/framework/models"
+	"github.com/julianGoh17/simple-e2e/framework/util"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestRunnerRunsAllTests(t *testing.T) {
+	internal.SetTestFilesRoot()
+	testDir := os.Getenv(util.TestDirEnv)
+	paths, err := FindTestFiles(testDir, []string{"examples/conditions-test", "examples/loops-test", "examples/matrix-test", "test"}, false)
+	assert.NoError(t, err)
+
+	controllers := []*Controller{}
+	runner := NewRunner(4, func(controller *Controller) error {
+		controllers = append(controllers, controller)
+		return nil
+	})
+	results, err := runner.RunTests(paths)
+	assert.NoError(t, err)
+	assert.Equal(t, len(paths), len(controllers))
+	// The matrix example has 4 combinations
+	assert.Equal(t, 7, len(results))
+	for _, result := range results {
+		assert.Equal(t, model.Passed, result.Status, result.Name)
+	}
+	assert.Equal(t, fmt.Sprintf("simple-e2e-1-%s", "conditions-test"), getDockerNamespace(0, paths[0]))
+}
+
+func TestRunnerReportsFailedTests(t *testing.T) {
+	internal.SetTestFilesRoot()
+	testDir := os.Getenv(util.TestDirEnv)
+	paths := []string{
+		fmt.Sprintf("%s/test.yaml", testDir),
+		fmt.Sprintf("%s/non-existent-test.yaml", testDir),
+	}
+
+	results, err := NewRunner(0, nil).RunTests(paths, "stage1")
+	assert.Error(t, err)
+	assert.Equal(t, "1 of 2 tests failed", err.Error())
+	assert.Equal(t, 2, len(results))
+	assert.Equal(t, model.Passed, results[0].Status)
+	assert.Equal(t, model.Skipped, results[0].Stages[1].Status)
+	assert.Equal(t, paths[1], results[1].Name)
+	assert.Equal(t, model.Failed, results[1].Status)
+	assert.Contains(t, results[1].Error, "unable to read file")
+
+	results, err = NewRunner(1, nil).RunTests(paths[1:])
+	assert.Error(t, err)
+	assert.Contains(t, err.Error(), "unable to read file")
+	assert.Equal(t, 1, len(results))
+}
+
+func TestRunnerFailsToConfigureController(t *testing.T) {
+	runner := NewRunner(1, func(controller *Controller) error {
+		return errors.New("Random Error")
+	})
+	results, err := runner.RunTests([]string{"test.yaml"})
+	assert.Error(t, err)
+	assert.Equal(t, "Random Error", err.Error())
+	assert.Nil(t, results)
+}
+
+func TestGetDockerNamespace(t *testing.T) {
+	assert.Equal(t, "simple-e2e-1-test", getDockerNamespace(0, filepath.Join("tests", "test.yaml")))
+	assert.Equal(t, "simple-e2e-3-my-test_1.0", getDockerNamespace(2, "tests/my test_1.0.yml"))
+	assert.Equal(t, "simple-e2e-2-", getDockerNamespace(1, "tests/@@.yaml"))
+}
@@ -0,0 +1,72 @@
+package operations
+
+import (
+	"fmt"
+	"io/ioutil"
+	"os"
+	"strings"
+
+	model "github.com/julianGoh17/simple-e2e/framework/models"
+	"github.com/julianGoh17/simple-e2e/framework/util"
+)
+
+// resolveSecrets returns the value of every secret of the procedure and registers them so they are masked in everything logged or shown to
+// the user. Variables given from outside of the test file which override a secret are also masked.
+func resolveSecrets(procedure *model.Procedure, overrides map[string]VariableOverride) (map[string]string, error) {
+	values := make(map[string]string, len(procedure.Secrets))
+	for name, secret := range procedure.Secrets {
+		value, err := resolveSecret(name, secret)
+		if err == nil {
+			err = registerSecret(name, value)
+		}
+		if override, ok := overrides[name]; ok && err == nil {
+			err = registerSecret(name, override.Value)
+		}
+		if err != nil {
+			logger.Error().
+				Err(err).
+				Str("secret", name).
+				Msg("Failed to resolve secret")
+			return nil, err
+		}
+		values[name] = value
+	}
+	return values, nil
+}
+
+// registerSecret will mask the value of the secret, failing if it is too short to be masked
+func registerSecret(name, value string) error {
+	if err := util.RegisterSecret(value); err != nil {
+		return fmt.Errorf("Secret '%s' is too short to keep out of the logs: %v", name, err)
+	}
+	return nil
+}
+
+func resolveSecret(name string, secret model.Secret) (string, error) {
+	sources := 0
+	for _, source := range []string{secret.Value, secret.Env, secret.File} {
+		if source != "" {
+			sources++
+		}
+	}
+	if sources != 1 {
+		return "", fmt.Errorf("Secret '%s' must have exactly one of 'value', 'env' or 'file'", name)
+	}
+
+	switch {
+	case secret.Env != "":
+		value, ok := os.LookupEnv(secret.Env)
+		if !ok {
+			return "", fmt.Errorf("Secret '%s' can not be read as environmental variable '%s' is not set", name, secret.Env)
+		}
+		return value, nil
+	case secret.File != "":
+		body, err := ioutil.ReadFile(getTestDirPath(secret.File))
+		if err != nil {
+			return "", fmt.Errorf("Secret '%s' can not be read from file: %v", name, err)
+		}
+		return strings.TrimRight(string(body), "\r\n"), nil
+	default:
+		return secret.Value, nil
+	}
+}
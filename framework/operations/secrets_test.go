@@ -0,0 +1,131 @@
+package operations
+
+import (
+	"errors"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"testing"
+
+	model "github.com/julianGoh17/simple-e2e/framework/models"
+	"github.com/julianGoh17/simple-e2e/framework/util"
+	"github.com/stretchr/testify/assert"
+)
+
+const secretsTest = `
+name: secrets-test
+description: example description
+globalVariables:
+  USER: admin
+  ADMIN_PASSWORD:
+    value: controller-admin-password
+    secret: true
+secrets:
+  API_TOKEN:
+    env: SIMPLE_E2E_TEST_API_TOKEN
+stages:
+  - name: example-stage
+    steps:
+      - description: "record-step"
+        variables:
+          LOGIN: "${USER}:${ADMIN_PASSWORD}"
+          TOKEN: "${API_TOKEN}"
+`
+
+func TestResolveSecret(t *testing.T) {
+	dir, err := ioutil.TempDir("", "simple-e2e-secrets")
+	assert.NoError(t, err)
+	defer os.RemoveAll(dir)
+	secretFile := writeVariableFile(t, dir, "token.txt", "resolve-file-token\n")
+	os.Setenv("SIMPLE_E2E_RESOLVE_SECRET", "resolve-env-token")
+	defer os.Unsetenv("SIMPLE_E2E_RESOLVE_SECRET")
+
+	tables := []struct {
+		secret   model.Secret
+		expected string
+		err      string
+	}{
+		{model.Secret{Value: "resolve-value-token"}, "resolve-value-token", ""},
+		{model.Secret{Env: "SIMPLE_E2E_RESOLVE_SECRET"}, "resolve-env-token", ""},
+		{model.Secret{File: secretFile}, "resolve-file-token", ""},
+		{model.Secret{}, "", "Secret 'TOKEN' must have exactly one of 'value', 'env' or 'file'"},
+		{model.Secret{Value: "value", Env: "SIMPLE_E2E_RESOLVE_SECRET"}, "", "Secret 'TOKEN' must have exactly one of 'value', 'env' or 'file'"},
+		{model.Secret{Env: "SIMPLE_E2E_RANDOM_SECRET"}, "", "Secret 'TOKEN' can not be read as environmental variable 'SIMPLE_E2E_RANDOM_SECRET' is not set"},
+		{model.Secret{File: filepath.Join(dir, "random.txt")}, "", "Secret 'TOKEN' can not be read from file: open " + filepath.Join(dir, "random.txt") + ": no such file or directory"},
+	}
+	for _, table := range tables {
+		value, err := resolveSecret("TOKEN", table.secret)
+		if table.err != "" {
+			if assert.Error(t, err) {
+				assert.Equal(t, table.err, err.Error())
+			}
+			continue
+		}
+		assert.NoError(t, err)
+		assert.Equal(t, table.expected, value)
+	}
+}
+
+func TestResolveSecretsRegistersValues(t *testing.T) {
+	procedure := &model.Procedure{Secrets: map[string]model.Secret{
+		"PASSWORD": {Value: "registered-password"},
+	}}
+	overrides := map[string]VariableOverride{
+		"PASSWORD": {Value: "registered-override-password", Source: "--var"},
+		"HOST":     {Value: "registered-host", Source: "--var"},
+	}
+
+	secrets, err := resolveSecrets(procedure, overrides)
+	assert.NoError(t, err)
+	assert.Equal(t, map[string]string{"PASSWORD": "registered-password"}, secrets)
+	assert.Equal(t, "****, **** and registered-host", util.MaskSecrets("registered-password, registered-override-password and registered-host"))
+}
+
+func TestResolveSecretsFailsWithShortValues(t *testing.T) {
+	tables := []struct {
+		secret   model.Secret
+		override string
+	}{
+		{model.Secret{Value: "abc"}, ""},
+		{model.Secret{Value: "short-secret-password"}, "abc"},
+	}
+
+	for _, table := range tables {
+		procedure := &model.Procedure{Secrets: map[string]model.Secret{"PIN": table.secret}}
+		overrides := map[string]VariableOverride{}
+		if table.override != "" {
+			overrides["PIN"] = VariableOverride{Value: table.override, Source: "--var"}
+		}
+		_, err := resolveSecrets(procedure, overrides)
+		assert.EqualError(t, err, "Secret 'PIN' is too short to keep out of the logs: Could not mask a value shorter than 4 characters")
+	}
+}
+
+func TestRunTestWithSecrets(t *testing.T) {
+	os.Setenv("SIMPLE_E2E_TEST_API_TOKEN", "controller-api-token")
+	defer os.Unsetenv("SIMPLE_E2E_TEST_API_TOKEN")
+	controller, err := NewController()
+	assert.NoError(t, err)
+
+	variables := []map[string]string{}
+	assert.NoError(t, controller.AddTestStep("record-step", func(step *model.Step) error {
+		variables = append(variables, step.Variables)
+		step.SetPassed()
+		return errors.New("could not log in with " + step.Variables["LOGIN"])
+	}))
+
+	assert.Error(t, controller.runTest([]byte(secretsTest)))
+	assert.Equal(t, []map[string]string{{"LOGIN": "admin:controller-admin-password", "TOKEN": "controller-api-token"}}, variables)
+	assert.Equal(t, "could not log in with admin:****", util.MaskSecrets(controller.GetResults()[0].Stages[0].Steps[0].Error))
+	assert.Equal(t, []string{"secrets"}, getOverriddenSources(controller.procedure, "ADMIN_PASSWORD"))
+}
+
+func TestRunTestFailsWithMissingSecret(t *testing.T) {
+	controller, err := NewController()
+	assert.NoError(t, err)
+
+	err = controller.runTest([]byte(secretsTest))
+	if assert.Error(t, err) {
+		assert.Equal(t, "Secret 'API_TOKEN' can not be read as environmental variable 'SIMPLE_E2E_TEST_API_TOKEN' is not set", err.Error())
+	}
+}
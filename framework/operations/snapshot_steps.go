@@ -0,0 +1,185 @@
+package operations
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"regexp"
+	"sort"
+	"strings"
+
+	"github.com/julianGoh17/simple-e2e/framework/models"
+	"github.com/julianGoh17/simple-e2e/framework/util"
+)
+
+const (
+	snapshotsDir       = "__snapshots__"
+	snapshotExtension  = ".snap"
+	ignoredReplacement = "<ignored>"
+)
+
+// MatchSnapshot will compare a value with the snapshot saved under '<TEST_DIR>/__snapshots__', failing with a unified diff of the snapshot and
+// the value when they are different. The snapshot is created or rewritten with the value when the run has '--update-snapshots', and the step
+// fails when the snapshot does not exist otherwise. The path of the snapshot is saved as the output 'snapshot'.
+// Environmental Variables:
+//   - VALUE: The value to compare, such as '${steps.api.outputs.body}'. JSON, and YAML maps and lists, are saved as indented JSON with sorted keys
+//   - NAME: The name of the snapshot, which can contain '/' to put it in a directory (default the id of the step, followed by the matrix
+//     combination of the run such as 'weather.CITY-Toronto')
+//   - IGNORE: A regular expression, or a list of them, whose matches are replaced with '<ignored>' before comparing, such as timestamps and IDs
+//   - REPLACE: A map of regular expressions to the text which their matches are replaced with before comparing. As a map has no order, the
+//     expressions are applied in alphabetical order.
+func MatchSnapshot(step *models.Step) error {
+	traceStepEntrance(step)
+	name := step.GetValueFromVariablesAsStringOrDefault("NAME", getDefaultSnapshotName(step))
+	if name == "" {
+		return traceStepExit(step, fmt.Errorf("Step '%s' must have 'NAME' or an id to name its snapshot", step.Description))
+	}
+	path, err := getSnapshotPath(name)
+	if err != nil {
+		return traceStepExit(step, err)
+	}
+	value, err := step.GetValueFromVariables("VALUE")
+	if err != nil {
+		return traceStepExit(step, err)
+	}
+	actual, err := formatSnapshot(value)
+	if err != nil {
+		return traceStepExit(step, err)
+	}
+	if actual, err = normalizeSnapshot(step, actual); err != nil {
+		return traceStepExit(step, err)
+	}
+	step.SetOutput("snapshot", path)
+
+	expected, err := ioutil.ReadFile(path)
+	if err != nil && !os.IsNotExist(err) {
+		return traceStepExit(step, fmt.Errorf("Could not read snapshot '%s': %v", name, err))
+	}
+	if step.UpdateSnapshots {
+		return traceStepExit(step, writeSnapshot(name, path, actual, err == nil))
+	}
+	if os.IsNotExist(err) {
+		return traceStepExit(step, fmt.Errorf("Snapshot '%s' does not exist, run with '--update-snapshots' to create it", name))
+	}
+	if diff := util.UnifiedDiff(name+snapshotExtension, "value", string(expected), actual); diff != "" {
+		return traceStepExit(step, fmt.Errorf("Value does not match snapshot '%s', run with '--update-snapshots' to update it:\n%s", name, diff))
+	}
+	return traceStepExit(step, nil)
+}
+
+// getDefaultSnapshotName returns the id of the step, followed by the matrix combination of its run so that each combination has its own snapshot
+func getDefaultSnapshotName(step *models.Step) string {
+	if step.ID == "" || len(step.GetCombination()) == 0 {
+		return step.ID
+	}
+	return fmt.Sprintf("%s.%s", step.ID, getCombinationNamespace(step.GetCombination()))
+}
+
+// getSnapshotPath returns the path of the snapshot file with the name, which must be inside the snapshots directory
+func getSnapshotPath(name string) (string, error) {
+	cleaned := filepath.Clean(name)
+	if filepath.IsAbs(cleaned) || cleaned == "." || cleaned == ".." || strings.HasPrefix(cleaned, ".."+string(filepath.Separator)) {
+		return "", fmt.Errorf("Snapshot name '%s' must be a relative path inside '%s'", name, snapshotsDir)
+	}
+	return filepath.Join(config.GetOrDefault(util.TestDirEnv), snapshotsDir, cleaned+snapshotExtension), nil
+}
+
+// formatSnapshot returns the value as the text which is saved in the snapshot. JSON text and YAML maps and lists are indented with sorted keys
+// and text always ends with a new line.
+func formatSnapshot(value interface{}) (string, error) {
+	var document interface{}
+	if text, isString := value.(string); isString {
+		decoder := json.NewDecoder(strings.NewReader(text))
+		// Numbers are kept as they were written so large numbers do not lose precision
+		decoder.UseNumber()
+		if decoder.Decode(&document) != nil || decoder.More() || !isJSONContainer(document) {
+			return ensureTrailingNewline(text), nil
+		}
+	} else {
+		document = value
+		if !isJSONContainer(document) {
+			return ensureTrailingNewline(fmt.Sprint(value)), nil
+		}
+	}
+
+	var formatted bytes.Buffer
+	encoder := json.NewEncoder(&formatted)
+	encoder.SetEscapeHTML(false)
+	encoder.SetIndent("", "  ")
+	if err := encoder.Encode(document); err != nil {
+		return "", fmt.Errorf("Could not convert variable 'VALUE' to JSON: %v", err)
+	}
+	return formatted.String(), nil
+}
+
+// normalizeSnapshot returns the text with the matches of the 'IGNORE' expressions replaced with '<ignored>' and then the matches of the
+// 'REPLACE' expressions, in alphabetical order, replaced with their text
+func normalizeSnapshot(step *models.Step, text string) (string, error) {
+	ignore, err := getTextList(step, "IGNORE")
+	if err != nil {
+		return "", err
+	}
+	replace, err := step.GetValueFromVariablesAsMapOrDefault("REPLACE", map[string]string{})
+	if err != nil {
+		return "", err
+	}
+	patterns := make([]string, 0, len(replace))
+	for pattern := range replace {
+		patterns = append(patterns, pattern)
+	}
+	sort.Strings(patterns)
+
+	replacements := make([][2]string, 0, len(ignore)+len(patterns))
+	for _, pattern := range ignore {
+		replacements = append(replacements, [2]string{pattern, ignoredReplacement})
+	}
+	for _, pattern := range patterns {
+		replacements = append(replacements, [2]string{pattern, replace[pattern]})
+	}
+	for _, replacement := range replacements {
+		expression, err := regexp.Compile(replacement[0])
+		if err != nil {
+			return "", fmt.Errorf("Could not compile regular expression '%s' of step '%s': %v", replacement[0], step.Description, err)
+		}
+		text = expression.ReplaceAllString(text, replacement[1])
+	}
+	return text, nil
+}
+
+// writeSnapshot will create or overwrite the snapshot file
+func writeSnapshot(name, path, text string, exists bool) error {
+	if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+		return fmt.Errorf("Could not create directory of snapshot '%s': %v", name, err)
+	}
+	if err := ioutil.WriteFile(path, []byte(text), defaultFileMode); err != nil {
+		return fmt.Errorf("Could not write snapshot '%s': %v", name, err)
+	}
+	message := "Created snapshot"
+	if exists {
+		message = "Updated snapshot"
+	}
+	logger.Info().
+		Str("snapshot", name).
+		Str("path", path).
+		Msg(message)
+	return nil
+}
+
+func isJSONContainer(document interface{}) bool {
+	switch document.(type) {
+	case map[string]interface{}, []interface{}:
+		return true
+	default:
+		return false
+	}
+}
+
+func ensureTrailingNewline(text string) string {
+	if strings.HasSuffix(text, "\n") {
+		return text
+	}
+	return text + "\n"
+}
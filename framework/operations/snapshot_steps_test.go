@@ -0,0 +1,144 @@
+package operations
+
+import (
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/julianGoh17/simple-e2e/framework/models"
+	"github.com/julianGoh17/simple-e2e/framework/util"
+	"github.com/stretchr/testify/assert"
+)
+
+// setSnapshotTestDir will set 'TEST_DIR' to a new directory and return it with a function which deletes it and restores 'TEST_DIR'
+func setSnapshotTestDir(t *testing.T) (string, func()) {
+	dir, err := ioutil.TempDir("", "simple-e2e-snapshots")
+	assert.NoError(t, err)
+	previous := os.Getenv(util.TestDirEnv)
+	os.Setenv(util.TestDirEnv, dir)
+	return dir, func() {
+		os.Setenv(util.TestDirEnv, previous)
+		os.RemoveAll(dir)
+	}
+}
+
+func TestMatchSnapshot(t *testing.T) {
+	dir, restore := setSnapshotTestDir(t)
+	defer restore()
+
+	tables := []struct {
+		variables string
+		snapshot  string
+	}{
+		{"  NAME: text\n  VALUE: hello", "hello\n"},
+		{"  NAME: api/weather\n  VALUE: '{\"temps\": [21, 19], \"city\": \"Toronto\", \"id\": 12345678901234567890}'", "{\n  \"city\": \"Toronto\",\n  \"id\": 12345678901234567890,\n  \"temps\": [\n    21,\n    19\n  ]\n}\n"},
+		{"  NAME: yaml\n  VALUE: {city: Toronto, url: 'http://a?b=1&c=2'}", "{\n  \"city\": \"Toronto\",\n  \"url\": \"http://a?b=1&c=2\"\n}\n"},
+		{"  NAME: number\n  VALUE: 42", "42\n"},
+		{"  NAME: not-json\n  VALUE: '{\"a\": 1} {\"b\": 2}'", "{\"a\": 1} {\"b\": 2}\n"},
+		{"  NAME: ignored\n  VALUE: 'created at 2020-10-19T05:50:23Z with id 8f3a'\n  IGNORE: '\\d{4}-\\d{2}-\\d{2}T[0-9:]+Z'\n  REPLACE: {'id [0-9a-f]+': 'id <id>'}", "created at <ignored> with id <id>\n"},
+	}
+
+	for _, table := range tables {
+		for _, run := range []string{"create", "match"} {
+			step := newStep(t, "Match snapshot", table.variables)
+			step.UpdateSnapshots = run == "create"
+			assert.NoError(t, MatchSnapshot(step), "%s %s", run, table.variables)
+			assert.True(t, step.HasSucceeded())
+			snapshot, err := ioutil.ReadFile(step.GetOutputs()["snapshot"])
+			assert.NoError(t, err)
+			assert.Equal(t, table.snapshot, string(snapshot), table.variables)
+		}
+	}
+	assert.FileExists(t, filepath.Join(dir, "__snapshots__", "api", "weather.snap"))
+
+	step := newStep(t, "Match snapshot", "  VALUE: hello")
+	step.ID = "greeting"
+	step.UpdateSnapshots = true
+	assert.NoError(t, MatchSnapshot(step))
+	assert.Equal(t, filepath.Join(dir, "__snapshots__", "greeting.snap"), step.GetOutputs()["snapshot"])
+
+	step.SetCombination(map[string]string{"CITY": "Toronto", "UNITS": "metric"})
+	assert.NoError(t, MatchSnapshot(step))
+	assert.Equal(t, filepath.Join(dir, "__snapshots__", "greeting.CITY-Toronto-UNITS-metric.snap"), step.GetOutputs()["snapshot"])
+}
+
+func TestMatchSnapshotFailsWhenSnapshotDoesNotExist(t *testing.T) {
+	dir, restore := setSnapshotTestDir(t)
+	defer restore()
+
+	step := newStep(t, "Match snapshot", "  NAME: weather\n  VALUE: sunny")
+	err := MatchSnapshot(step)
+	if assert.Error(t, err) {
+		assert.Equal(t, "Snapshot 'weather' does not exist, run with '--update-snapshots' to create it", err.Error())
+	}
+	assert.False(t, step.HasSucceeded())
+	assert.NoFileExists(t, filepath.Join(dir, "__snapshots__", "weather.snap"))
+}
+
+func TestMatchSnapshotFailsAndUpdates(t *testing.T) {
+	dir, restore := setSnapshotTestDir(t)
+	defer restore()
+	path := filepath.Join(dir, "__snapshots__", "weather.snap")
+	assert.NoError(t, os.MkdirAll(filepath.Dir(path), 0755))
+	assert.NoError(t, ioutil.WriteFile(path, []byte("{\n  \"city\": \"Toronto\",\n  \"temp\": 21\n}\n"), 0644))
+
+	step := newStep(t, "Match snapshot", "  NAME: weather\n  VALUE: '{\"city\": \"Toronto\", \"temp\": 25}'")
+	err := MatchSnapshot(step)
+	if assert.Error(t, err) {
+		assert.Equal(t, "Value does not match snapshot 'weather', run with '--update-snapshots' to update it:\n"+
+			"--- weather.snap\n+++ value\n@@ -1,4 +1,4 @@\n {\n   \"city\": \"Toronto\",\n-  \"temp\": 21\n+  \"temp\": 25\n }\n", err.Error())
+	}
+	assert.False(t, step.HasSucceeded())
+
+	step = newStep(t, "Match snapshot", "  NAME: weather\n  VALUE: '{\"city\": \"Toronto\", \"temp\": 25}'")
+	step.UpdateSnapshots = true
+	assert.NoError(t, MatchSnapshot(step))
+	snapshot, err := ioutil.ReadFile(path)
+	assert.NoError(t, err)
+	assert.Equal(t, "{\n  \"city\": \"Toronto\",\n  \"temp\": 25\n}\n", string(snapshot))
+}
+
+func TestMatchSnapshotFails(t *testing.T) {
+	dir, restore := setSnapshotTestDir(t)
+	defer restore()
+	assert.NoError(t, ioutil.WriteFile(filepath.Join(dir, "__snapshots__"), []byte{}, 0644))
+
+	tables := []struct {
+		variables string
+		err       string
+	}{
+		{"  VALUE: hello", "Step 'Match snapshot' must have 'NAME' or an id to name its snapshot"},
+		{"  NAME: ../secrets\n  VALUE: hello", "Snapshot name '../secrets' must be a relative path inside '__snapshots__'"},
+		{"  NAME: /etc/passwd\n  VALUE: hello", "Snapshot name '/etc/passwd' must be a relative path inside '__snapshots__'"},
+		{"  NAME: greeting", "Could not find variable 'VALUE' in step.variables"},
+		{"  NAME: greeting\n  VALUE: hello\n  IGNORE: '('", "Could not compile regular expression '(' of step 'Match snapshot'"},
+		{"  NAME: greeting\n  VALUE: hello\n  REPLACE: [a]", "Could not convert 'a' to type 'map[string]string'"},
+		{"  NAME: greeting\n  VALUE: hello", "Could not read snapshot 'greeting'"},
+	}
+
+	for _, table := range tables {
+		step := newStep(t, "Match snapshot", table.variables)
+		err := MatchSnapshot(step)
+		if assert.Error(t, err, table.variables) {
+			assert.Contains(t, err.Error(), table.err)
+		}
+		assert.False(t, step.HasSucceeded())
+	}
+}
+
+func TestRunTestPassesUpdateSnapshotsToSteps(t *testing.T) {
+	controller, err := NewController()
+	assert.NoError(t, err)
+	updates := []bool{}
+	assert.NoError(t, controller.AddTestStep("example-step", func(step *models.Step) error {
+		updates = append(updates, step.UpdateSnapshots)
+		step.SetPassed()
+		return nil
+	}))
+
+	assert.NoError(t, controller.runTest([]byte(correctlyFormated)))
+	controller.SetUpdateSnapshots(true)
+	assert.NoError(t, controller.runTest([]byte(correctlyFormated)))
+	assert.Equal(t, []bool{false, true}, updates)
+}
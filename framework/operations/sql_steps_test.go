@@ -0,0 +1,202 @@
+package operations
+
+import (
+	"database/sql"
+	"fmt"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"reflect"
+	"testing"
+
+	"github.com/julianGoh17/simple-e2e/framework/models"
+	"github.com/julianGoh17/simple-e2e/framework/util"
+	"github.com/stretchr/testify/assert"
+)
+
+func newSQLStep(t *testing.T, dsn, description, variables string) *models.Step {
+	return newStep(t, description, fmt.Sprintf("  DRIVER: sqlite\n  DSN: %s\n%s", dsn, variables))
+}
+
+// newSQLiteDatabase returns the path of a SQLite database with a 'cities' table, and the directory which it is in
+func newSQLiteDatabase(t *testing.T) (string, string) {
+	dir, err := ioutil.TempDir("", "simple-e2e-sql")
+	assert.NoError(t, err)
+	dsn := filepath.Join(dir, "test.db")
+	step := newSQLStep(t, dsn, "Execute SQL", `  QUERY: |
+    CREATE TABLE cities (id INTEGER PRIMARY KEY, name TEXT NOT NULL, temperature REAL, tags TEXT, photo BLOB);
+    INSERT INTO cities (name, temperature) VALUES ('Toronto', 21.5), ('Vancouver', 18);`)
+	assert.NoError(t, ExecuteSQL(step))
+	return dsn, dir
+}
+
+func TestExecuteSQL(t *testing.T) {
+	dsn, dir := newSQLiteDatabase(t)
+	defer os.RemoveAll(dir)
+
+	tables := []struct {
+		variables    string
+		rowsAffected string
+		err          string
+	}{
+		{"  QUERY: INSERT INTO cities (name, tags) VALUES (?, ?)\n  ARGS: [Montreal, [snow, bagels]]", "1", ""},
+		{"  QUERY: UPDATE cities SET temperature = temperature + ?\n  ARGS: [1]", "3", ""},
+		{"  QUERY: DELETE FROM cities WHERE name = 'Nowhere'", "0", ""},
+		{"  QUERY: INSERT INTO towns (name) VALUES ('Guelph')", "", "Could not execute SQL of step 'Execute SQL': "},
+		{"  QUERY: SELECT 1\n  ARGS: one", "", "Variable 'ARGS' of step 'Execute SQL' must be a list"},
+		{"  QUERY: SELECT 1\n  TIMEOUT: soon", "", "Could not convert 'soon' to type 'time.Duration'"},
+		{"  ARGS: []", "", "Could not find variable 'QUERY' in step.variables"},
+	}
+
+	for _, table := range tables {
+		step := newSQLStep(t, dsn, "Execute SQL", table.variables)
+		err := ExecuteSQL(step)
+		if table.err != "" {
+			if assert.Error(t, err, table.variables) {
+				assert.Contains(t, err.Error(), table.err)
+			}
+			assert.False(t, step.HasSucceeded())
+			continue
+		}
+		assert.NoError(t, err, table.variables)
+		assert.True(t, step.HasSucceeded())
+		assert.Equal(t, table.rowsAffected, step.GetOutputs()["rowsAffected"], table.variables)
+	}
+
+	step := newSQLStep(t, dsn, "Assert query returns", "  QUERY: SELECT tags FROM cities WHERE name = 'Montreal'\n  ROWS: [{tags: '[\"snow\",\"bagels\"]'}]")
+	assert.NoError(t, AssertQueryReturns(step))
+}
+
+func TestExecuteSQLFile(t *testing.T) {
+	dsn, dir := newSQLiteDatabase(t)
+	defer os.RemoveAll(dir)
+	previous := os.Getenv(util.TestDirEnv)
+	os.Setenv(util.TestDirEnv, dir)
+	defer os.Setenv(util.TestDirEnv, previous)
+	assert.NoError(t, os.MkdirAll(filepath.Join(dir, "sql"), 0755))
+	assert.NoError(t, ioutil.WriteFile(filepath.Join(dir, "sql", "seed.sql"), []byte(`
+-- Cities which are added by the seed file
+INSERT INTO cities (name, temperature) VALUES ('Calgary', 12);
+INSERT INTO cities (name, temperature) VALUES ('Halifax', 15), ('Ottawa', ?);
+`), 0644))
+
+	step := newSQLStep(t, dsn, "Execute SQL file", "  FILE: sql/seed.sql\n  ARGS: [20]")
+	assert.NoError(t, ExecuteSQLFile(step))
+	assert.True(t, step.HasSucceeded())
+	step = newSQLStep(t, dsn, "Assert query returns", "  QUERY: SELECT name, temperature FROM cities WHERE id > 2 ORDER BY id\n"+
+		"  ROWS: [{name: Calgary, temperature: 12}, {name: Halifax, temperature: 15}, {name: Ottawa, temperature: 20}]")
+	assert.NoError(t, AssertQueryReturns(step))
+
+	step = newSQLStep(t, dsn, "Execute SQL file", "  FILE: sql/missing.sql")
+	err := ExecuteSQLFile(step)
+	if assert.Error(t, err) {
+		assert.Contains(t, err.Error(), "Could not read SQL file 'sql/missing.sql'")
+	}
+	assert.EqualError(t, ExecuteSQLFile(newSQLStep(t, dsn, "Execute SQL file", "  ARGS: []")), "Could not find variable 'FILE' in step.variables")
+}
+
+func TestAssertQueryReturns(t *testing.T) {
+	dsn, dir := newSQLiteDatabase(t)
+	defer os.RemoveAll(dir)
+
+	tables := []struct {
+		variables string
+		rows      string
+		count     string
+		err       string
+	}{
+		{
+			"  QUERY: SELECT id, name, temperature FROM cities ORDER BY id\n  ROWS: [{id: 1, name: Toronto, temperature: 21.5}, {id: 2, name: Vancouver, temperature: 18}]",
+			`[{"id":1,"name":"Toronto","temperature":21.5},{"id":2,"name":"Vancouver","temperature":18}]`, "2", "",
+		},
+		{
+			"  QUERY: SELECT * FROM cities WHERE name = ?\n  ARGS: [Vancouver]\n  ROWS: '[{\"name\": \"Vancouver\"}]'\n  COUNT: 1",
+			`[{"id":2,"name":"Vancouver","photo":null,"tags":null,"temperature":18}]`, "1", "",
+		},
+		{"  QUERY: SELECT name FROM cities WHERE temperature > 30\n  COUNT: 0", `[]`, "0", ""},
+		{"  QUERY: SELECT count(*) AS total FROM cities\n  ROWS: [{total: 2}]", `[{"total":2}]`, "1", ""},
+		{"  QUERY: SELECT CAST('toronto.png' AS BLOB) AS photo\n  ROWS: [{photo: toronto.png}]", `[{"photo":"toronto.png"}]`, "1", ""},
+		{
+			"  QUERY: SELECT name FROM cities ORDER BY id\n  ROWS: [{name: Vancouver}, {name: Toronto}]", "", "2",
+			"Query 'SELECT name FROM cities ORDER BY id' did not return the expected rows:\n" +
+				"  $[0].name: expected \"Vancouver\" but was \"Toronto\"\n  $[1].name: expected \"Toronto\" but was \"Vancouver\"",
+		},
+		{
+			"  QUERY: SELECT name FROM cities\n  ROWS: [{name: Toronto}]", "", "2",
+			"Query 'SELECT name FROM cities' did not return the expected rows:\n  $: expected 1 items but had 2",
+		},
+		{
+			"  QUERY: SELECT name FROM cities ORDER BY id\n  COUNT: 1", "", "2",
+			"Query 'SELECT name FROM cities ORDER BY id' returned 2 rows but expected 1. It returned: [{\"name\":\"Toronto\"},{\"name\":\"Vancouver\"}]",
+		},
+		{"  QUERY: SELECT name FROM cities", "", "", "Step 'Assert query returns' must have 'ROWS', 'ROWS_FILE' or 'COUNT'"},
+		{"  QUERY: SELECT name FROM cities\n  COUNT: two", "", "", "Could not convert 'two' to type 'int'"},
+		{"  QUERY: SELECT name FROM cities\n  ROWS_FILE: missing.yaml", "", "", "Could not read 'missing.yaml'"},
+		{"  QUERY: SELECT name FROM towns\n  COUNT: 0", "", "", "Could not run query 'SELECT name FROM towns': "},
+		{"  COUNT: 0", "", "", "Could not find variable 'QUERY' in step.variables"},
+	}
+
+	for _, table := range tables {
+		step := newSQLStep(t, dsn, "Assert query returns", table.variables)
+		err := AssertQueryReturns(step)
+		if table.err != "" {
+			if assert.Error(t, err, table.variables) {
+				assert.Contains(t, err.Error(), table.err)
+			}
+			assert.False(t, step.HasSucceeded())
+		} else {
+			assert.NoError(t, err, table.variables)
+			assert.True(t, step.HasSucceeded())
+		}
+		if table.rows != "" {
+			assert.Equal(t, table.rows, step.GetOutputs()["rows"], table.variables)
+		}
+		assert.Equal(t, table.count, step.GetOutputs()["count"], table.variables)
+	}
+}
+
+func TestOpenDatabaseFails(t *testing.T) {
+	tables := []struct {
+		variables string
+		err       string
+	}{
+		{"  DRIVER: oracle\n  DSN: db", "Driver 'oracle' of step 'Execute SQL' must be 'postgres', 'mysql' or 'sqlite'"},
+		{"  DRIVER: postgres", "Could not find variable 'DSN' in step.variables"},
+		{"  DSN: db", "Could not find variable 'DRIVER' in step.variables"},
+		{"  DRIVER: PostgreSQL\n  DSN: postgres://user@127.0.0.1:1/db?sslmode=disable", "Could not connect to postgres database of step 'Execute SQL': "},
+		{"  DRIVER: mariadb\n  DSN: 'user@tcp(127.0.0.1:1)/db'", "Could not connect to mysql database of step 'Execute SQL': "},
+		{"  DRIVER: sqlite3\n  DSN: /simple-e2e/missing/dir/test.db", "Could not connect to sqlite database of step 'Execute SQL': "},
+	}
+
+	for _, table := range tables {
+		step := newStep(t, "Execute SQL", "  QUERY: SELECT 1\n"+table.variables)
+		err := ExecuteSQL(step)
+		if assert.Error(t, err, table.variables) {
+			assert.Contains(t, err.Error(), table.err)
+		}
+		assert.False(t, step.HasSucceeded())
+	}
+}
+
+func TestGetSQLValue(t *testing.T) {
+	tables := []struct {
+		scanType reflect.Type
+		value    interface{}
+		expected interface{}
+	}{
+		{reflect.TypeOf(int64(0)), []byte("42"), int64(42)},
+		{reflect.TypeOf(sql.NullInt64{}), []byte("-7"), int64(-7)},
+		{reflect.TypeOf(uint32(0)), []byte("7"), uint64(7)},
+		{reflect.TypeOf(float64(0)), []byte("21.5"), 21.5},
+		{reflect.TypeOf(sql.NullFloat64{}), []byte("1e3"), float64(1000)},
+		{reflect.TypeOf(sql.RawBytes{}), []byte("Toronto"), "Toronto"},
+		{reflect.TypeOf(int64(0)), []byte("not a number"), "not a number"},
+		{nil, []byte("text"), "text"},
+		{reflect.TypeOf(int64(0)), int64(5), int64(5)},
+		{reflect.TypeOf(""), nil, nil},
+	}
+
+	for _, table := range tables {
+		assert.Equal(t, table.expected, getSQLValue(table.scanType, table.value), fmt.Sprint(table.value))
+	}
+}
@@ -0,0 +1,189 @@
+package operations
+
+import (
+	"crypto/rand"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"sync"
+	"time"
+
+	"github.com/julianGoh17/simple-e2e/framework/docker"
+	model "github.com/julianGoh17/simple-e2e/framework/models"
+	"github.com/julianGoh17/simple-e2e/framework/util"
+)
+
+// RunState is the state of a run of one or more tests. It is saved to '<STATE_DIR>/<ID>.json' after every stage which passes and after every
+// test so that a failed run can be resumed without running its completed stages again.
+type RunState struct {
+	ID    string       `json:"id"`
+	Tests []*TestState `json:"tests"`
+	// lock protects Tests as the tests and matrix combinations of a run can save their state at the same time
+	lock sync.Mutex
+	// saved is whether the state has been written to its file, so the run can be resumed
+	saved bool
+}
+
+// TestState is the saved state of a single run of a test. When the test has a matrix, there is one TestState for each combination.
+type TestState struct {
+	TestPath        string                       `json:"testPath"`
+	Combination     map[string]string            `json:"combination"`
+	CompletedStages []string                     `json:"completedStages"`
+	Statuses        map[string]string            `json:"statuses"`
+	Outputs         map[string]map[string]string `json:"outputs"`
+	// Containers are the containers created by the steps of the test which had not been deleted when the state was saved
+	Containers []*docker.ContainerInfo `json:"containers"`
+}
+
+// NewRunState is a constructor function which returns the empty state of a new run with a unique ID
+func NewRunState() *RunState {
+	return &RunState{
+		ID:    newRunID(),
+		Tests: []*TestState{},
+	}
+}
+
+// LoadRunState will read the saved state of an earlier run so that it can be resumed
+func LoadRunState(id string) (*RunState, error) {
+	body, err := ioutil.ReadFile(getStatePath(id))
+	if err != nil {
+		return nil, fmt.Errorf("Could not find state of run '%s': %v", id, err)
+	}
+	state := &RunState{}
+	if err := json.Unmarshal(body, state); err != nil {
+		return nil, fmt.Errorf("Could not read state of run '%s': %v", id, err)
+	}
+	logger.Info().
+		Str("runID", id).
+		Int("tests", len(state.Tests)).
+		Msg("Loaded state of run to resume")
+	return state, nil
+}
+
+// GetTestPaths returns the path of every test in the run in the order they were first run
+func (state *RunState) GetTestPaths() []string {
+	state.lock.Lock()
+	defer state.lock.Unlock()
+	seen := make(map[string]bool)
+	paths := []string{}
+	for _, test := range state.Tests {
+		if !seen[test.TestPath] {
+			seen[test.TestPath] = true
+			paths = append(paths, test.TestPath)
+		}
+	}
+	return paths
+}
+
+// getTest returns the saved state of the test run for the matrix combination, adding an empty one if the test has not been run yet
+func (state *RunState) getTest(testPath string, combination map[string]string) *TestState {
+	state.lock.Lock()
+	defer state.lock.Unlock()
+	if test := state.findTestLocked(testPath, combination); test != nil {
+		return test
+	}
+	test := &TestState{
+		TestPath:        testPath,
+		Combination:     combination,
+		CompletedStages: []string{},
+		Statuses:        make(map[string]string),
+		Outputs:         make(map[string]map[string]string),
+		Containers:      []*docker.ContainerInfo{},
+	}
+	state.Tests = append(state.Tests, test)
+	return test
+}
+
+// findTest returns the saved state of the test run for the matrix combination, or nil if the test has not been run yet
+func (state *RunState) findTest(testPath string, combination map[string]string) *TestState {
+	state.lock.Lock()
+	defer state.lock.Unlock()
+	return state.findTestLocked(testPath, combination)
+}
+
+func (state *RunState) findTestLocked(testPath string, combination map[string]string) *TestState {
+	key := model.CombinationToString(combination)
+	for _, test := range state.Tests {
+		if test.TestPath == testPath && model.CombinationToString(test.Combination) == key {
+			return test
+		}
+	}
+	return nil
+}
+
+// completeStage will record that the stage of the test run has passed along with the status and outputs of its steps and the containers which
+// exist
+func (state *RunState) completeStage(test *TestState, stage string, run *testRun, containers []*docker.ContainerInfo) {
+	state.lock.Lock()
+	defer state.lock.Unlock()
+	if !containsString(test.CompletedStages, stage) {
+		test.CompletedStages = append(test.CompletedStages, stage)
+	}
+	for id, status := range run.statuses {
+		test.Statuses[id] = model.MapResultStatusToString(status)
+	}
+	for id, outputs := range run.outputs {
+		test.Outputs[id] = outputs
+	}
+	test.Containers = containers
+}
+
+// save will write the state of the run to '<STATE_DIR>/<ID>.json'
+func (state *RunState) save() error {
+	state.lock.Lock()
+	defer state.lock.Unlock()
+	path := getStatePath(state.ID)
+	if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+		return fmt.Errorf("Could not save state of run '%s': %v", state.ID, err)
+	}
+	body, err := json.MarshalIndent(state, "", "  ")
+	if err != nil {
+		return fmt.Errorf("Could not save state of run '%s': %v", state.ID, err)
+	}
+	// the outputs of steps can have tokens and passwords in them, so only the user who ran the tests can read the state
+	if err := ioutil.WriteFile(path, body, 0600); err != nil {
+		return fmt.Errorf("Could not save state of run '%s': %v", state.ID, err)
+	}
+	state.saved = true
+	return nil
+}
+
+// IsSaved returns whether the state of the run has been saved, so that it can be resumed
+func (state *RunState) IsSaved() bool {
+	state.lock.Lock()
+	defer state.lock.Unlock()
+	return state.saved
+}
+
+// restore will set the status and outputs of the steps of the test run from its saved state
+func (run *testRun) restore(test *TestState) {
+	for id, status := range test.Statuses {
+		run.statuses[id] = model.MapStringToResultStatus(status)
+	}
+	for id, outputs := range test.Outputs {
+		run.outputs[id] = outputs
+	}
+}
+
+func getStatePath(id string) string {
+	return filepath.Join(config.GetOrDefault(util.StateDirEnv), fmt.Sprintf("%s.json", id))
+}
+
+// newRunID returns an ID which starts with the time of the run so the state files sort by when they were run
+func newRunID() string {
+	suffix := make([]byte, 3)
+	rand.Read(suffix)
+	return fmt.Sprintf("%s-%s", time.Now().Format("20060102-150405"), hex.EncodeToString(suffix))
+}
+
+func containsString(values []string, value string) bool {
+	for _, existing := range values {
+		if existing == value {
+			return true
+		}
+	}
+	return false
+}
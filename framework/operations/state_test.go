@@ -0,0 +1,265 @@
+package operations
+
+import (
+	"errors"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+	"testing"
+
+	"github.com/julianGoh17/simple-e2e/framework/docker"
+	model "github.com/julianGoh17/simple-e2e/framework/models"
+	"github.com/julianGoh17/simple-e2e/framework/util"
+	"github.com/stretchr/testify/assert"
+)
+
+const resumeTest = `
+name: resume-test
+description: example description
+stages:
+  - name: setup
+    steps:
+      - description: "setup-step"
+        id: setup
+  - name: test
+    steps:
+      - description: "test-step"
+        variables:
+          TOKEN: "${steps.setup.outputs.token}"
+  - name: teardown
+    steps:
+      - description: "teardown-step"
+`
+
+func setStateDir(t *testing.T) func() {
+	dir, err := ioutil.TempDir("", "simple-e2e-state")
+	assert.NoError(t, err)
+	previous := os.Getenv(util.StateDirEnv)
+	os.Setenv(util.StateDirEnv, dir)
+	return func() {
+		os.Setenv(util.StateDirEnv, previous)
+		os.RemoveAll(dir)
+	}
+}
+
+func TestNewRunState(t *testing.T) {
+	state := NewRunState()
+	assert.Regexp(t, `^\d{8}-\d{6}-[0-9a-f]{6}$`, state.ID)
+	assert.NotEqual(t, state.ID, NewRunState().ID)
+	assert.Equal(t, []string{}, state.GetTestPaths())
+}
+
+func TestRunStateGetTest(t *testing.T) {
+	state := NewRunState()
+	first := state.getTest("first.yaml", map[string]string{"TAG": "1.0"})
+	assert.Equal(t, first, state.getTest("first.yaml", map[string]string{"TAG": "1.0"}))
+	assert.NotEqual(t, first, state.getTest("first.yaml", map[string]string{"TAG": "2.0"}))
+	state.getTest("second.yaml", map[string]string{})
+
+	assert.Equal(t, 3, len(state.Tests))
+	assert.Equal(t, []string{"first.yaml", "second.yaml"}, state.GetTestPaths())
+}
+
+func TestRunStateSavesAndLoads(t *testing.T) {
+	defer setStateDir(t)()
+	state := NewRunState()
+	test := state.getTest("test.yaml", map[string]string{"TAG": "1.0"})
+	run := &testRun{
+		statuses: map[string]model.ResultStatus{"setup": model.Passed},
+		outputs:  map[string]map[string]string{"setup": {"token": "abc"}},
+	}
+	containers := []*docker.ContainerInfo{{Name: "database", ID: "1", Image: "postgres"}}
+
+	state.completeStage(test, "setup", run, containers)
+	state.completeStage(test, "setup", run, containers)
+	assert.False(t, state.IsSaved())
+	assert.NoError(t, state.save())
+	assert.True(t, state.IsSaved())
+
+	loaded, err := LoadRunState(state.ID)
+	assert.NoError(t, err)
+	assert.Equal(t, state.ID, loaded.ID)
+	assert.Equal(t, []*TestState{{
+		TestPath:        "test.yaml",
+		Combination:     map[string]string{"TAG": "1.0"},
+		CompletedStages: []string{"setup"},
+		Statuses:        map[string]string{"setup": "Passed"},
+		Outputs:         map[string]map[string]string{"setup": {"token": "abc"}},
+		Containers:      containers,
+	}}, loaded.Tests)
+	info, err := os.Stat(filepath.Join(os.Getenv(util.StateDirEnv), state.ID+".json"))
+	assert.NoError(t, err)
+	assert.Equal(t, os.FileMode(0600), info.Mode().Perm())
+
+	restored := newTestRun(&model.Procedure{}, nil, map[string]string{}, nil)
+	restored.restore(loaded.Tests[0])
+	assert.Equal(t, run.statuses, restored.statuses)
+	assert.Equal(t, run.outputs, restored.outputs)
+}
+
+func TestLoadRunStateFails(t *testing.T) {
+	defer setStateDir(t)()
+	_, err := LoadRunState("random")
+	if assert.Error(t, err) {
+		assert.Contains(t, err.Error(), "Could not find state of run 'random'")
+	}
+
+	path := filepath.Join(os.Getenv(util.StateDirEnv), "invalid.json")
+	assert.NoError(t, ioutil.WriteFile(path, []byte("{"), 0644))
+	_, err = LoadRunState("invalid")
+	if assert.Error(t, err) {
+		assert.Contains(t, err.Error(), "Could not read state of run 'invalid'")
+	}
+}
+
+func newResumeController(t *testing.T, calls map[string]int, tokens *[]string, testFails bool) *Controller {
+	controller, err := NewController()
+	assert.NoError(t, err)
+	assert.NoError(t, controller.AddTestStep("setup-step", func(step *model.Step) error {
+		calls["setup"]++
+		step.SetOutput("token", "abc")
+		step.SetPassed()
+		return nil
+	}))
+	assert.NoError(t, controller.AddTestStep("test-step", func(step *model.Step) error {
+		calls["test"]++
+		*tokens = append(*tokens, step.Variables["TOKEN"])
+		if testFails {
+			return errors.New("test failed")
+		}
+		step.SetPassed()
+		return nil
+	}))
+	assert.NoError(t, controller.AddTestStep("teardown-step", func(step *model.Step) error {
+		calls["teardown"]++
+		step.SetPassed()
+		return nil
+	}))
+	return controller
+}
+
+func TestRunTestResumesFromFailedStage(t *testing.T) {
+	defer setStateDir(t)()
+	calls := make(map[string]int)
+	tokens := []string{}
+
+	state := NewRunState()
+	controller := newResumeController(t, calls, &tokens, true)
+	controller.SetRunState(state)
+	assert.Error(t, controller.runTest([]byte(resumeTest)))
+	assert.Equal(t, map[string]int{"setup": 1, "test": 1}, calls)
+
+	loaded, err := LoadRunState(state.ID)
+	assert.NoError(t, err)
+	assert.Equal(t, []string{"setup"}, loaded.Tests[0].CompletedStages)
+
+	controller = newResumeController(t, calls, &tokens, false)
+	controller.SetRunState(loaded)
+	assert.NoError(t, controller.runTest([]byte(resumeTest)))
+	assert.Equal(t, map[string]int{"setup": 1, "test": 2, "teardown": 1}, calls)
+	assert.Equal(t, []string{"abc", "abc"}, tokens)
+	assert.Equal(t, model.Skipped, controller.GetResults()[0].Stages[0].Status)
+	assert.Equal(t, model.Passed, controller.GetResults()[0].Stages[1].Status)
+	assert.Equal(t, []string{"setup", "test", "teardown"}, loaded.Tests[0].CompletedStages)
+}
+
+func TestRunTestResumesWithTeardownWhichAlwaysRuns(t *testing.T) {
+	defer setStateDir(t)()
+	calls := make(map[string]int)
+	tokens := []string{}
+	test := strings.Replace(resumeTest, "  - name: teardown\n", "  - name: teardown\n    alwaysRuns: true\n", 1)
+
+	state := NewRunState()
+	controller := newResumeController(t, calls, &tokens, true)
+	controller.SetRunState(state)
+	assert.Error(t, controller.runTest([]byte(test)))
+	assert.Equal(t, map[string]int{"setup": 1, "test": 1, "teardown": 1}, calls)
+
+	loaded, err := LoadRunState(state.ID)
+	assert.NoError(t, err)
+	assert.Equal(t, []string{"setup"}, loaded.Tests[0].CompletedStages)
+
+	controller = newResumeController(t, calls, &tokens, false)
+	controller.SetRunState(loaded)
+	assert.NoError(t, controller.runTest([]byte(test)))
+	assert.Equal(t, map[string]int{"setup": 1, "test": 2, "teardown": 2}, calls)
+	assert.Equal(t, []string{"setup", "test", "teardown"}, loaded.Tests[0].CompletedStages)
+}
+
+func TestRunTestSavesContainersOfEachMatrixCombination(t *testing.T) {
+	defer setStateDir(t)()
+	test := `
+name: matrix-resume-test
+description: example description
+matrix:
+  parameters:
+    NAME: [first, second]
+  parallel: 2
+stages:
+  - name: setup
+    steps:
+      - description: "container-step"
+`
+	state := NewRunState()
+	for _, name := range []string{"first", "second"} {
+		saved := state.getTest("", map[string]string{"NAME": name})
+		saved.Containers = []*docker.ContainerInfo{{Name: "database", ID: name, Image: "postgres"}}
+	}
+
+	controller, err := NewController()
+	assert.NoError(t, err)
+	seen := make(map[string][]*docker.ContainerInfo)
+	lock := sync.Mutex{}
+	assert.NoError(t, controller.AddTestStep("container-step", func(step *model.Step) error {
+		lock.Lock()
+		defer lock.Unlock()
+		seen[step.GetGlobalVariable("NAME")] = step.Docker.GetCreatedContainers()
+		step.SetPassed()
+		return nil
+	}))
+	controller.SetRunState(state)
+	assert.NoError(t, controller.runTest([]byte(test)))
+
+	for _, name := range []string{"first", "second"} {
+		expected := []*docker.ContainerInfo{{Name: "database", ID: name, Image: "postgres"}}
+		assert.Equal(t, expected, seen[name], name)
+		assert.Equal(t, expected, state.getTest("", map[string]string{"NAME": name}).Containers, name)
+	}
+	assert.Equal(t, []*docker.ContainerInfo{}, controller.docker.GetCreatedContainers())
+}
+
+func TestRunTestFromStage(t *testing.T) {
+	calls := make(map[string]int)
+	tokens := []string{}
+
+	controller := newResumeController(t, calls, &tokens, false)
+	controller.SetFromStage("test")
+	assert.NoError(t, controller.runTest([]byte(resumeTest)))
+	assert.Equal(t, map[string]int{"test": 1, "teardown": 1}, calls)
+	assert.Equal(t, []string{"${steps.setup.outputs.token}"}, tokens)
+
+	controller.SetFromStage("random")
+	err := controller.runTest([]byte(resumeTest))
+	if assert.Error(t, err) {
+		assert.Equal(t, "Could not find stage 'random' to run from in test 'resume-test'", err.Error())
+	}
+}
+
+func TestRunTestWarnsWhenStateCanNotBeSaved(t *testing.T) {
+	dir, err := ioutil.TempDir("", "simple-e2e-state")
+	assert.NoError(t, err)
+	defer os.RemoveAll(dir)
+	file := filepath.Join(dir, "file")
+	assert.NoError(t, ioutil.WriteFile(file, []byte{}, 0644))
+	previous := os.Getenv(util.StateDirEnv)
+	os.Setenv(util.StateDirEnv, file)
+	defer os.Setenv(util.StateDirEnv, previous)
+
+	controller := newResumeController(t, make(map[string]int), &[]string{}, false)
+	state := NewRunState()
+	controller.SetRunState(state)
+	assert.NoError(t, controller.runTest([]byte(resumeTest)))
+	assert.False(t, state.IsSaved())
+}
@@ -8,6 +8,13 @@ import (
 	model "github.com/julianGoh17/simple-e2e/framework/models"
 )
 
+// descriptionPlaceholders are the placeholders which a step description can use in place of a quoted value, and the regular expressions which
+// the quoted values in the description of a step must match
+var descriptionPlaceholders = map[string]string{
+	"'${string}'":   "('[A-Za-z]+')",
+	"'${duration}'": "('[0-9][0-9A-Za-zµ., ]*')",
+}
+
 // StepManager is the object that
 type StepManager struct {
 	regexTestMethods   map[string]func(*model.Step) error
@@ -43,7 +50,12 @@ func (stepManager *StepManager) AddStepToManager(description string, method func
 }
 
 func (stepManager *StepManager) isRegexDescription(description string) bool {
-	return strings.Contains(description, "'${string}'")
+	for placeholder := range descriptionPlaceholders {
+		if strings.Contains(description, placeholder) {
+			return true
+		}
+	}
+	return false
 }
 
 func (stepManager *StepManager) addRegexTestStep(description string, method func(*model.Step) error) error {
@@ -51,7 +63,10 @@ func (stepManager *StepManager) addRegexTestStep(description string, method func
 		Str("step", description).
 		Bool("isRegex", true).
 		Msg("Adding step to regex test steps")
-	parsedString := strings.ReplaceAll(description, "'${string}'", "('[A-Za-z]+')")
+	parsedString := description
+	for placeholder, pattern := range descriptionPlaceholders {
+		parsedString = strings.ReplaceAll(parsedString, placeholder, pattern)
+	}
 	_, err := regexp.Compile(parsedString)
 	if err != nil {
 		return err
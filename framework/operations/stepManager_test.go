@@ -14,8 +14,23 @@ const (
 	literalDescription      = "This is a literal string"
 	invalidRegexDescription = `'${string}'r([a-z]+)gosdf[`
 	regexKey                = "This is a '('[A-Za-z]+')'"
+	durationDescription     = "Wait for '${duration}'"
 )
 
+// countDefaultSteps returns how many of the default steps have literal descriptions and how many have placeholders in their descriptions
+func countDefaultSteps() (int, int) {
+	stepManager := &StepManager{}
+	literal, regex := 0, 0
+	for description := range getDefaultSteps() {
+		if stepManager.isRegexDescription(description) {
+			regex++
+		} else {
+			literal++
+		}
+	}
+	return literal, regex
+}
+
 func TestAddTestStep(t *testing.T) {
 	tables := []struct {
 		descriptions []string
@@ -49,6 +64,7 @@ func TestAddTestStep(t *testing.T) {
 		},
 	}
 
+	defaultLiteral, defaultRegex := countDefaultSteps()
 	for _, table := range tables {
 		stepManager := NewStepManager()
 		errMsg := fmt.Sprintf("Failed for descriptions '%s'", table.descriptions)
@@ -59,8 +75,8 @@ func TestAddTestStep(t *testing.T) {
 				assert.NoError(t, stepManager.AddStepToManager(description, testFuncPassStep), errMsg)
 			}
 		}
-		assert.Equal(t, len(table.literalKeys), len(stepManager.literalTestMethods)-len(getDefaultSteps()), errMsg)
-		assert.Equal(t, len(table.regexKeys), len(stepManager.regexTestMethods), errMsg)
+		assert.Equal(t, len(table.literalKeys), len(stepManager.literalTestMethods)-defaultLiteral, errMsg)
+		assert.Equal(t, len(table.regexKeys), len(stepManager.regexTestMethods)-defaultRegex, errMsg)
 
 		for key := range stepManager.literalTestMethods {
 			assert.NotNil(t, stepManager.literalTestMethods[key], errMsg)
@@ -98,6 +114,21 @@ func TestGetTestStep(t *testing.T) {
 			literalDescription + " random suffix",
 			true,
 		},
+		{
+			durationDescription,
+			"Wait for '1m30s'",
+			false,
+		},
+		{
+			durationDescription,
+			"Wait for '1 minute and 30.5 seconds'",
+			false,
+		},
+		{
+			durationDescription,
+			"Wait for 'ever'",
+			true,
+		},
 	}
 
 	for _, table := range tables {
@@ -118,8 +149,9 @@ func TestAddingDuplicateRegexTestSteps(t *testing.T) {
 	assert.NoError(t, stepManager.AddStepToManager(regexDescription, testFuncPassStep))
 	assert.Error(t, stepManager.AddStepToManager(regexDescription, testFuncPassStep))
 
-	assert.Equal(t, len(getDefaultSteps()), len(stepManager.literalTestMethods))
-	assert.Equal(t, 1, len(stepManager.regexTestMethods))
+	defaultLiteral, defaultRegex := countDefaultSteps()
+	assert.Equal(t, defaultLiteral, len(stepManager.literalTestMethods))
+	assert.Equal(t, 1+defaultRegex, len(stepManager.regexTestMethods))
 }
 
 func TestAddingDuplicateLiteralTestSteps(t *testing.T) {
@@ -128,10 +160,14 @@ func TestAddingDuplicateLiteralTestSteps(t *testing.T) {
 	assert.NoError(t, stepManager.AddStepToManager(literalDescription, testFuncPassStep))
 	assert.Error(t, stepManager.AddStepToManager(literalDescription, testFuncPassStep))
 
-	assert.Equal(t, 1+len(getDefaultSteps()), len(stepManager.literalTestMethods))
-	assert.Equal(t, 0, len(stepManager.regexTestMethods))
+	defaultLiteral, defaultRegex := countDefaultSteps()
+	assert.Equal(t, 1+defaultLiteral, len(stepManager.literalTestMethods))
+	assert.Equal(t, defaultRegex, len(stepManager.regexTestMethods))
 }
 
 func TestMain(m *testing.M) {
+	internal.SetStateRoot()
+	internal.SetArtifactsRoot()
+	internal.SetWorkspaceRoot()
 	internal.TestCoverageReaches85Percent(m)
 }
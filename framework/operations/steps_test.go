@@ -0,0 +1,16 @@
+package operations
+
+import (
+	"testing"
+
+	"github.com/julianGoh17/simple-e2e/framework/models"
+	"github.com/stretchr/testify/assert"
+	"gopkg.in/yaml.v2"
+)
+
+// newStep returns a step with the description and the variables, which are written as YAML indented under 'variables:' as they are in a test file
+func newStep(t *testing.T, description, variables string) *models.Step {
+	step := &models.Step{}
+	assert.NoError(t, yaml.UnmarshalStrict([]byte("description: "+description+"\nvariables:\n"+variables), step))
+	return step
+}
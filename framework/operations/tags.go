@@ -0,0 +1,63 @@
+package operations
+
+import (
+	"fmt"
+
+	model "github.com/julianGoh17/simple-e2e/framework/models"
+)
+
+// tagFilter selects stages by their tags. Tag expressions use the same syntax as 'if' expressions where each name is true if the stage has
+// that tag, for example 'smoke && !slow'.
+type tagFilter struct {
+	include *condition
+	exclude *condition
+}
+
+func newTagFilter(include, exclude string) (*tagFilter, error) {
+	filter := &tagFilter{}
+	var err error
+	if filter.include, err = parseTagExpression(include); err != nil {
+		return nil, err
+	}
+	if filter.exclude, err = parseTagExpression(exclude); err != nil {
+		return nil, err
+	}
+	return filter, nil
+}
+
+func parseTagExpression(expression string) (*condition, error) {
+	if expression == "" {
+		return nil, nil
+	}
+	parsed, err := parseCondition(expression)
+	if err != nil {
+		return nil, fmt.Errorf("Invalid tag expression '%s': %v", expression, err)
+	}
+	if parsed.usesStatus {
+		return nil, fmt.Errorf("Invalid tag expression '%s': Tag expressions can not use status functions", expression)
+	}
+	return parsed, nil
+}
+
+// matches returns whether the tags are selected by the include expression and not by the exclude expression
+func (filter *tagFilter) matches(tags []string) bool {
+	if filter == nil {
+		return true
+	}
+	set := make(map[string]bool, len(tags))
+	for _, tag := range tags {
+		set[tag] = true
+	}
+	context := &conditionContext{lookup: func(name string) (interface{}, bool) {
+		return set[name], set[name]
+	}}
+	if filter.include != nil && !isTruthy(filter.include.root.evaluate(context)) {
+		return false
+	}
+	return filter.exclude == nil || !isTruthy(filter.exclude.root.evaluate(context))
+}
+
+// getStageTags returns the tags of the stage together with the tags of the procedure it belongs to
+func getStageTags(procedure *model.Procedure, stage *model.Stage) []string {
+	return append(append([]string{}, procedure.Tags...), stage.Tags...)
+}
@@ -0,0 +1,62 @@
+package operations
+
+import (
+	"fmt"
+	"testing"
+
+	model "github.com/julianGoh17/simple-e2e/framework/models"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestTagFilterMatches(t *testing.T) {
+	tables := []struct {
+		include  string
+		exclude  string
+		tags     []string
+		expected bool
+	}{
+		{"", "", []string{}, true},
+		{"smoke", "", []string{"smoke", "slow"}, true},
+		{"smoke", "", []string{"slow"}, false},
+		{"smoke && !slow", "", []string{"smoke", "slow"}, false},
+		{"smoke && !slow", "", []string{"smoke"}, true},
+		{"smoke || db-tests", "", []string{"db-tests"}, true},
+		{"", "slow", []string{"smoke", "slow"}, false},
+		{"", "slow", []string{}, true},
+		{"smoke", "flaky", []string{"smoke", "flaky"}, false},
+	}
+
+	for _, table := range tables {
+		filter, err := newTagFilter(table.include, table.exclude)
+		assert.NoError(t, err)
+		assert.Equal(t, table.expected, filter.matches(table.tags), fmt.Sprintf("%s / %s", table.include, table.exclude))
+	}
+
+	var filter *tagFilter
+	assert.True(t, filter.matches([]string{"smoke"}))
+}
+
+func TestNewTagFilterFails(t *testing.T) {
+	tables := []struct {
+		include string
+		exclude string
+		err     string
+	}{
+		{"smoke &&", "", "Invalid tag expression 'smoke &&': Unexpected end of expression 'smoke &&'"},
+		{"", "failure()", "Invalid tag expression 'failure()': Tag expressions can not use status functions"},
+	}
+
+	for _, table := range tables {
+		_, err := newTagFilter(table.include, table.exclude)
+		if assert.Error(t, err) {
+			assert.Equal(t, table.err, err.Error())
+		}
+	}
+}
+
+func TestGetStageTags(t *testing.T) {
+	procedure := &model.Procedure{Tags: []string{"example"}}
+	stage := &model.Stage{Tags: []string{"smoke"}}
+	assert.Equal(t, []string{"example", "smoke"}, getStageTags(procedure, stage))
+	assert.Equal(t, []string{"example"}, procedure.Tags)
+}
@@ -0,0 +1,145 @@
+package operations
+
+import (
+	"regexp"
+	"strings"
+
+	"github.com/julianGoh17/simple-e2e/framework/docker"
+	model "github.com/julianGoh17/simple-e2e/framework/models"
+)
+
+// testRun holds the state of a single run of a procedure. There is one testRun for each matrix combination of the procedure.
+type testRun struct {
+	variables map[string]string
+	overrides map[string]VariableOverride
+	outputs   map[string]map[string]string
+	statuses  map[string]model.ResultStatus
+	result    *model.TestResult
+	// docker creates the containers of the run's steps, and is nil when the steps use the handler of the controller
+	docker *docker.Handler
+	// state is where the run is saved after each stage which passes, and is nil if the run is not saved
+	state *TestState
+	// workspace is the directory which the file steps of the run read and write files in, and is empty until it is created
+	workspace string
+	// timers are started and measured by the timer steps of the run
+	timers *model.Timers
+}
+
+// invalidNamePattern matches the characters which can not be in the name of a Docker container
+var invalidNamePattern = regexp.MustCompile(`[^a-zA-Z0-9_.-]+`)
+
+// newTestRun returns the state of a run whose variables are the global variables and secrets of the procedure overridden by the values of the
+// matrix combination, which are then overridden by the variables given from outside of the test file
+func newTestRun(procedure *model.Procedure, secrets, combination map[string]string, overrides map[string]VariableOverride) *testRun {
+	variables := make(map[string]string)
+	for key, value := range procedure.GlobalVariables {
+		variables[key] = value
+	}
+	for key, value := range secrets {
+		variables[key] = value
+	}
+	for key, value := range combination {
+		variables[key] = value
+	}
+	for key, override := range overrides {
+		variables[key] = override.Value
+	}
+
+	return &testRun{
+		variables: variables,
+		overrides: overrides,
+		outputs:   make(map[string]map[string]string),
+		statuses:  make(map[string]model.ResultStatus),
+		timers:    model.NewTimers(),
+		result: &model.TestResult{
+			Name:        procedure.Name,
+			Combination: combination,
+			Stages:      []*model.StageResult{},
+		},
+	}
+}
+
+// prepareStep returns a copy of the step with the run's variables interpolated so the same procedure can be used by multiple runs. The loop
+// variables of the current iteration of the step take precedence over the run's variables, and step variables with the same name as a variable
+// given from outside of the test file are replaced by it.
+func (run *testRun) prepareStep(step model.Step, loopVariables map[string]string) model.Step {
+	prepared := step.Clone()
+	prepared.SetRunVariables(run.variables)
+	prepared.SetWorkspace(run.workspace)
+	prepared.SetTimers(run.timers)
+	prepared.SetCombination(run.result.Combination)
+	if run.docker != nil {
+		prepared.Docker = run.docker
+	}
+	prepared.Interpolate(func(name string) (interface{}, bool) {
+		if value, ok := loopVariables[name]; ok {
+			return value, true
+		}
+		return run.lookup(name)
+	})
+	for key, override := range run.overrides {
+		if _, ok := prepared.Variables[key]; ok {
+			prepared.SetVariable(key, override.Value)
+		}
+	}
+	return prepared
+}
+
+// getCombinationNamespace returns the Docker namespace of the run of a matrix combination, such as 'DB-postgres-VERSION-12' for
+// 'DB=postgres, VERSION=12'
+func getCombinationNamespace(combination map[string]string) string {
+	return cleanName(model.CombinationToString(combination))
+}
+
+// cleanName returns the name with the characters which can not be in the name of a Docker container replaced with '-', so it can be used
+// in the names of containers and files
+func cleanName(name string) string {
+	return strings.Trim(invalidNamePattern.ReplaceAllString(name, "-"), "-.")
+}
+
+// lookup returns the value of a run variable, 'steps.<id>.outputs.<name>' or 'steps.<id>.status'
+func (run *testRun) lookup(name string) (interface{}, bool) {
+	if value, ok := run.variables[name]; ok {
+		return value, ok
+	}
+	parts := strings.SplitN(name, ".", 4)
+	if len(parts) < 3 || parts[0] != "steps" {
+		return nil, false
+	}
+	if len(parts) == 3 && parts[2] == "status" {
+		status, ok := run.statuses[parts[1]]
+		return model.MapResultStatusToString(status), ok
+	}
+	if len(parts) == 4 && parts[2] == "outputs" {
+		value, ok := run.outputs[parts[1]][parts[3]]
+		return value, ok
+	}
+	return nil, false
+}
+
+// recordStep will save the status and outputs of a step with an id so they can be used by later steps
+func (run *testRun) recordStep(step *model.Step, status model.ResultStatus) {
+	if step.ID == "" {
+		return
+	}
+	run.statuses[step.ID] = status
+	outputs := make(map[string]string)
+	for name, value := range step.GetOutputs() {
+		outputs[name] = value
+	}
+	run.outputs[step.ID] = outputs
+}
+
+// beginStage will add the result of a new stage to the run's result
+func (run *testRun) beginStage(name string) *model.StageResult {
+	result := &model.StageResult{Name: name, Status: model.Failed, Steps: []model.StepResult{}}
+	run.result.Stages = append(run.result.Stages, result)
+	return result
+}
+
+// skipStage will add the result of a stage which was not run to the run's result
+func (run *testRun) skipStage(stage *model.Stage) {
+	result := &model.StageResult{Name: stage.Name, Status: model.Skipped, Steps: []model.StepResult{}}
+	result.SkipSteps(stage.Steps)
+	run.result.Stages = append(run.result.Stages, result)
+}
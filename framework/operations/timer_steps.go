@@ -0,0 +1,113 @@
+package operations
+
+import (
+	"fmt"
+	"time"
+
+	"github.com/julianGoh17/simple-e2e/framework/models"
+)
+
+// defaultTimer is the name of the timer which the timer steps use when they are not given 'TIMER'
+const defaultTimer = "default"
+
+// Sleep will wait for the duration in the description of the step, such as "Sleep for '500ms'" or "Sleep for '1 minute and 30 seconds'"
+func Sleep(step *models.Step) error {
+	traceStepEntrance(step)
+	duration, err := getDescriptionDuration(step)
+	if err != nil {
+		return traceStepExit(step, err)
+	}
+
+	logger.Info().
+		Str("step", step.Description).
+		Str("duration", duration.String()).
+		Msg("Sleeping")
+	timer := time.NewTimer(duration)
+	defer timer.Stop()
+	ctx := step.GetContext()
+	select {
+	case <-timer.C:
+		return traceStepExit(step, nil)
+	case <-ctx.Done():
+		return traceStepExit(step, fmt.Errorf("Step '%s' was interrupted while sleeping: %v", step.Description, ctx.Err()))
+	}
+}
+
+// StartTimer will start a timer of the test run, restarting it if it has already been started, so that a later 'Assert elapsed less than' step
+// can check how much time has elapsed since this step. The time the timer was started is saved as the output 'startedAt'.
+// Environmental Variables:
+//   - TIMER: The name of the timer (default 'default')
+func StartTimer(step *models.Step) error {
+	traceStepEntrance(step)
+	timers, err := getStepTimers(step)
+	if err != nil {
+		return traceStepExit(step, err)
+	}
+	name := step.GetValueFromVariablesAsStringOrDefault("TIMER", defaultTimer)
+	started := timers.Start(name)
+	step.SetOutput("startedAt", started.Format(time.RFC3339Nano))
+	return traceStepExit(step, nil)
+}
+
+// AssertElapsedLessThan will check that less time than a duration has elapsed since a timer was started. The elapsed time is added to the result
+// of the step and saved as the output 'elapsed'.
+// Environmental Variables:
+//   - DURATION: The time which must not have elapsed yet, such as '250ms' or '2 seconds'
+//   - TIMER: The name of the timer (default 'default')
+func AssertElapsedLessThan(step *models.Step) error {
+	traceStepEntrance(step)
+	timers, err := getStepTimers(step)
+	if err != nil {
+		return traceStepExit(step, err)
+	}
+	limit, err := step.GetValueFromVariablesAsDuration("DURATION")
+	if err != nil {
+		return traceStepExit(step, err)
+	}
+	name := step.GetValueFromVariablesAsStringOrDefault("TIMER", defaultTimer)
+	elapsed, err := timers.Elapsed(name)
+	if err != nil {
+		return traceStepExit(step, fmt.Errorf("%v by a 'Start timer' step", err))
+	}
+
+	step.RecordTiming(name, elapsed)
+	step.SetOutput("elapsed", elapsed.String())
+	logger.Info().
+		Str("timer", name).
+		Str("elapsed", elapsed.String()).
+		Str("limit", limit.String()).
+		Msg("Measured elapsed time of timer")
+	if elapsed >= limit {
+		return traceStepExit(step, fmt.Errorf("Timer '%s' measured %s which is not less than %s", name, elapsed.Round(time.Millisecond), limit))
+	}
+	return traceStepExit(step, nil)
+}
+
+// getDescriptionDuration returns the duration quoted in the description of the step, which can not be negative
+func getDescriptionDuration(step *models.Step) (time.Duration, error) {
+	values, err := step.GetDescriptionVariables()
+	if err != nil {
+		return 0, err
+	}
+	if len(values) != 1 {
+		return 0, fmt.Errorf("Step '%s' must have a single quoted duration in its description", step.Description)
+	}
+	converter := &models.TypeConverter{}
+	duration, err := converter.GetDuration(values[0])
+	if err != nil {
+		return 0, err
+	}
+	if duration < 0 {
+		return 0, fmt.Errorf("Duration '%s' of step '%s' can not be negative", values[0], step.Description)
+	}
+	return duration, nil
+}
+
+// getStepTimers returns the timers of the test run which is running the step
+func getStepTimers(step *models.Step) (*models.Timers, error) {
+	timers := step.GetTimers()
+	if timers == nil {
+		return nil, fmt.Errorf("Step '%s' does not have timers as it is not part of a test run", step.Description)
+	}
+	return timers, nil
+}
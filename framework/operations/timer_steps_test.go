@@ -0,0 +1,159 @@
+package operations
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/julianGoh17/simple-e2e/framework/models"
+	"github.com/stretchr/testify/assert"
+)
+
+const timerRun = `
+name: timer-test
+stages:
+  - name: example-stage
+    steps:
+      - description: Start timer
+        id: start
+        variables:
+          TIMER: request
+      - description: Sleep for '${DELAY}'
+      - description: Assert elapsed less than
+        id: check
+        variables:
+          TIMER: request
+          DURATION: ${LIMIT}`
+
+func TestSleep(t *testing.T) {
+	tables := []struct {
+		description string
+		minimum     time.Duration
+		err         string
+	}{
+		{"Sleep for '20ms'", 20 * time.Millisecond, ""},
+		{"Sleep for '0.02 seconds'", 20 * time.Millisecond, ""},
+		{"Sleep for '0s'", 0, ""},
+		{"Sleep for 'a while'", 0, "Could not convert 'a while' to type 'time.Duration'"},
+		{"Sleep for '-5s'", 0, "Duration '-5s' of step 'Sleep for '-5s'' can not be negative"},
+		{"Sleep for '1s' and '2s'", 0, "Step 'Sleep for '1s' and '2s'' must have a single quoted duration in its description"},
+		{"Sleep for '1s", 0, "Test Step 'Sleep for '1s' is ill formatted because it contains an odd number of ','"},
+	}
+
+	for _, table := range tables {
+		step := &models.Step{Description: table.description}
+		start := time.Now()
+		err := Sleep(step)
+		if table.err != "" {
+			assert.EqualError(t, err, table.err)
+			assert.False(t, step.HasSucceeded())
+			continue
+		}
+		assert.NoError(t, err, table.description)
+		assert.True(t, step.HasSucceeded())
+		assert.True(t, time.Since(start) >= table.minimum, table.description)
+	}
+}
+
+func TestSleepIsInterrupted(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	step := &models.Step{Description: "Sleep for '1 minute'"}
+	step.SetContext(ctx)
+	time.AfterFunc(10*time.Millisecond, cancel)
+
+	start := time.Now()
+	err := Sleep(step)
+	assert.EqualError(t, err, "Step 'Sleep for '1 minute'' was interrupted while sleeping: context canceled")
+	assert.True(t, time.Since(start) < time.Second)
+}
+
+func TestStartTimerAndAssertElapsedLessThan(t *testing.T) {
+	timers := models.NewTimers()
+	start := &models.Step{Description: "Start timer"}
+	start.SetTimers(timers)
+	assert.NoError(t, StartTimer(start))
+	assert.True(t, start.HasSucceeded())
+	startedAt, err := time.Parse(time.RFC3339Nano, start.GetOutputs()["startedAt"])
+	assert.NoError(t, err)
+	assert.WithinDuration(t, time.Now(), startedAt, time.Second)
+	time.Sleep(10 * time.Millisecond)
+
+	tables := []struct {
+		variables map[string]string
+		err       string
+	}{
+		{map[string]string{"DURATION": "1 minute"}, ""},
+		{map[string]string{"DURATION": "1m", "TIMER": "default"}, ""},
+		{map[string]string{"DURATION": "1ms"}, "Timer 'default' measured "},
+		{map[string]string{"DURATION": "1m", "TIMER": "other"}, "Timer 'other' has not been started by a 'Start timer' step"},
+		{map[string]string{}, "Could not find variable 'DURATION' in step.variables"},
+		{map[string]string{"DURATION": "soon"}, "Could not convert 'soon' to type 'time.Duration'"},
+	}
+
+	for _, table := range tables {
+		step := &models.Step{Description: "Assert elapsed less than", Variables: table.variables}
+		step.SetTimers(timers)
+		err := AssertElapsedLessThan(step)
+		if table.err != "" {
+			if assert.Error(t, err, table.variables) {
+				assert.Contains(t, err.Error(), table.err)
+			}
+			assert.False(t, step.HasSucceeded())
+			continue
+		}
+		assert.NoError(t, err, table.variables)
+		assert.True(t, step.HasSucceeded())
+		elapsed, err := time.ParseDuration(step.GetOutputs()["elapsed"])
+		assert.NoError(t, err)
+		assert.True(t, elapsed >= 10*time.Millisecond, elapsed)
+		assert.Equal(t, map[string]time.Duration{"default": elapsed}, step.GetTimings())
+	}
+
+	step := &models.Step{Description: "Assert elapsed less than", Variables: map[string]string{"DURATION": "1ms"}}
+	step.SetTimers(timers)
+	err = AssertElapsedLessThan(step)
+	if assert.Error(t, err) {
+		assert.Regexp(t, `^Timer 'default' measured [0-9.]+ms which is not less than 1ms$`, err.Error())
+	}
+	assert.Contains(t, step.GetTimings(), "default")
+}
+
+func TestTimerStepsNeedATestRun(t *testing.T) {
+	step := &models.Step{Description: "Start timer"}
+	assert.EqualError(t, StartTimer(step), "Step 'Start timer' does not have timers as it is not part of a test run")
+	step = &models.Step{Description: "Assert elapsed less than", Variables: map[string]string{"DURATION": "1s"}}
+	assert.EqualError(t, AssertElapsedLessThan(step), "Step 'Assert elapsed less than' does not have timers as it is not part of a test run")
+}
+
+func TestRunTestRecordsTimings(t *testing.T) {
+	tables := []struct {
+		delay  string
+		limit  string
+		status models.ResultStatus
+	}{
+		{"10ms", "1 minute", models.Passed},
+		{"20 milliseconds", "5ms", models.Failed},
+	}
+
+	for _, table := range tables {
+		controller, err := NewController()
+		assert.NoError(t, err)
+		controller.SetVariableOverrides(map[string]VariableOverride{"DELAY": {Value: table.delay}, "LIMIT": {Value: table.limit}})
+		err = controller.runTest([]byte(timerRun))
+		results := controller.GetResults()
+		if !assert.Equal(t, 1, len(results)) {
+			continue
+		}
+		assert.Equal(t, table.status, results[0].Status, table.delay)
+		steps := results[0].Stages[0].Steps
+		assert.Equal(t, "Sleep for '"+table.delay+"'", steps[1].Description)
+		assert.Nil(t, steps[1].Timings)
+		assert.Equal(t, table.status, steps[2].Status)
+		assert.True(t, steps[2].Timings["request"] >= 10*time.Millisecond, steps[2].Timings)
+		if table.status == models.Failed {
+			assert.Error(t, err)
+		} else {
+			assert.NoError(t, err)
+		}
+	}
+}
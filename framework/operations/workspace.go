@@ -0,0 +1,70 @@
+package operations
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+
+	model "github.com/julianGoh17/simple-e2e/framework/models"
+	"github.com/julianGoh17/simple-e2e/framework/util"
+)
+
+// workspaceVariable is the run variable which holds the path of the run's workspace, so steps can refer to it as '${WORKSPACE}'
+const workspaceVariable = "WORKSPACE"
+
+// createWorkspace will create an empty directory under '<WORKSPACE_DIR>' for the files of the run and add its path to the run variables,
+// unless the test already has a variable with the same name
+func (run *testRun) createWorkspace() error {
+	dir := filepath.Join(config.GetOrDefault(util.WorkspaceDirEnv), newRunID())
+	if err := os.MkdirAll(dir, 0755); err != nil {
+		return fmt.Errorf("Could not create workspace of test '%s': %v", run.result.Name, err)
+	}
+	run.workspace = dir
+	if _, ok := run.variables[workspaceVariable]; !ok {
+		run.variables[workspaceVariable] = dir
+	}
+	logger.Debug().
+		Str("test", run.result.Name).
+		Str("workspace", dir).
+		Msg("Created workspace of test run")
+	return nil
+}
+
+// removeWorkspace will delete the workspace of a run which passed. The workspace of a run which failed is kept so its files can be looked at.
+func (run *testRun) removeWorkspace(err error) {
+	if run.workspace == "" {
+		return
+	}
+	if err != nil {
+		logger.Info().
+			Str("test", run.result.Name).
+			Str("workspace", run.workspace).
+			Msg("Keeping workspace of failed test run")
+		return
+	}
+	if err := os.RemoveAll(run.workspace); err != nil {
+		logger.Warn().
+			Err(err).
+			Str("workspace", run.workspace).
+			Msg("Could not remove workspace of test run")
+	}
+}
+
+// getWorkspacePath returns the absolute path of a path relative to the workspace of the step's run, and an error if the path is outside of
+// the workspace
+func getWorkspacePath(step *model.Step, path string) (string, error) {
+	workspace := step.GetWorkspace()
+	if workspace == "" {
+		return "", fmt.Errorf("Step '%s' does not have a workspace as it is not part of a test run", step.Description)
+	}
+	resolved := filepath.Clean(path)
+	if !filepath.IsAbs(resolved) {
+		resolved = filepath.Join(workspace, resolved)
+	}
+	relative, err := filepath.Rel(workspace, resolved)
+	if err != nil || relative == ".." || strings.HasPrefix(relative, ".."+string(filepath.Separator)) {
+		return "", fmt.Errorf("Path '%s' of step '%s' must be inside the workspace '%s'", path, step.Description, workspace)
+	}
+	return resolved, nil
+}
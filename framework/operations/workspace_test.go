@@ -0,0 +1,115 @@
+package operations
+
+import (
+	"fmt"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"testing"
+
+	models "github.com/julianGoh17/simple-e2e/framework/models"
+	"github.com/julianGoh17/simple-e2e/framework/util"
+	"github.com/stretchr/testify/assert"
+)
+
+const workspaceRun = `
+name: workspace-test
+stages:
+  - name: example-stage
+    steps:
+      - description: workspace-step
+        variables:
+          DIR: ${WORKSPACE}`
+
+func TestGetWorkspacePath(t *testing.T) {
+	step := &models.Step{Description: "Write file"}
+	step.SetWorkspace("/tmp/workspace")
+
+	tables := []struct {
+		path     string
+		resolved string
+		err      string
+	}{
+		{"config.yaml", "/tmp/workspace/config.yaml", ""},
+		{"./configs/../config.yaml", "/tmp/workspace/config.yaml", ""},
+		{".", "/tmp/workspace", ""},
+		{"/tmp/workspace/config.yaml", "/tmp/workspace/config.yaml", ""},
+		{"../config.yaml", "", "Path '../config.yaml' of step 'Write file' must be inside the workspace '/tmp/workspace'"},
+		{"/etc/passwd", "", "Path '/etc/passwd' of step 'Write file' must be inside the workspace '/tmp/workspace'"},
+		{"/tmp/workspace-other/config.yaml", "", "must be inside the workspace"},
+	}
+
+	for _, table := range tables {
+		resolved, err := getWorkspacePath(step, table.path)
+		if table.err != "" {
+			if assert.Error(t, err, table.path) {
+				assert.Contains(t, err.Error(), table.err)
+			}
+			continue
+		}
+		assert.NoError(t, err, table.path)
+		assert.Equal(t, table.resolved, resolved)
+	}
+
+	_, err := getWorkspacePath(&models.Step{Description: "Write file"}, "config.yaml")
+	assert.EqualError(t, err, "Step 'Write file' does not have a workspace as it is not part of a test run")
+}
+
+func TestCreateAndRemoveWorkspace(t *testing.T) {
+	procedure := &models.Procedure{Name: "workspace-test"}
+	run := newTestRun(procedure, nil, map[string]string{}, nil)
+	assert.NoError(t, run.createWorkspace())
+	assert.DirExists(t, run.workspace)
+	assert.Equal(t, run.workspace, run.variables[workspaceVariable])
+	run.removeWorkspace(fmt.Errorf("failed"))
+	assert.DirExists(t, run.workspace)
+	run.removeWorkspace(nil)
+	_, err := os.Stat(run.workspace)
+	assert.True(t, os.IsNotExist(err))
+
+	procedure.GlobalVariables = map[string]string{workspaceVariable: "mine"}
+	run = newTestRun(procedure, nil, map[string]string{}, nil)
+	assert.NoError(t, run.createWorkspace())
+	defer os.RemoveAll(run.workspace)
+	assert.Equal(t, "mine", run.variables[workspaceVariable])
+	assert.NotEqual(t, "", run.workspace)
+
+	newTestRun(procedure, nil, map[string]string{}, nil).removeWorkspace(nil)
+}
+
+func TestCreateWorkspaceFails(t *testing.T) {
+	file, err := ioutil.TempFile("", "simple-e2e-workspace")
+	assert.NoError(t, err)
+	file.Close()
+	defer os.Remove(file.Name())
+	previous := os.Getenv(util.WorkspaceDirEnv)
+	os.Setenv(util.WorkspaceDirEnv, file.Name())
+	defer os.Setenv(util.WorkspaceDirEnv, previous)
+
+	run := newTestRun(&models.Procedure{Name: "workspace-test"}, nil, map[string]string{}, nil)
+	err = run.createWorkspace()
+	if assert.Error(t, err) {
+		assert.Contains(t, err.Error(), "Could not create workspace of test 'workspace-test'")
+	}
+}
+
+func TestRunTestGivesEachRunAWorkspace(t *testing.T) {
+	controller, err := NewController()
+	assert.NoError(t, err)
+
+	workspace := ""
+	assert.NoError(t, controller.AddTestStep("workspace-step", func(step *models.Step) error {
+		workspace = step.GetWorkspace()
+		dir, err := step.GetValueFromVariablesAsString("DIR")
+		assert.Equal(t, workspace, dir)
+		assert.DirExists(t, workspace)
+		assert.Equal(t, util.NewConfig().GetOrDefault(util.WorkspaceDirEnv), filepath.Dir(workspace))
+		step.SetErrored(err)
+		return err
+	}))
+
+	assert.NoError(t, controller.runTest([]byte(workspaceRun)))
+	assert.NotEqual(t, "", workspace)
+	_, err = os.Stat(workspace)
+	assert.True(t, os.IsNotExist(err))
+}
@@ -12,6 +12,12 @@ const (
 	TestDirEnv = "TEST_DIR"
 	// DockerfileDirEnv is the env var key for the root Dockerfile directory
 	DockerfileDirEnv = "DOCKERFILE_DIR"
+	// StateDirEnv is the env var key for the directory where the state of each run is saved so it can be resumed
+	StateDirEnv = "STATE_DIR"
+	// ArtifactsDirEnv is the env var key for the directory where steps save files such as the output of commands
+	ArtifactsDirEnv = "ARTIFACTS_DIR"
+	// WorkspaceDirEnv is the env var key for the directory where each test run gets its own workspace directory for the files it writes
+	WorkspaceDirEnv = "WORKSPACE_DIR"
 )
 
 // NewConfig object returns the config object initialized with the default values
@@ -33,6 +39,9 @@ func initializeConfig(config *GlobalConfig) {
 	config.defaults = map[string]string{
 		TestDirEnv:       "/home/e2e/tests",
 		DockerfileDirEnv: "/home/e2e/Dockerfiles",
+		StateDirEnv:      "/home/e2e/state",
+		ArtifactsDirEnv:  "/home/e2e/artifacts",
+		WorkspaceDirEnv:  "/home/e2e/workspaces",
 	}
 }
 
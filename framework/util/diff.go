@@ -0,0 +1,132 @@
+package util
+
+import (
+	"fmt"
+	"strings"
+)
+
+const diffContextLines = 3
+
+// diffLine is a line of a diff, whose kind is ' ' for a line in both texts, '-' for a line only in the old text and '+' for a line only in the
+// new text
+type diffLine struct {
+	kind byte
+	text string
+}
+
+// UnifiedDiff returns the differences between the lines of the old and new text in the unified diff format, with three lines of context around
+// every change, or an empty string if the texts are the same
+func UnifiedDiff(oldName, newName, oldText, newText string) string {
+	if oldText == newText {
+		return ""
+	}
+	lines := diffLines(splitLines(oldText), splitLines(newText))
+
+	// oldLines and newLines are the number of lines of each text before every line of the diff, which give the line numbers of the hunks
+	oldLines := make([]int, len(lines)+1)
+	newLines := make([]int, len(lines)+1)
+	changes := []int{}
+	for index, line := range lines {
+		oldLines[index+1], newLines[index+1] = oldLines[index], newLines[index]
+		if line.kind != '+' {
+			oldLines[index+1]++
+		}
+		if line.kind != '-' {
+			newLines[index+1]++
+		}
+		if line.kind != ' ' {
+			changes = append(changes, index)
+		}
+	}
+
+	var diff strings.Builder
+	fmt.Fprintf(&diff, "--- %s\n+++ %s\n", oldName, newName)
+	for index := 0; index < len(changes); {
+		start := maxInt(changes[index]-diffContextLines, 0)
+		end := changes[index]
+		for index < len(changes) && changes[index]-diffContextLines <= end+diffContextLines+1 {
+			end = changes[index]
+			index++
+		}
+		end = minInt(end+diffContextLines, len(lines)-1)
+
+		oldStart, oldCount := oldLines[start]+1, oldLines[end+1]-oldLines[start]
+		newStart, newCount := newLines[start]+1, newLines[end+1]-newLines[start]
+		if oldCount == 0 {
+			oldStart--
+		}
+		if newCount == 0 {
+			newStart--
+		}
+		fmt.Fprintf(&diff, "@@ -%d,%d +%d,%d @@\n", oldStart, oldCount, newStart, newCount)
+		for _, line := range lines[start : end+1] {
+			diff.WriteByte(line.kind)
+			diff.WriteString(line.text)
+			if !strings.HasSuffix(line.text, "\n") {
+				diff.WriteString("\n\\ No newline at end of file\n")
+			}
+		}
+	}
+	return diff.String()
+}
+
+// splitLines returns the lines of the text, keeping the new line at the end of each line so a missing new line at the end of the text is a
+// difference
+func splitLines(text string) []string {
+	lines := strings.SplitAfter(text, "\n")
+	if lines[len(lines)-1] == "" {
+		lines = lines[:len(lines)-1]
+	}
+	return lines
+}
+
+// diffLines returns the lines of both texts in order, marking the lines which are not part of their longest common subsequence as removed or
+// added
+func diffLines(oldLines, newLines []string) []diffLine {
+	// common[i][j] is the length of the longest common subsequence of oldLines[i:] and newLines[j:]
+	common := make([][]int, len(oldLines)+1)
+	for i := range common {
+		common[i] = make([]int, len(newLines)+1)
+	}
+	for i := len(oldLines) - 1; i >= 0; i-- {
+		for j := len(newLines) - 1; j >= 0; j-- {
+			if oldLines[i] == newLines[j] {
+				common[i][j] = common[i+1][j+1] + 1
+			} else {
+				common[i][j] = maxInt(common[i+1][j], common[i][j+1])
+			}
+		}
+	}
+
+	lines := []diffLine{}
+	i, j := 0, 0
+	for i < len(oldLines) || j < len(newLines) {
+		switch {
+		case i < len(oldLines) && j < len(newLines) && oldLines[i] == newLines[j]:
+			lines = append(lines, diffLine{' ', oldLines[i]})
+			i++
+			j++
+		case j == len(newLines) || i < len(oldLines) && common[i+1][j] >= common[i][j+1]:
+			lines = append(lines, diffLine{'-', oldLines[i]})
+			i++
+		default:
+			lines = append(lines, diffLine{'+', newLines[j]})
+			j++
+		}
+	}
+	return lines
+}
+
+func maxInt(a, b int) int {
+	if a > b {
+		return a
+	}
+	return b
+}
+
+func minInt(a, b int) int {
+	if a < b {
+		return a
+	}
+	return b
+}
@@ -38,9 +38,9 @@ func ConfigureGlobalLogLevel(level string) {
 		Msg(fmt.Sprintf("Global logger has been configured to log level '%s'.", logLevel))
 }
 
-// GetStandardLogger will retrieve the standard logger for the project
+// GetStandardLogger will retrieve the standard logger for the project. The values of secrets are masked in everything it logs.
 func GetStandardLogger() *zerolog.Logger {
-	output := zerolog.ConsoleWriter{Out: os.Stdout, TimeFormat: time.RFC3339}
+	output := zerolog.ConsoleWriter{Out: NewSecretMaskingWriter(os.Stdout), TimeFormat: time.RFC3339}
 	log := zerolog.New(output).With().Timestamp().Logger()
 	return &log
 }
@@ -0,0 +1,109 @@
+package util
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+)
+
+const (
+	// MaskedValue is what the value of a secret is replaced with in the logs, results and error messages
+	MaskedValue = "****"
+	// minSecretLength is the length of the shortest value which is masked, as masking shorter values would also mask unrelated text
+	minSecretLength = 4
+)
+
+var secrets = &secretRegistry{values: make(map[string]bool)}
+
+// secretRegistry holds the values of every secret that has been used so far, shared by every test that is run
+type secretRegistry struct {
+	lock   sync.RWMutex
+	values map[string]bool
+}
+
+// RegisterSecret will mask the value in everything that is logged or shown to the user from now on, including where it is escaped in JSON or in
+// quotes. Blank values are ignored, while values shorter than 4 characters return an error as they can not be masked without also masking
+// unrelated text.
+func RegisterSecret(value string) error {
+	length := len(strings.TrimSpace(value))
+	if length == 0 {
+		return nil
+	}
+	if length < minSecretLength {
+		return fmt.Errorf("Could not mask a value shorter than %d characters", minSecretLength)
+	}
+	secrets.lock.Lock()
+	defer secrets.lock.Unlock()
+	for _, form := range getEscapedForms(value) {
+		secrets.values[form] = true
+	}
+	return nil
+}
+
+// getEscapedForms returns the value as it is written in text, in Go's quoted strings and in JSON with and without its HTML characters escaped.
+// Each escaped form is also escaped in JSON again, as it is when JSON or quoted text is logged as a string field.
+func getEscapedForms(value string) []string {
+	forms := []string{value}
+	escaped := []string{trimQuotes(strconv.Quote(value)), escapeJSON(value, true), escapeJSON(value, false)}
+	for _, form := range escaped {
+		forms = append(forms, form, escapeJSON(form, true), escapeJSON(form, false))
+	}
+	return forms
+}
+
+// escapeJSON returns the value as it is written in a JSON string, without the quotes around it
+func escapeJSON(value string, escapeHTML bool) string {
+	var buffer bytes.Buffer
+	encoder := json.NewEncoder(&buffer)
+	encoder.SetEscapeHTML(escapeHTML)
+	if err := encoder.Encode(value); err != nil {
+		return value
+	}
+	return trimQuotes(strings.TrimSpace(buffer.String()))
+}
+
+func trimQuotes(quoted string) string {
+	return strings.TrimSuffix(strings.TrimPrefix(quoted, `"`), `"`)
+}
+
+// MaskSecrets returns the text with every registered secret replaced by MaskedValue
+func MaskSecrets(text string) string {
+	secrets.lock.RLock()
+	defer secrets.lock.RUnlock()
+	if len(secrets.values) == 0 {
+		return text
+	}
+
+	// Longer secrets are replaced first so a secret which contains another secret is masked completely
+	values := make([]string, 0, len(secrets.values))
+	for value := range secrets.values {
+		values = append(values, value)
+	}
+	sort.Slice(values, func(i, j int) bool { return len(values[i]) > len(values[j]) })
+	for _, value := range values {
+		text = strings.ReplaceAll(text, value, MaskedValue)
+	}
+	return text
+}
+
+// secretMaskingWriter masks every registered secret in what is written to it before passing it on
+type secretMaskingWriter struct {
+	out io.Writer
+}
+
+// NewSecretMaskingWriter returns a writer which masks every registered secret before writing to out
+func NewSecretMaskingWriter(out io.Writer) io.Writer {
+	return &secretMaskingWriter{out: out}
+}
+
+func (writer *secretMaskingWriter) Write(p []byte) (int, error) {
+	if _, err := writer.out.Write([]byte(MaskSecrets(string(p)))); err != nil {
+		return 0, err
+	}
+	return len(p), nil
+}
@@ -0,0 +1,102 @@
+package util
+
+import (
+	"bytes"
+	"fmt"
+	"strings"
+	"testing"
+
+	"github.com/rs/zerolog"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestMaskSecrets(t *testing.T) {
+	assert.NoError(t, RegisterSecret("mask-secrets-password"))
+	assert.NoError(t, RegisterSecret("mask-secrets-password-suffix"))
+	assert.NoError(t, RegisterSecret(" "))
+	assert.EqualError(t, RegisterSecret("abc"), "Could not mask a value shorter than 4 characters")
+	assert.NoError(t, RegisterSecret("mask \"secrets\" C:\\key\n<line>"))
+	assert.NoError(t, RegisterSecret("ends with \"quote\""))
+
+	values := []struct {
+		text     string
+		expected string
+	}{
+		{
+			"no secrets here",
+			"no secrets here",
+		},
+		{
+			"password=mask-secrets-password",
+			"password=****",
+		},
+		{
+			"mask-secrets-password-suffix and mask-secrets-password",
+			"**** and ****",
+		},
+		{
+			"a b",
+			"a b",
+		},
+		{
+			"abc def",
+			"abc def",
+		},
+		{
+			"mask \"secrets\" C:\\key\n<line>",
+			"****",
+		},
+		{
+			`{"TOKEN":"mask \"secrets\" C:\\key\n<line>"}`,
+			`{"TOKEN":"****"}`,
+		},
+		{
+			`{"TOKEN":"mask \"secrets\" C:\\key\n\u003cline\u003e"}`,
+			`{"TOKEN":"****"}`,
+		},
+		{
+			`"ends with \"quote\""`,
+			`"****"`,
+		},
+	}
+
+	for _, value := range values {
+		assert.Equal(t, value.expected, MaskSecrets(value.text))
+	}
+}
+
+func TestSecretMaskingWriter(t *testing.T) {
+	RegisterSecret("masking-writer-password")
+	out := &bytes.Buffer{}
+	writer := NewSecretMaskingWriter(out)
+
+	text := []byte("connecting with masking-writer-password")
+	written, err := writer.Write(text)
+	assert.NoError(t, err)
+	assert.Equal(t, len(text), written)
+	assert.Equal(t, "connecting with ****", out.String())
+}
+
+func TestSecretMaskingWriterMasksLogs(t *testing.T) {
+	RegisterSecret("masking-logger-password")
+	out := &bytes.Buffer{}
+	logger := zerolog.New(NewSecretMaskingWriter(out))
+
+	logger.Info().Str("PASSWORD", "masking-logger-password").Msg("Step.variables")
+	assert.NotContains(t, out.String(), "masking-logger-password")
+	assert.Contains(t, out.String(), `"PASSWORD":"****"`)
+}
+
+func TestSecretMaskingWriterMasksEscapedSecrets(t *testing.T) {
+	secret := "masking-escaped \"pass\\word\"\n"
+	RegisterSecret(secret)
+	out := &bytes.Buffer{}
+	logger := zerolog.New(NewSecretMaskingWriter(out))
+	console := zerolog.New(zerolog.ConsoleWriter{Out: NewSecretMaskingWriter(out), NoColor: true})
+
+	logger.Info().Str("PASSWORD", secret).Msg("Step.variables")
+	console.Info().Str("PASSWORD", secret).Msg("Step.variables")
+	logger.Info().Str("body", fmt.Sprintf("%q", secret)).Msg("Response")
+	assert.NotContains(t, out.String(), "pass")
+	assert.Equal(t, 3, strings.Count(out.String(), MaskedValue))
+}